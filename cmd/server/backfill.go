@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github-reports/internal/api"
+	"github-reports/internal/config"
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+	"github-reports/internal/period"
+	"github-reports/internal/reporter"
+	"github-reports/internal/store"
+)
+
+// backfillOptions 是 backfill 子命令的参数
+type backfillOptions struct {
+	users  []string
+	orgs   []string
+	from   time.Time
+	to     time.Time
+	kind   string
+	report bool
+}
+
+// runBackfill 实现 backfill 子命令：按周期逐段拉取历史活动写入存储，可选地为每个周期生成报告。
+// 每完成一个周期记录一次进度，中断后用相同参数重新运行会从上次完成的周期之后继续。
+func runBackfill(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	configPath := fs.String("config", "", "配置文件路径")
+	users := fs.String("users", "", "要回填的 GitHub 用户，逗号分隔")
+	orgs := fs.String("orgs", "", "要回填的组织，逗号分隔，会展开为组织成员")
+	from := fs.String("from", "", "开始日期，格式 2006-01-02")
+	to := fs.String("to", "", "结束日期，格式 2006-01-02，默认为今天")
+	kind := fs.String("period", period.KindWeek, "切分周期：week、month、quarter 或 sprint")
+	report := fs.Bool("report", false, "为每个周期调用 LLM 生成并存档报告")
+	maxWait := fs.Duration("max-rate-limit-wait", time.Hour, "触发 GitHub 限流时最多等待的时间")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: server backfill -users a,b [-orgs org] -from 2026-01-01 [-to 2026-06-30] [-period week] [-report]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("配置无效: %w", err)
+	}
+	if cfg.Storage.Path == "" {
+		return fmt.Errorf("backfill 需要配置 storage.path")
+	}
+
+	opts := backfillOptions{
+		users:  splitList(*users),
+		orgs:   splitList(*orgs),
+		kind:   *kind,
+		report: *report,
+	}
+	if len(opts.users) == 0 && len(opts.orgs) == 0 {
+		fs.Usage()
+		return fmt.Errorf("至少需要指定 -users 或 -orgs")
+	}
+	if opts.from, err = time.Parse("2006-01-02", *from); err != nil {
+		return fmt.Errorf("无效的 -from: %q", *from)
+	}
+	opts.to = time.Now()
+	if *to != "" {
+		if opts.to, err = time.Parse("2006-01-02", *to); err != nil {
+			return fmt.Errorf("无效的 -to: %q", *to)
+		}
+	}
+
+	st, err := store.Open(cfg.Storage.Path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	// Ctrl-C 时在当前请求结束后退出，已完成的周期不会丢失
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	b := &backfiller{cfg: cfg, store: st, maxWait: *maxWait, repoInfo: github.NewRepoInfoCache(st)}
+	if opts.report {
+		if b.llmClient, err = llm.NewClient(cfg.LLM); err != nil {
+			return err
+		}
+	}
+
+	logins, err := b.expandUsers(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, login := range logins {
+		if err := b.backfillUser(ctx, login, opts); err != nil {
+			return fmt.Errorf("回填 %s 失败: %w", login, err)
+		}
+	}
+
+	log.Println("[Backfill] 全部完成")
+	return nil
+}
+
+type backfiller struct {
+	cfg       *config.Config
+	store     *store.Store
+	llmClient llm.Client
+	maxWait   time.Duration
+	repoInfo  *github.RepoInfoCache
+}
+
+// client 为用户创建 GitHub 客户端，触发限流时等待而不是失败
+func (b *backfiller) client(login string) *github.Client {
+	return api.NewGitHubClient(b.cfg.GitHub, b.cfg.GitHub.TokenFor(login), github.WithRateLimitWait(b.maxWait))
+}
+
+// expandUsers 合并 -users 和组织成员，去重后返回
+func (b *backfiller) expandUsers(ctx context.Context, opts backfillOptions) ([]string, error) {
+	seen := make(map[string]bool)
+	var logins []string
+	add := func(login string) {
+		if key := strings.ToLower(login); !seen[key] {
+			seen[key] = true
+			logins = append(logins, login)
+		}
+	}
+
+	for _, u := range opts.users {
+		add(u)
+	}
+	for _, org := range opts.orgs {
+		members, err := b.client("").OrgMembers(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[Backfill] 组织 %s 有 %d 名成员", org, len(members))
+		for _, m := range members {
+			add(m)
+		}
+	}
+
+	return logins, nil
+}
+
+// backfillUser 按周期回填一个用户，跳过检查点之前已完成的周期
+func (b *backfiller) backfillUser(ctx context.Context, login string, opts backfillOptions) error {
+	loc, err := b.cfg.Location(login)
+	if err != nil {
+		return err
+	}
+	resolver := b.cfg.Report.PeriodResolver(loc)
+
+	from := time.Date(opts.from.Year(), opts.from.Month(), opts.from.Day(), 0, 0, 0, 0, loc)
+	to := time.Date(opts.to.Year(), opts.to.Month(), opts.to.Day(), 23, 59, 59, 0, loc)
+	periods, err := resolver.Walk(opts.kind, from, to)
+	if err != nil {
+		return err
+	}
+
+	// 检查点不含截止日期：延长 --to 重新运行时从上次完成的周期继续，而不是从头开始
+	job := fmt.Sprintf("backfill:%s:%s:%s", strings.ToLower(login), opts.kind, opts.from.Format("2006-01-02"))
+	checkpoint, resumed, err := b.store.Checkpoint(job)
+	if err != nil {
+		return err
+	}
+	done := checkpoint.Done
+	retry := make(map[int64]time.Time)
+	for _, since := range checkpoint.Retry {
+		retry[since.Unix()] = since
+	}
+	if resumed {
+		log.Printf("[Backfill] %s 从检查点继续，已完成至 %s（上次截止 %s），%d 个不完整的周期需要重试",
+			login, done.Format("2006-01-02"), checkpoint.To.Format("2006-01-02"), len(retry))
+	}
+
+	fetcher := github.NewFetcher(b.client(login))
+	for i, p := range periods {
+		if _, again := retry[p.Since.Unix()]; resumed && !p.Until.After(done) && !again {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		log.Printf("[Backfill] %s [%d/%d] %s (%s ~ %s)", login, i+1, len(periods), p.Label,
+			p.Since.Format("2006-01-02"), p.Until.Format("2006-01-02"))
+
+		activity, err := fetcher.FetchHistory(ctx, login, p.Since, p.Until)
+		if err != nil {
+			return err
+		}
+		if err := b.store.SaveActivity(activity); err != nil {
+			return err
+		}
+		if activity.Truncated {
+			log.Printf("[Backfill] %s %s 拉取不完整，未记录快照，下次运行时重试；也可以缩短回填周期", login, p.Label)
+		}
+
+		if opts.report {
+			if err := b.generateReport(ctx, login, loc, resolver, p); err != nil {
+				// 没有活动的周期无法生成报告，不影响继续回填
+				log.Printf("[Backfill] %s %s 生成报告失败: %v", login, p.Label, err)
+			}
+		}
+
+		// 进行中的周期下次还需要重新拉取，不记录进度；拉取不完整的周期记入 Retry，下次运行时重试
+		if p.Complete() {
+			if activity.Truncated {
+				retry[p.Since.Unix()] = p.Since
+			} else {
+				delete(retry, p.Since.Unix())
+			}
+			if p.Until.After(done) {
+				done = p.Until
+			}
+			if err := b.store.SaveCheckpoint(job, store.Checkpoint{Done: done, To: to, Retry: retryList(retry)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// retryList 按时间顺序返回需要重试的周期起始时间
+func retryList(retry map[int64]time.Time) []time.Time {
+	list := make([]time.Time, 0, len(retry))
+	for _, since := range retry {
+		list = append(list, since)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Before(list[j]) })
+	return list
+}
+
+// generateReport 基于已写入存储的数据生成报告并存档
+func (b *backfiller) generateReport(ctx context.Context, login string, loc *time.Location, resolver *period.Resolver, p period.Period) error {
+	rep := reporter.NewReporter(storedSource{b.store}, b.llmClient)
+	rep.SetLocation(loc)
+	rep.SetTokenBudget(llm.InputBudget(b.cfg.LLM))
+	rep.SetMapReduce(b.cfg.Report.MapReduce.Mode, b.cfg.Report.MapReduce.Concurrency)
+	rep.SetLayout(b.cfg.Report.Layout)
+	rep.SetHistory(b.store)
+	rep.SetRepoInfo(b.repoInfo.Source(b.client(login)))
+	if diffs := b.cfg.Report.Diffs; diffs.Enabled {
+		rep.SetPatches(b.client(login), reporter.PatchOptions{
+			TopN:             diffs.TopN,
+			MaxTokensPerItem: diffs.MaxTokensPerItem,
+			IncludePrivate:   diffs.IncludePrivate,
+			ExcludeRepos:     diffs.ExcludeRepos,
+		})
+	}
+
+	req := reporter.Request{
+		Username: login,
+		Period:   p,
+		Style:    b.cfg.Report.Style,
+		Language: b.cfg.Report.Language,
+	}
+	if b.cfg.Report.Comparison {
+		if prev, ok := resolver.Previous(p); ok {
+			req.Previous = &prev
+		}
+	}
+
+	content, err := rep.GenerateReport(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return b.store.SaveReport(&store.Report{
+		Username:      login,
+		Requester:     "backfill",
+		PeriodLabel:   p.Label,
+		Since:         p.Since,
+		Until:         p.Until,
+		Style:         req.Style,
+		Language:      req.Language,
+		Model:         b.cfg.LLM.Model,
+		PromptVersion: llm.PromptVersion,
+		Content:       content,
+	})
+}
+
+// storedSource 把存储中的数据作为活动来源，生成历史报告时不再访问 GitHub
+type storedSource struct {
+	store *store.Store
+}
+
+func (s storedSource) FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error) {
+	return s.store.Activity(username, since, until)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
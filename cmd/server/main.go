@@ -9,14 +9,25 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 内嵌时区数据库，保证精简镜像中也能加载 report.timezone
 
 	"github-reports/internal/api"
 	"github-reports/internal/config"
+	"github-reports/internal/directory"
+	"github-reports/internal/store"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// 子命令：回填历史数据
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := runBackfill(os.Args[2:]); err != nil {
+			log.Fatalf("回填失败: %v", err)
+		}
+		return
+	}
+
 	// 解析命令行参数
 	configPath := flag.String("config", "", "配置文件路径")
 	flag.Parse()
@@ -36,8 +47,31 @@ func main() {
 	// 设置 HTTP 服务器
 	router := gin.Default()
 
+	// 加载飞书用户到 GitHub 登录名的映射
+	var entries []directory.Entry
+	for _, e := range cfg.Directory.Entries {
+		entries = append(entries, directory.Entry{
+			Identity: directory.Identity{OpenID: e.OpenID, UserID: e.UserID, Name: e.Name},
+			Login:    e.Login,
+		})
+	}
+	dir, err := directory.New(entries, cfg.Directory.StorePath)
+	if err != nil {
+		log.Fatalf("加载用户映射失败: %v", err)
+	}
+
+	// 打开历史存储
+	var st *store.Store
+	if cfg.Storage.Path != "" {
+		st, err = store.Open(cfg.Storage.Path)
+		if err != nil {
+			log.Fatalf("打开历史存储失败: %v", err)
+		}
+		defer st.Close()
+	}
+
 	// 创建 API 处理器
-	handler := api.NewHandler(cfg)
+	handler := api.NewHandler(cfg, dir, st)
 
 	// 注册路由
 	v1 := router.Group("/api/v1")
@@ -45,8 +79,16 @@ func main() {
 		// 健康检查 - 无需认证
 		v1.GET("/health", handler.Health)
 
-		// Webhook - 需要认证
-		v1.POST("/webhook", handler.AuthMiddleware(), handler.Webhook)
+		// Webhook - 需要认证，飞书直接推送的事件校验 Verification Token
+		v1.POST("/webhook", handler.WebhookAuthMiddleware(), handler.Webhook)
+
+		// 同步生成报告 - 需要认证
+		v1.POST("/reports", handler.AuthMiddleware(), handler.Report)
+
+		// 查询和重发存档的报告 - 需要认证
+		v1.GET("/reports", handler.AuthMiddleware(), handler.ListReports)
+		v1.GET("/reports/:id", handler.AuthMiddleware(), handler.GetReport)
+		v1.POST("/reports/:id/resend", handler.AuthMiddleware(), handler.ResendReport)
 	}
 
 	// 设置 HTTP 服务器
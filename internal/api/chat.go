@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github-reports/internal/directory"
+	"github-reports/internal/username"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChatMention 是消息中的一个 @ 提及，Key 是它在 content 中的占位符，如 @_user_1
+type ChatMention struct {
+	Key string `json:"key"`
+	directory.Identity
+}
+
+// feishuHeader 是飞书 2.0 事件的 header 字段
+type feishuHeader struct {
+	EventType string `json:"event_type"`
+	Token     string `json:"token"` // 事件订阅的 Verification Token
+}
+
+// feishuVerified 判断请求体中的 Verification Token 是否与配置一致：
+// URL 校验请求在顶层的 token 字段，2.0 事件在 header.token。读取后会恢复请求体
+func (h *Handler) feishuVerified(c *gin.Context) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req WebhookRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return false
+	}
+	token := req.Token
+	if req.Header != nil {
+		token = req.Header.Token
+	}
+	expected := h.config.Notifiers.Feishu.VerificationToken
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// feishuEvent 是飞书事件订阅 im.message.receive_v1 的 event 字段
+type feishuEvent struct {
+	Sender struct {
+		SenderID struct {
+			OpenID string `json:"open_id"`
+			UserID string `json:"user_id"`
+		} `json:"sender_id"`
+	} `json:"sender"`
+	Message struct {
+		MessageType string `json:"message_type"`
+		Content     string `json:"content"` // JSON 字符串，文本消息为 {"text": "..."}
+		Mentions    []struct {
+			Key string `json:"key"`
+			ID  struct {
+				OpenID string `json:"open_id"`
+				UserID string `json:"user_id"`
+			} `json:"id"`
+			Name string `json:"name"`
+		} `json:"mentions"`
+	} `json:"message"`
+}
+
+// normalize 把飞书原生事件展开到 Content、Sender、Mentions 字段
+func (r *WebhookRequest) normalize() error {
+	if r.Event == nil {
+		return nil
+	}
+
+	msg := r.Event.Message
+	if msg.MessageType != "" && msg.MessageType != "text" {
+		return fmt.Errorf("unsupported message type: %s", msg.MessageType)
+	}
+
+	var text struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(msg.Content), &text); err != nil {
+		return fmt.Errorf("failed to parse message content: %w", err)
+	}
+	r.Content = text.Text
+
+	r.Sender = &directory.Identity{
+		OpenID: r.Event.Sender.SenderID.OpenID,
+		UserID: r.Event.Sender.SenderID.UserID,
+	}
+
+	r.Mentions = nil
+	for _, m := range msg.Mentions {
+		r.Mentions = append(r.Mentions, ChatMention{
+			Key: m.Key,
+			Identity: directory.Identity{
+				OpenID: m.ID.OpenID,
+				UserID: m.ID.UserID,
+				Name:   m.Name,
+			},
+		})
+	}
+
+	return nil
+}
+
+// selfReference 匹配指代发送者本人的说法
+var selfReference = regexp.MustCompile(`我的|(?i)\bmy\b`)
+
+// resolveChatUsers 用目录把消息中的 @ 提及和「我的」替换为 @GitHub 登录名，交给后续的用户名识别。
+// 目录中找不到的提及（包括对机器人本身的提及）会被去掉，避免把飞书显示名误当成 GitHub 用户名。
+// 返回替换后的内容和发送者对应的登录名（未绑定时为空）。
+func (h *Handler) resolveChatUsers(req WebhookRequest, log func(string)) (string, string) {
+	content := req.Content
+
+	for _, m := range req.Mentions {
+		if m.Key == "" {
+			continue
+		}
+		if login, ok := h.directory.Lookup(m.Identity); ok {
+			log(fmt.Sprintf("@%s 对应 GitHub 用户 %s", m.Name, login))
+			content = strings.ReplaceAll(content, m.Key, " @"+login+" ")
+		} else {
+			content = strings.ReplaceAll(content, m.Key, " ")
+		}
+	}
+
+	var self string
+	if req.Sender != nil {
+		if login, ok := h.directory.Lookup(*req.Sender); ok {
+			self = login
+			content = selfReference.ReplaceAllString(content, " @"+login+" 的 ")
+		}
+	}
+
+	return strings.TrimSpace(content), self
+}
+
+// parseCommand 识别以 / 开头的管理命令，开头对机器人的 @ 提及会被跳过
+func parseCommand(req WebhookRequest) (name string, args []string, ok bool) {
+	keys := make(map[string]bool)
+	for _, m := range req.Mentions {
+		keys[m.Key] = true
+	}
+
+	fields := strings.Fields(req.Content)
+	for len(fields) > 0 && keys[fields[0]] {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+
+	return strings.ToLower(fields[0]), fields[1:], true
+}
+
+// handleCommand 执行目录管理命令并返回回复内容：
+//
+//	/bind @同事 github-login   绑定被 @ 的同事
+//	/bind 显示名 github-login  按飞书显示名绑定
+//	/bind github-login         绑定自己
+//	/unbind @同事 | 显示名 | github-login
+//	/bindings                  列出所有映射
+func (h *Handler) handleCommand(req WebhookRequest, name string, args []string) string {
+	var sender directory.Identity
+	if req.Sender != nil {
+		sender = *req.Sender
+	}
+
+	switch name {
+	case "/bind", "/unbind":
+		if !h.config.IsDirectoryAdmin(sender.OpenID, sender.UserID) {
+			return "❌ 只有管理员可以修改用户映射"
+		}
+	case "/bindings":
+		return formatBindings(h.directory.Entries())
+	default:
+		return fmt.Sprintf("❌ 未知命令 %s，支持 /bind、/unbind、/bindings", name)
+	}
+
+	mentions := make(map[string]directory.Identity)
+	for _, m := range req.Mentions {
+		mentions[m.Key] = m.Identity
+	}
+	// target 把参数解析为聊天身份：@ 提及或飞书显示名
+	target := func(arg string) directory.Identity {
+		if id, ok := mentions[arg]; ok {
+			return id
+		}
+		return directory.Identity{Name: arg}
+	}
+
+	switch name {
+	case "/bind":
+		var id directory.Identity
+		var login string
+		switch len(args) {
+		case 1:
+			id, login = sender, args[0]
+			id.Name = ""
+		case 2:
+			id, login = target(args[0]), args[1]
+		default:
+			return "❌ 用法：/bind @同事 github-login"
+		}
+		login = strings.TrimPrefix(login, "@")
+		if !username.ValidLogin(login) {
+			return fmt.Sprintf("❌ %q 不是合法的 GitHub 用户名", login)
+		}
+		if err := h.directory.Bind(directory.Entry{Identity: id, Login: login}); err != nil {
+			return "❌ 保存映射失败: " + err.Error()
+		}
+		return fmt.Sprintf("✅ 已绑定 %s → %s", displayName(id), login)
+
+	default: // /unbind
+		if len(args) != 1 {
+			return "❌ 用法：/unbind @同事 或 /unbind github-login"
+		}
+		id := target(args[0])
+		var login string
+		if _, isMention := mentions[args[0]]; !isMention {
+			login = strings.TrimPrefix(args[0], "@")
+		}
+		removed, err := h.directory.Unbind(id, login)
+		if err != nil {
+			return "❌ 保存映射失败: " + err.Error()
+		}
+		if removed == 0 {
+			return fmt.Sprintf("未找到 %s 的映射（配置文件中的映射需修改配置）", args[0])
+		}
+		return fmt.Sprintf("✅ 已删除 %d 条映射", removed)
+	}
+}
+
+func formatBindings(entries []directory.Entry) string {
+	if len(entries) == 0 {
+		return "暂无用户映射"
+	}
+	var sb strings.Builder
+	sb.WriteString("用户映射：\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("- %s → %s\n", displayName(e.Identity), e.Login))
+	}
+	return sb.String()
+}
+
+func displayName(id directory.Identity) string {
+	switch {
+	case id.Name != "":
+		return id.Name
+	case id.OpenID != "":
+		return id.OpenID
+	default:
+		return id.UserID
+	}
+}
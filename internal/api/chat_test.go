@@ -0,0 +1,237 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github-reports/internal/config"
+	"github-reports/internal/directory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWebhookFeishuVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Webhook.Token = "secret"
+	cfg.Notifiers.Feishu.VerificationToken = "verify-me"
+	h := NewHandler(cfg, nil, nil)
+
+	router := gin.New()
+	router.POST("/webhook", h.WebhookAuthMiddleware(), h.Webhook)
+
+	tests := []struct {
+		name       string
+		auth       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "url verification answers the challenge",
+			body:       `{"type": "url_verification", "token": "verify-me", "challenge": "abc123"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"challenge":"abc123"}`,
+		},
+		{
+			name:       "url verification with a wrong token",
+			body:       `{"type": "url_verification", "token": "wrong", "challenge": "abc123"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "event without a token",
+			body:       `{"schema": "2.0", "header": {"event_type": "im.message.receive_v1"}, "event": {}}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "event with the verification token",
+			body: `{"schema": "2.0", "header": {"event_type": "im.message.receive_v1", "token": "verify-me"},
+				"event": {"message": {"message_type": "text", "content": "{\"text\": \"@octocat 的周报\"}"}}}`,
+			// 通过认证后因为飞书通知未启用而返回 400
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Feishu notification is not enabled"}`,
+		},
+		{
+			name:       "bearer token still works",
+			auth:       "Bearer secret",
+			body:       `{"type": "url_verification", "challenge": "abc123"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"challenge":"abc123"}`,
+		},
+		{
+			name:       "wrong bearer token",
+			auth:       "Bearer wrong",
+			body:       `{"type": "url_verification", "token": "verify-me", "challenge": "abc123"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// newChatHandler 创建一个带目录的 Handler：ou_admin 是管理员，Alice 已在配置中绑定
+func newChatHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Directory.Admins = []string{"ou_admin"}
+	dir, err := directory.New([]directory.Entry{
+		{Identity: directory.Identity{OpenID: "ou_alice", Name: "Alice"}, Login: "alice-gh"},
+	}, "")
+	if err != nil {
+		t.Fatalf("directory.New: %v", err)
+	}
+	return NewHandler(cfg, dir, nil)
+}
+
+func TestHandleCommand(t *testing.T) {
+	admin := &directory.Identity{OpenID: "ou_admin", Name: "Admin"}
+	bob := ChatMention{Key: "@_user_2", Identity: directory.Identity{OpenID: "ou_bob", Name: "Bob"}}
+
+	tests := []struct {
+		name      string
+		req       WebhookRequest
+		wantReply string
+		lookup    directory.Identity
+		wantLogin string
+	}{
+		{
+			name:      "non-admin cannot bind",
+			req:       WebhookRequest{Content: "/bind bob-gh", Sender: &directory.Identity{OpenID: "ou_bob"}},
+			wantReply: "只有管理员",
+			lookup:    directory.Identity{OpenID: "ou_bob"},
+		},
+		{
+			name:      "bind self with one argument",
+			req:       WebhookRequest{Content: "/bind @admin-gh", Sender: admin},
+			wantReply: "已绑定 ou_admin → admin-gh",
+			lookup:    directory.Identity{OpenID: "ou_admin"},
+			wantLogin: "admin-gh",
+		},
+		{
+			name: "bind a mention with two arguments",
+			req: WebhookRequest{Content: "@_user_1 /bind @_user_2 bob-gh", Sender: admin,
+				Mentions: []ChatMention{{Key: "@_user_1", Identity: directory.Identity{Name: "bot"}}, bob}},
+			wantReply: "已绑定 Bob → bob-gh",
+			lookup:    directory.Identity{OpenID: "ou_bob"},
+			wantLogin: "bob-gh",
+		},
+		{
+			name:      "bind a display name",
+			req:       WebhookRequest{Content: "/bind 小王 wang-gh", Sender: admin},
+			wantReply: "已绑定 小王 → wang-gh",
+			lookup:    directory.Identity{Name: "小王"},
+			wantLogin: "wang-gh",
+		},
+		{
+			name:      "invalid login",
+			req:       WebhookRequest{Content: "/bind @_user_2 not_a_login!", Sender: admin, Mentions: []ChatMention{bob}},
+			wantReply: "不是合法的 GitHub 用户名",
+			lookup:    directory.Identity{OpenID: "ou_bob"},
+		},
+		{
+			name:      "too many arguments",
+			req:       WebhookRequest{Content: "/bind a b c", Sender: admin},
+			wantReply: "用法",
+		},
+		{
+			name:      "unbind refuses static entries",
+			req:       WebhookRequest{Content: "/unbind alice-gh", Sender: admin},
+			wantReply: "未找到 alice-gh 的映射",
+			lookup:    directory.Identity{OpenID: "ou_alice"},
+			wantLogin: "alice-gh",
+		},
+		{
+			name:      "unknown command",
+			req:       WebhookRequest{Content: "/frobnicate", Sender: admin},
+			wantReply: "未知命令 /frobnicate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newChatHandler(t)
+			name, args, ok := parseCommand(tt.req)
+			if !ok {
+				t.Fatalf("parseCommand(%q) did not find a command", tt.req.Content)
+			}
+			if reply := h.handleCommand(tt.req, name, args); !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply = %q, want it to contain %q", reply, tt.wantReply)
+			}
+			if tt.lookup == (directory.Identity{}) {
+				return
+			}
+			if login, _ := h.directory.Lookup(tt.lookup); login != tt.wantLogin {
+				t.Errorf("Lookup(%+v) = %q, want %q", tt.lookup, login, tt.wantLogin)
+			}
+		})
+	}
+}
+
+func TestResolveChatUsers(t *testing.T) {
+	h := newChatHandler(t)
+	alice := &directory.Identity{OpenID: "ou_alice", Name: "Alice"}
+	mentions := []ChatMention{
+		{Key: "@_user_1", Identity: directory.Identity{OpenID: "ou_bot", Name: "周报机器人"}},
+		{Key: "@_user_2", Identity: directory.Identity{OpenID: "ou_alice", Name: "Alice"}},
+	}
+
+	tests := []struct {
+		name     string
+		req      WebhookRequest
+		want     string
+		wantSelf string
+	}{
+		{
+			name: "mentions are replaced by logins and unknown mentions removed",
+			req:  WebhookRequest{Content: "@_user_1 看看 @_user_2 上周的周报", Mentions: mentions},
+			want: "看看  @alice-gh  上周的周报",
+		},
+		{
+			name:     "我的 refers to the sender",
+			req:      WebhookRequest{Content: "我的周报", Sender: alice},
+			want:     "@alice-gh 的 周报",
+			wantSelf: "alice-gh",
+		},
+		{
+			name:     "my refers to the sender",
+			req:      WebhookRequest{Content: "show my weekly report", Sender: alice},
+			want:     "show  @alice-gh 的  weekly report",
+			wantSelf: "alice-gh",
+		},
+		{
+			name: "unbound sender keeps the text",
+			req:  WebhookRequest{Content: "我的周报", Sender: &directory.Identity{OpenID: "ou_nobody"}},
+			want: "我的周报",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, self := h.resolveChatUsers(tt.req, func(string) {})
+			if got != tt.want || self != tt.wantSelf {
+				t.Errorf("resolveChatUsers = %q, %q, want %q, %q", got, self, tt.want, tt.wantSelf)
+			}
+		})
+	}
+}
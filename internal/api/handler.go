@@ -10,23 +10,33 @@ import (
 	"time"
 
 	"github-reports/internal/config"
+	"github-reports/internal/directory"
 	"github-reports/internal/github"
 	"github-reports/internal/llm"
 	"github-reports/internal/notifier"
-	"github-reports/internal/reporter"
+	"github-reports/internal/period"
+	"github-reports/internal/render"
+	"github-reports/internal/store"
+	"github-reports/internal/username"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Handler 处理 HTTP 请求
 type Handler struct {
-	config *config.Config
+	config    *config.Config
+	directory *directory.Directory
+	store     *store.Store // 可选：历史存储，为 nil 时不保存活动和报告
+	repoInfo  *github.RepoInfoCache
 }
 
 // NewHandler 创建一个新的 API 处理器
-func NewHandler(cfg *config.Config) *Handler {
+func NewHandler(cfg *config.Config, dir *directory.Directory, st *store.Store) *Handler {
 	return &Handler{
-		config: cfg,
+		config:    cfg,
+		directory: dir,
+		store:     st,
+		repoInfo:  newRepoInfoCache(st),
 	}
 }
 
@@ -48,9 +58,39 @@ func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-// WebhookRequest 表示 webhook 请求体
+// WebhookAuthMiddleware 检查 webhook 令牌；飞书直接推送的事件没有 Authorization 头，
+// 改为校验请求体中的 Verification Token
+func (h *Handler) WebhookAuthMiddleware() gin.HandlerFunc {
+	auth := h.AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && h.config.Notifiers.Feishu.VerificationToken != "" {
+			if !h.feishuVerified(c) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification token"})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// WebhookRequest 表示 webhook 请求体。
+// 既支持 {"content": "..."} 的简单格式，也支持直接转发飞书事件订阅（im.message.receive_v1）的原始事件。
 type WebhookRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content  string              `json:"content"`
+	Period   string              `json:"period"`   // 可选：统计周期，如 last-week、this-month、Q3、sprint 42，默认为最近 7 天
+	Sender   *directory.Identity `json:"sender"`   // 可选：发送者，用于识别「我」
+	Mentions []ChatMention       `json:"mentions"` // 可选：content 中 @ 提及的占位符及对应用户
+
+	Schema string        `json:"schema"` // 飞书事件格式版本，如 2.0
+	Header *feishuHeader `json:"header"` // 飞书事件头
+	Event  *feishuEvent  `json:"event"`  // 飞书原始事件
+
+	Type      string `json:"type"`      // 飞书 URL 校验请求为 url_verification
+	Challenge string `json:"challenge"` // URL 校验时需要原样返回
+	Token     string `json:"token"`     // URL 校验请求中的 Verification Token
 }
 
 // Webhook 处理 POST /api/v1/webhook
@@ -77,6 +117,20 @@ func (h *Handler) Webhook(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// 飞书配置事件订阅地址时会发送 URL 校验请求，需要原样返回 challenge
+	if req.Type == "url_verification" {
+		c.JSON(http.StatusOK, gin.H{"challenge": req.Challenge})
+		return
+	}
+	if err := req.normalize(); err != nil {
+		fmt.Printf("[Webhook] 解析飞书事件失败: %v\n", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content is required"})
+		return
+	}
 
 	// 打印解析后的请求体内容
 	fmt.Printf("[Webhook] 解析后的请求体: %+v\n", req)
@@ -102,11 +156,11 @@ func (h *Handler) Webhook(c *gin.Context) {
 	})
 
 	// 在后台异步处理
-	go h.processWebhookAsync(req.Content)
+	go h.processWebhookAsync(req)
 }
 
 // processWebhookAsync 异步处理 webhook 请求
-func (h *Handler) processWebhookAsync(content string) {
+func (h *Handler) processWebhookAsync(req WebhookRequest) {
 	log := func(message string) {
 		println("[Webhook-Async]", message)
 	}
@@ -118,14 +172,28 @@ func (h *Handler) processWebhookAsync(content string) {
 	// 向飞书发送错误的辅助函数
 	sendErrorToFeishu := func(errorMsg string) {
 		if h.config.Notifiers.Feishu.Enabled {
-			feishuNotifier := notifier.NewFeishuNotifier(h.config.Notifiers.Feishu.WebhookURL)
+			feishuNotifier := h.feishuNotifier()
 			errorReport := fmt.Sprintf("# ❌ GitHub 周报生成失败\n\n**错误信息：**\n%s", errorMsg)
 			_ = feishuNotifier.Send(ctx, errorReport)
 		}
 	}
 
-	// 步骤 1: 使用 LLM 提取 GitHub 用户名
-	log("步骤 1: 使用 LLM 提取 GitHub 用户名...")
+	// 管理命令：维护飞书用户到 GitHub 登录名的映射
+	if name, args, ok := parseCommand(req); ok {
+		log("执行命令: " + name)
+		reply := h.handleCommand(req, name, args)
+		feishuNotifier := h.feishuNotifier()
+		if err := feishuNotifier.Send(ctx, reply); err != nil {
+			log("错误: 发送飞书通知失败: " + err.Error())
+		}
+		return
+	}
+
+	// 把 @ 提及和「我」换成 GitHub 登录名
+	content, self := h.resolveChatUsers(req, log)
+
+	// 步骤 1: 提取报告意图（用户、周期、风格、语言）
+	log("步骤 1: 提取报告意图...")
 	llmClient, err := llm.NewClient(h.config.LLM)
 	if err != nil {
 		errMsg := "创建 LLM 客户端失败: " + err.Error()
@@ -134,68 +202,177 @@ func (h *Handler) processWebhookAsync(content string) {
 		return
 	}
 
-	username, err := llmClient.ExtractGitHubUsername(ctx, content)
+	intent, err := h.extractIntent(ctx, llmClient, content, self, log)
 	if err != nil {
-		errMsg := "提取 GitHub 用户名失败: " + err.Error()
+		errMsg := "解析请求失败: " + err.Error()
 		log("错误: " + errMsg)
 		sendErrorToFeishu(errMsg)
 		return
 	}
 
-	// 清理用户名（去除空白字符和换行符）
-	username = strings.TrimSpace(username)
-	log("提取到的 GitHub 用户名: " + username)
+	// 请求体中显式指定的周期优先于从消息中提取的周期
+	if req.Period != "" {
+		intent.Period = req.Period
+	}
 
-	// 步骤 2: 查找 GitHub 令牌
-	log("步骤 2: 查找 GitHub token...")
-	var token string
-	for _, t := range h.config.GitHub.Tokens {
-		if t.Username == username {
-			token = t.Token
-			log("找到匹配的 GitHub token (username: " + t.Username + ")")
-			break
+	for _, username := range intent.Users {
+		// 步骤 2-3: 解析周期、拉取活动数据并生成报告
+		reportReq := reportRequest{
+			Username: username,
+			Period:   intent.Period,
+			Org:      intent.Org,
+			Repo:     intent.Repo,
+			Style:    intent.Style,
+			Language: intent.Language,
+
+			Requester: requester(req.Sender, self),
 		}
-	}
+		if _, _, err := h.resolvePeriod(username, reportReq.Period); err != nil {
+			log(fmt.Sprintf("周期 %q 无效，使用默认周期: %s", reportReq.Period, err.Error()))
+			reportReq.Period = ""
+		}
+
+		report, err := h.generateReport(ctx, llmClient, reportReq, log)
+		if err != nil {
+			errMsg := fmt.Sprintf("生成 %s 的周报失败: %s", username, err.Error())
+			log("错误: " + errMsg)
+			sendErrorToFeishu(errMsg)
+			continue
+		}
+
+		log("报告生成成功")
 
-	if token == "" && len(h.config.GitHub.Tokens) > 0 {
-		token = h.config.GitHub.Tokens[0].Token
-		log("使用默认 GitHub token 查询用户 " + username)
+		// 步骤 4: 发送到飞书
+		log("步骤 4: 发送到飞书...")
+		feishuNotifier := h.feishuNotifier()
+		if err := feishuNotifier.Send(ctx, report.Content); err != nil {
+			errMsg := "发送飞书通知失败: " + err.Error()
+			log("错误: " + errMsg)
+			continue
+		}
+
+		log("成功发送到飞书")
+
+		if h.config.Report.Charts {
+			h.sendCharts(ctx, feishuNotifier, report, log)
+		}
 	}
+}
 
-	if token == "" {
-		errMsg := "未配置 GitHub token"
-		log("错误: " + errMsg)
-		sendErrorToFeishu(errMsg)
+// feishuNotifier 创建飞书通知器，配置了自建应用凭证时支持发送图片
+func (h *Handler) feishuNotifier() *notifier.FeishuNotifier {
+	n := notifier.NewFeishuNotifier(h.config.Notifiers.Feishu.WebhookURL)
+	n.SetApp(h.config.Notifiers.Feishu.AppID, h.config.Notifiers.Feishu.AppSecret)
+	return n
+}
+
+// sendCharts 绘制图表并逐张发送，失败只记录日志
+func (h *Handler) sendCharts(ctx context.Context, n notifier.ImageNotifier, report *generatedReport, log func(string)) {
+	if !n.SupportsImages() {
+		log("通知渠道不支持图片（飞书需配置 app_id / app_secret），跳过图表")
 		return
 	}
 
-	// 步骤 3: 拉取 GitHub 活动数据
-	log("步骤 3: 拉取 GitHub 活动数据...")
-	until := time.Now()
-	since := until.Add(-7 * 24 * time.Hour) // 默认为最近 7 天
-
-	githubClient := github.NewClient(token)
-	rep := reporter.NewReporter(githubClient, llmClient)
-	report, err := rep.GenerateReport(ctx, username, since, until)
+	images, err := render.Render(report.Activity, report.Location)
 	if err != nil {
-		errMsg := fmt.Sprintf("生成 %s 的周报失败: %s", username, err.Error())
-		log("错误: " + errMsg)
-		sendErrorToFeishu(errMsg)
+		log("绘制图表失败: " + err.Error())
 		return
 	}
 
-	log("报告生成成功")
+	for _, img := range images {
+		if err := n.SendImage(ctx, img.Name, img.PNG); err != nil {
+			log(fmt.Sprintf("发送图表 %s 失败: %s", img.Name, err.Error()))
+			return
+		}
+	}
+	log(fmt.Sprintf("已发送 %d 张图表", len(images)))
+}
+
+// requester 返回存档用的发起人：优先使用绑定的 GitHub 登录名，其次是飞书 ID
+func requester(sender *directory.Identity, self string) string {
+	switch {
+	case self != "":
+		return self
+	case sender != nil && sender.OpenID != "":
+		return sender.OpenID
+	case sender != nil && sender.UserID != "":
+		return sender.UserID
+	default:
+		return "webhook"
+	}
+}
 
-	// 步骤 4: 发送到飞书
-	log("步骤 4: 发送到飞书...")
-	feishuNotifier := notifier.NewFeishuNotifier(h.config.Notifiers.Feishu.WebhookURL)
-	if err := feishuNotifier.Send(ctx, report); err != nil {
-		errMsg := "发送飞书通知失败: " + err.Error()
-		log("错误: " + errMsg)
-		return
+// extractIntent 先用确定性规则识别用户名和周期，规则无法确定时才调用 LLM；
+// LLM 输出逐字段校验，不合法的字段回退到配置的默认值。
+// 消息中没有提到任何人时，使用发送者本人（self，未绑定时为空）。
+func (h *Handler) extractIntent(ctx context.Context, llmClient llm.Client, content, self string, log func(string)) (llm.Intent, error) {
+	defaults := llm.Intent{
+		Style:    h.config.Report.Style,
+		Language: h.config.Report.Language,
 	}
 
-	log("成功发送到飞书")
+	var intent llm.Intent
+	if result := username.Extract(content, h.config.Aliases()); !result.Ambiguous {
+		intent = ruleIntent(content, result.Logins, defaults)
+		log("通过规则识别到用户名，跳过 LLM")
+	} else {
+		log("规则无法确定用户名，使用 LLM 提取...")
+		raw, err := llmClient.ExtractIntent(ctx, content)
+		if err != nil {
+			return llm.Intent{}, err
+		}
+
+		var invalid []string
+		intent, invalid = llm.ParseIntent(raw, defaults)
+		if len(invalid) > 0 {
+			log(fmt.Sprintf("LLM 输出中以下字段无效，已使用默认值: %s（原始输出: %s）", strings.Join(invalid, ", "), raw))
+		}
+	}
+
+	if len(intent.Users) == 0 && self != "" {
+		log("消息中未指定用户，使用发送者 " + self)
+		intent.Users = []string{self}
+	}
+	if len(intent.Users) == 0 {
+		return llm.Intent{}, fmt.Errorf("未能从消息中识别出 GitHub 用户名")
+	}
+
+	log(fmt.Sprintf("提取到的意图: 用户=%s 组织=%q 仓库=%q 周期=%q 风格=%q 语言=%q",
+		strings.Join(intent.Users, ","), intent.Org, intent.Repo, intent.Period, intent.Style, intent.Language))
+
+	return intent, nil
+}
+
+// ruleIntent 用关键词补全规则路径下的周期、风格和语言
+func ruleIntent(content string, users []string, defaults llm.Intent) llm.Intent {
+	intent := defaults
+	intent.Users = users
+	intent.Period = period.Detect(content)
+
+	lower := strings.ToLower(content)
+	switch {
+	case containsAny(lower, "详细", "detailed", "完整"):
+		intent.Style = llm.StyleDetailed
+	case containsAny(lower, "简要", "简短", "简单", "brief"):
+		intent.Style = llm.StyleBrief
+	}
+	switch {
+	case containsAny(lower, "英文", "english", "in en"):
+		intent.Language = llm.LanguageEn
+	case containsAny(lower, "中文", "chinese"):
+		intent.Language = llm.LanguageZh
+	}
+
+	return intent
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
 }
 
 // Health 处理 GET /api/v1/health
@@ -0,0 +1,436 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github-reports/internal/config"
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+	"github-reports/internal/period"
+	"github-reports/internal/render"
+	"github-reports/internal/reporter"
+	"github-reports/internal/source"
+	"github-reports/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportRequest 是一次报告生成的参数
+type reportRequest struct {
+	Username string
+	Period   string // 周期表达式，为空时使用 report.default_period
+	Org      string
+	Repo     string
+	Style    string
+	Language string
+
+	Requester string // 发起人，随报告存档
+}
+
+// generatedReport 是生成好的报告及绘制图表所需的数据
+type generatedReport struct {
+	Content  string
+	Period   period.Period
+	Activity *github.UserActivity
+	Location *time.Location
+}
+
+// generateReport 解析统计周期、组合数据来源并生成报告
+func (h *Handler) generateReport(ctx context.Context, llmClient llm.Client, req reportRequest, log func(string)) (*generatedReport, error) {
+	username := req.Username
+
+	// 查找 GitHub 令牌
+	log("查找 GitHub token...")
+	var token string
+	for _, t := range h.config.GitHub.Tokens {
+		if strings.EqualFold(t.Username, username) {
+			token = t.Token
+			log("找到匹配的 GitHub token (username: " + t.Username + ")")
+			break
+		}
+	}
+
+	if token == "" && len(h.config.GitHub.Tokens) > 0 {
+		token = h.config.GitHub.Tokens[0].Token
+		log("使用默认 GitHub token 查询用户 " + username)
+	}
+
+	if token == "" && !h.config.Sources.LocalGit.Offline {
+		return nil, fmt.Errorf("未配置 GitHub token")
+	}
+
+	// 解析统计周期
+	p, loc, err := h.resolvePeriod(username, req.Period)
+	if err != nil {
+		return nil, err
+	}
+	log(fmt.Sprintf("统计周期: %s，%s ~ %s (%s)", p.Label, p.Since.Format("2006-01-02 15:04"), p.Until.Format("2006-01-02 15:04"), loc))
+
+	// 拉取活动数据并生成报告
+	log("拉取活动数据并生成报告...")
+	var githubClient *github.Client
+	if token != "" && !h.config.Sources.LocalGit.Offline {
+		githubClient = h.newGitHubClient(token)
+	}
+
+	// 未在 users 中配置的用户来自消息解析，拉取前先确认 GitHub 上确实存在
+	if githubClient != nil && h.config.User(username) == nil {
+		login, err := githubClient.LookupUser(ctx, username)
+		if errors.Is(err, github.ErrUserNotFound) {
+			return nil, fmt.Errorf("GitHub 用户 %s 不存在", username)
+		}
+		if err != nil {
+			return nil, err
+		}
+		username = login
+	}
+
+	rep := reporter.NewReporter(source.ForUser(h.config, username, githubClient), llmClient)
+	rep.SetLocation(loc)
+	rep.SetTokenBudget(llm.InputBudget(h.config.LLM))
+	rep.SetMapReduce(h.config.Report.MapReduce.Mode, h.config.Report.MapReduce.Concurrency)
+	rep.SetLayout(h.config.Report.Layout)
+	if h.store != nil {
+		rep.SetHistory(h.store)
+	}
+	if githubClient != nil {
+		rep.SetRepoInfo(h.repoInfo.Source(githubClient))
+		if diffs := h.config.Report.Diffs; diffs.Enabled {
+			rep.SetPatches(githubClient, reporter.PatchOptions{
+				TopN:             diffs.TopN,
+				MaxTokensPerItem: diffs.MaxTokensPerItem,
+				IncludePrivate:   diffs.IncludePrivate,
+				ExcludeRepos:     diffs.ExcludeRepos,
+			})
+		}
+	}
+	if u := h.config.User(username); u != nil && githubClient != nil {
+		rep.SetRoadmap(github.NewFetcher(githubClient), roadmapTargets(u.Roadmap))
+	}
+
+	reportReq := reporter.Request{
+		Username: username,
+		Period:   p,
+		Org:      req.Org,
+		Repo:     req.Repo,
+		Style:    req.Style,
+		Language: req.Language,
+	}
+	if h.config.Report.Comparison {
+		if prev, ok := h.periodResolver(loc).Previous(p); ok {
+			reportReq.Previous = &prev
+		}
+	}
+
+	result, err := rep.Generate(ctx, reportReq)
+	if err != nil {
+		return nil, err
+	}
+
+	h.archiveReport(&store.Report{
+		Username:      username,
+		Requester:     req.Requester,
+		PeriodLabel:   p.Label,
+		Since:         p.Since,
+		Until:         p.Until,
+		Org:           req.Org,
+		Repo:          req.Repo,
+		Style:         req.Style,
+		Language:      req.Language,
+		Model:         h.config.LLM.Model,
+		PromptVersion: llm.PromptVersion,
+		Content:       result.Report,
+	}, log)
+
+	return &generatedReport{
+		Content:  result.Report,
+		Period:   p,
+		Activity: result.Activity,
+		Location: loc,
+	}, nil
+}
+
+// archiveReport 把生成的报告存入历史，失败只记录日志
+func (h *Handler) archiveReport(report *store.Report, log func(string)) {
+	if h.store == nil {
+		return
+	}
+	if err := h.store.SaveReport(report); err != nil {
+		log("保存报告失败: " + err.Error())
+		return
+	}
+	log(fmt.Sprintf("报告已存档，ID: %d", report.ID))
+}
+
+// resolvePeriod 在用户时区内解析周期表达式
+func (h *Handler) resolvePeriod(username, expr string) (period.Period, *time.Location, error) {
+	loc, err := h.config.Location(username)
+	if err != nil {
+		return period.Period{}, nil, fmt.Errorf("加载时区失败: %w", err)
+	}
+
+	if expr == "" {
+		expr = h.config.Report.DefaultPeriod
+	}
+
+	p, err := h.periodResolver(loc).Resolve(expr)
+	if err != nil {
+		return period.Period{}, nil, fmt.Errorf("无法识别统计周期 %q: %w", expr, err)
+	}
+
+	return p, loc, nil
+}
+
+// periodResolver 创建指定时区的周期解析器
+func (h *Handler) periodResolver(loc *time.Location) *period.Resolver {
+	return h.config.Report.PeriodResolver(loc)
+}
+
+// newGitHubClient 创建 GitHub 客户端
+func (h *Handler) newGitHubClient(token string) *github.Client {
+	return NewGitHubClient(h.config.GitHub, token)
+}
+
+// NewGitHubClient 创建 GitHub 客户端，配置了 base_url 时指向对应的 API 地址
+func NewGitHubClient(cfg config.GitHubConfig, token string, opts ...github.ClientOption) *github.Client {
+	if cfg.BaseURL != "" {
+		baseURL, _ := url.Parse(cfg.BaseURL) // 已在 Validate 中校验
+		opts = append(opts, github.WithBaseURL(baseURL))
+	}
+	return github.NewClient(token, opts...)
+}
+
+// newRepoInfoCache 创建仓库元数据缓存，有历史存储时同时持久化到其中
+func newRepoInfoCache(st *store.Store) *github.RepoInfoCache {
+	// st 为 nil 时不能直接传入，否则接口值不为 nil
+	if st == nil {
+		return github.NewRepoInfoCache(nil)
+	}
+	return github.NewRepoInfoCache(st)
+}
+
+// roadmapTargets 将配置转换为 github 包使用的路线图目标
+func roadmapTargets(cfg config.RoadmapConfig) github.RoadmapTargets {
+	targets := github.RoadmapTargets{MilestoneRepos: cfg.MilestoneRepos}
+	for _, p := range cfg.Projects {
+		targets.Projects = append(targets.Projects, github.ProjectTarget{
+			Owner:       p.Owner,
+			Number:      p.Number,
+			StatusField: p.StatusField,
+			DoneStatus:  p.DoneStatus,
+		})
+	}
+	return targets
+}
+
+// ReportRequest 表示同步生成报告的请求体
+type ReportRequest struct {
+	Username string `json:"username" binding:"required"`
+	Period   string `json:"period"`   // 可选，同 WebhookRequest.Period
+	Org      string `json:"org"`      // 可选：只统计该组织下的仓库
+	Repo     string `json:"repo"`     // 可选：只统计该仓库，owner/name
+	Style    string `json:"style"`    // 可选：brief 或 detailed
+	Language string `json:"language"` // 可选：zh 或 en
+	Format   string `json:"format"`   // 可选：json（默认）或 html，html 返回内嵌图表的独立页面
+}
+
+// Report 处理 POST /api/v1/reports
+// 同步生成报告并直接返回 Markdown，不发送到飞书
+func (h *Handler) Report(c *gin.Context) {
+	var req ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log := func(message string) {
+		println("[Report]", message)
+	}
+
+	p, _, err := h.resolvePeriod(req.Username, req.Period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Style != "" && req.Style != llm.StyleBrief && req.Style != llm.StyleDetailed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "style must be brief or detailed"})
+		return
+	}
+	if req.Language != "" && req.Language != llm.LanguageZh && req.Language != llm.LanguageEn {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "language must be zh or en"})
+		return
+	}
+	if req.Format != "" && req.Format != "json" && req.Format != "html" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or html"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	llmClient, err := llm.NewClient(h.config.LLM)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	style, language := req.Style, req.Language
+	if style == "" {
+		style = h.config.Report.Style
+	}
+	if language == "" {
+		language = h.config.Report.Language
+	}
+
+	report, err := h.generateReport(ctx, llmClient, reportRequest{
+		Username: req.Username,
+		Period:   req.Period,
+		Org:      req.Org,
+		Repo:     req.Repo,
+		Style:    style,
+		Language: language,
+
+		Requester: "api",
+	}, log)
+	if err != nil {
+		log("错误: " + err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Format == "html" {
+		images, err := render.Render(report.Activity, report.Location)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		page, err := render.HTML(fmt.Sprintf("%s · %s", req.Username, p.Label), report.Content, language, images)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"username": req.Username,
+		"period": gin.H{
+			"label": p.Label,
+			"since": p.Since.Format(time.RFC3339),
+			"until": p.Until.Format(time.RFC3339),
+		},
+		"report":     report.Content,
+		"statistics": report.Activity.Statistics(report.Location),
+	})
+}
+
+// ListReports 处理 GET /api/v1/reports
+// 按 username、since、until（YYYY-MM-DD，按报告时区解析）和 limit 查询存档的报告，不返回正文
+func (h *Handler) ListReports(c *gin.Context) {
+	if h.store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "report history is disabled"})
+		return
+	}
+
+	q := store.ReportQuery{Username: c.Query("username"), Limit: 50}
+	loc, err := h.config.Location(q.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for name, dst := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		if v := c.Query(name); v != "" {
+			t, err := time.ParseInLocation("2006-01-02", v, loc)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s: %s", name, v)})
+				return
+			}
+			*dst = t
+		}
+	}
+	if !q.Until.IsZero() {
+		q.Until = q.Until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		q.Limit = limit
+	}
+
+	reports, err := h.store.Reports(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range reports {
+		reports[i].Content = ""
+	}
+	c.JSON(http.StatusOK, gin.H{"reports": reports})
+}
+
+// GetReport 处理 GET /api/v1/reports/:id，返回存档的报告全文
+func (h *Handler) GetReport(c *gin.Context) {
+	report, ok := h.archivedReport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ResendReport 处理 POST /api/v1/reports/:id/resend，把存档的报告重新发送到飞书
+func (h *Handler) ResendReport(c *gin.Context) {
+	report, ok := h.archivedReport(c)
+	if !ok {
+		return
+	}
+
+	if !h.config.Notifiers.Feishu.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Feishu notification is not enabled"})
+		return
+	}
+	feishuNotifier := h.feishuNotifier()
+	if err := feishuNotifier.Send(c.Request.Context(), report.Content); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// archivedReport 读取路径参数 id 对应的存档报告，失败时已写入错误响应
+func (h *Handler) archivedReport(c *gin.Context) (*store.Report, bool) {
+	if h.store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "report history is disabled"})
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report id"})
+		return nil, false
+	}
+
+	report, err := h.store.Report(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "report not found"})
+		return nil, false
+	}
+	return report, true
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github-reports/internal/config"
+	"github-reports/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newReportsRouter 创建一个带存档的路由，存档中有一份上海时区 2026-10-12 ~ 2026-10-18 的周报
+func newReportsRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	st, err := store.Open(filepath.Join(t.TempDir(), "reports.db"))
+	if err != nil {
+		t.Fatalf("store.Open: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	if err := st.SaveReport(&store.Report{
+		Username: "octocat",
+		Since:    time.Date(2026, 10, 12, 0, 0, 0, 0, loc),
+		Until:    time.Date(2026, 10, 18, 23, 59, 59, 0, loc),
+		Content:  "# report",
+	}); err != nil {
+		t.Fatalf("SaveReport: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Webhook.Token = "secret"
+	cfg.Report.Timezone = "Asia/Shanghai"
+	h := NewHandler(cfg, nil, st)
+
+	router := gin.New()
+	router.GET("/reports", h.ListReports)
+	router.GET("/reports/:id", h.GetReport)
+	router.POST("/reports/:id/resend", h.ResendReport)
+	return router
+}
+
+func TestListReportsTimezone(t *testing.T) {
+	router := newReportsRouter(t)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		// 按 UTC 解析时 10-11 的结束时刻晚于上海时间 10-12 零点，会误匹配
+		{"until=2026-10-11", 0},
+		{"until=2026-10-12", 1},
+		{"since=2026-10-18", 1},
+		{"since=2026-10-19", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports?"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d (body %s)", w.Code, w.Body.String())
+			}
+			var body struct {
+				Reports []store.Report `json:"reports"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if len(body.Reports) != tt.want {
+				t.Errorf("got %d reports, want %d", len(body.Reports), tt.want)
+			}
+		})
+	}
+}
+
+func TestReportEndpoints(t *testing.T) {
+	router := newReportsRouter(t)
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{http.MethodGet, "/reports/1", http.StatusOK},
+		{http.MethodGet, "/reports/1?resend=true", http.StatusOK},
+		{http.MethodGet, "/reports/2", http.StatusNotFound},
+		{http.MethodGet, "/reports/abc", http.StatusBadRequest},
+		// 飞书通知未启用
+		{http.MethodPost, "/reports/1/resend", http.StatusBadRequest},
+		{http.MethodPost, "/reports/2/resend", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
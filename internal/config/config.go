@@ -2,6 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github-reports/internal/period"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +18,78 @@ type Config struct {
 	LLM       LLMConfig       `mapstructure:"llm"`
 	Notifiers NotifiersConfig `mapstructure:"notifiers"`
 	Webhook   WebhookConfig   `mapstructure:"webhook"`
+	Users     []UserConfig    `mapstructure:"users"`
+	Sources   SourcesConfig   `mapstructure:"sources"`
+	Report    ReportConfig    `mapstructure:"report"`
+	Directory DirectoryConfig `mapstructure:"directory"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+}
+
+// StorageConfig 配置活动和报告的历史存储
+type StorageConfig struct {
+	Path string `mapstructure:"path"` // bbolt 数据库文件，默认 data/history.db；设为空字符串时不保存历史
+}
+
+// DirectoryConfig 配置飞书用户到 GitHub 登录名的映射，用于识别消息中的「我」和 @ 提及
+type DirectoryConfig struct {
+	StorePath string           `mapstructure:"store_path"` // 通过 /bind 命令添加的映射保存位置，默认 data/directory.json
+	Admins    []string         `mapstructure:"admins"`     // 可以使用 /bind、/unbind 命令的飞书 open_id 或 user_id
+	Entries   []DirectoryEntry `mapstructure:"entries"`
+}
+
+// DirectoryEntry 是一条静态映射，open_id、user_id、name 至少填一个
+type DirectoryEntry struct {
+	OpenID string `mapstructure:"open_id"`
+	UserID string `mapstructure:"user_id"`
+	Name   string `mapstructure:"name"` // 飞书显示名
+	Login  string `mapstructure:"login"`
+}
+
+// IsDirectoryAdmin 判断飞书用户是否可以修改映射
+func (c *Config) IsDirectoryAdmin(openID, userID string) bool {
+	for _, admin := range c.Directory.Admins {
+		if admin != "" && (admin == openID || admin == userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportConfig 配置报告的生成方式
+type ReportConfig struct {
+	Timezone      string          `mapstructure:"timezone"`       // IANA 时区名，如 Asia/Shanghai；默认使用服务器本地时区
+	DefaultPeriod string          `mapstructure:"default_period"` // 未指定周期时使用，如 last-week；默认为最近 7 天
+	Sprint        SprintConfig    `mapstructure:"sprint"`
+	Style         string          `mapstructure:"style"`      // 默认报告风格：brief 或 detailed
+	Language      string          `mapstructure:"language"`   // 默认报告语言：zh 或 en
+	Comparison    bool            `mapstructure:"comparison"` // 是否与上一个同类周期做环比，默认开启
+	Charts        bool            `mapstructure:"charts"`     // 是否在推送报告后附上贡献热力图等图表，需要支持图片的通知渠道
+	MapReduce     MapReduceConfig `mapstructure:"map_reduce"`
+	Diffs         DiffConfig      `mapstructure:"diffs"`
+	Layout        []string        `mapstructure:"layout"` // 报告的段落顺序，未列出的段落不输出；为空时使用全部段落的默认顺序
+}
+
+// DiffConfig 控制是否把重点 PR / commit 的 diff 发给 LLM。
+// 代码会离开本机发送给 LLM 服务商，因此默认关闭，且私有仓库需要单独允许。
+type DiffConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	TopN             int      `mapstructure:"top_n"`               // 附带 diff 的工作流数量，按重要性选取，默认 5
+	MaxTokensPerItem int      `mapstructure:"max_tokens_per_item"` // 每个 diff 截断后的 token 上限，默认 800
+	IncludePrivate   bool     `mapstructure:"include_private"`     // 是否发送私有仓库的 diff，默认不发送
+	ExcludeRepos     []string `mapstructure:"exclude_repos"`       // 永远不发送 diff 的仓库，支持通配符，如 acme/secret-*
+}
+
+// MapReduceConfig 控制大型活动集的分仓库摘要：先为每个仓库单独生成摘要，再合成最终报告
+type MapReduceConfig struct {
+	Mode        string `mapstructure:"mode"`        // auto（默认，超出 token 预算且涉及多个仓库时启用）、always 或 off
+	Concurrency int    `mapstructure:"concurrency"` // 同时进行的仓库摘要请求数，默认 4
+}
+
+// SprintConfig 定义固定节奏的迭代，用于解析 "sprint 42" 等周期
+type SprintConfig struct {
+	Start       string `mapstructure:"start"`        // 第一个迭代的开始日期，格式 2006-01-02
+	LengthDays  int    `mapstructure:"length_days"`  // 每个迭代的天数
+	FirstNumber int    `mapstructure:"first_number"` // 第一个迭代的编号，默认 1
 }
 
 type ServerConfig struct {
@@ -23,7 +101,21 @@ type WebhookConfig struct {
 }
 
 type GitHubConfig struct {
-	Tokens []GitHubToken `mapstructure:"tokens"`
+	Tokens  []GitHubToken `mapstructure:"tokens"`
+	BaseURL string        `mapstructure:"base_url"` // 可选：GitHub Enterprise 的 REST API 地址，如 https://ghe.example.com/api/v3/
+}
+
+// TokenFor 返回查询该用户时使用的令牌：优先使用为该用户配置的令牌，否则使用第一个
+func (c *GitHubConfig) TokenFor(username string) string {
+	for _, t := range c.Tokens {
+		if strings.EqualFold(t.Username, username) {
+			return t.Token
+		}
+	}
+	if len(c.Tokens) > 0 {
+		return c.Tokens[0].Token
+	}
+	return ""
 }
 
 type GitHubToken struct {
@@ -31,12 +123,67 @@ type GitHubToken struct {
 	Username string `mapstructure:"username"` // 可选：如果不指定，则允许查询任何用户
 }
 
+// UserConfig 是针对某个报告对象（GitHub 用户）的个性化配置
+type UserConfig struct {
+	Login      string           `mapstructure:"login"`
+	Aliases    []string         `mapstructure:"aliases"`    // 可选：聊天中对此人的称呼，如「小明」，用于从消息中识别用户
+	Timezone   string           `mapstructure:"timezone"`   // 可选，覆盖 report.timezone
+	Emails     []string         `mapstructure:"emails"`     // commit 中使用的邮箱或姓名，用于在本地仓库和推送中识别本人
+	Identities []IdentityConfig `mapstructure:"identities"` // 可选：要合并的平台身份，未配置时只使用 GitHub
+	Roadmap    RoadmapConfig    `mapstructure:"roadmap"`
+}
+
+// IdentityConfig 是用户在某个平台上的身份
+type IdentityConfig struct {
+	Provider string `mapstructure:"provider"` // github, gitlab, gitea, local
+	Username string `mapstructure:"username"` // 可选，默认与 login 相同
+}
+
+// RoadmapConfig 配置需要映射到报告中的 Projects v2 看板和里程碑
+type RoadmapConfig struct {
+	Projects       []ProjectConfig `mapstructure:"projects"`
+	MilestoneRepos []string        `mapstructure:"milestone_repos"` // owner/repo
+}
+
+type ProjectConfig struct {
+	Owner       string `mapstructure:"owner"`  // 组织或用户登录名
+	Number      int    `mapstructure:"number"` // 看板编号，见 URL 中的 /projects/{number}
+	StatusField string `mapstructure:"status_field"`
+	DoneStatus  string `mapstructure:"done_status"`
+}
+
+// SourcesConfig 配置 GitHub API 之外的活动数据来源
+type SourcesConfig struct {
+	LocalGit LocalGitConfig `mapstructure:"local_git"`
+	GitLab   ForgeConfig    `mapstructure:"gitlab"`
+	Gitea    ForgeConfig    `mapstructure:"gitea"`
+}
+
+// ForgeConfig 配置一个自建代码托管平台
+type ForgeConfig struct {
+	BaseURL string `mapstructure:"base_url"`
+	Token   string `mapstructure:"token"`
+}
+
+// LocalGitConfig 配置从本地克隆读取活动
+type LocalGitConfig struct {
+	Offline bool              `mapstructure:"offline"` // 为 true 时只读取本地仓库，不访问 GitHub API
+	Repos   []LocalRepoConfig `mapstructure:"repos"`
+}
+
+type LocalRepoConfig struct {
+	Path string `mapstructure:"path"`
+	Name string `mapstructure:"name"` // 可选，报告中显示的仓库名，默认为目录名
+}
+
 type LLMConfig struct {
 	Provider       string `mapstructure:"provider"` // openai, claude, custom
 	APIKey         string `mapstructure:"api_key"`
 	Model          string `mapstructure:"model"`
 	PromptTemplate string `mapstructure:"prompt_template"`
 	BaseURL        string `mapstructure:"base_url"` // 可选，用于自定义端点
+	// MaxInputTokens 是报告活动数据的 token 预算，超出时只保留最重要的条目；为 0 时按模型的上下文长度计算
+	MaxInputTokens int `mapstructure:"max_input_tokens"`
 }
 
 type NotifiersConfig struct {
@@ -46,6 +193,11 @@ type NotifiersConfig struct {
 type FeishuConfig struct {
 	Enabled    bool   `mapstructure:"enabled"`
 	WebhookURL string `mapstructure:"webhook_url"`
+	AppID      string `mapstructure:"app_id"`     // 可选：自建应用凭证，上传图表图片需要
+	AppSecret  string `mapstructure:"app_secret"` // 可选：与 app_id 一起配置
+	// VerificationToken 是事件订阅的 Verification Token。飞书直接推送的事件无法携带 Authorization 头，
+	// 配置后 webhook 接口接受 token 与之相同的事件
+	VerificationToken string `mapstructure:"verification_token"`
 }
 
 // Load 从文件加载配置
@@ -66,6 +218,16 @@ func Load(configPath string) (*Config, error) {
 	v.SetDefault("llm.provider", "deepseek")
 	v.SetDefault("llm.model", "deepseek-chat")
 	v.SetDefault("llm.base_url", "https://api.deepseek.com/v1")
+	v.SetDefault("report.sprint.first_number", 1)
+	v.SetDefault("report.style", "brief")
+	v.SetDefault("report.language", "zh")
+	v.SetDefault("report.comparison", true)
+	v.SetDefault("report.map_reduce.mode", "auto")
+	v.SetDefault("report.map_reduce.concurrency", 4)
+	v.SetDefault("report.diffs.top_n", 5)
+	v.SetDefault("report.diffs.max_tokens_per_item", 800)
+	v.SetDefault("directory.store_path", "data/directory.json")
+	v.SetDefault("storage.path", "data/history.db")
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
@@ -79,12 +241,70 @@ func Load(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// User 返回指定 GitHub 用户的配置，未配置时返回 nil
+func (c *Config) User(login string) *UserConfig {
+	for i := range c.Users {
+		if strings.EqualFold(c.Users[i].Login, login) {
+			return &c.Users[i]
+		}
+	}
+	return nil
+}
+
+// Aliases 返回所有用户别名到 GitHub 登录名的映射
+func (c *Config) Aliases() map[string]string {
+	aliases := make(map[string]string)
+	for _, u := range c.Users {
+		for _, alias := range u.Aliases {
+			aliases[alias] = u.Login
+		}
+	}
+	return aliases
+}
+
+// Location 返回生成该用户报告时使用的时区：用户配置优先，其次是 report.timezone，最后是服务器本地时区
+func (c *Config) Location(login string) (*time.Location, error) {
+	name := c.Report.Timezone
+	if u := c.User(login); u != nil && u.Timezone != "" {
+		name = u.Timezone
+	}
+	if name == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// PeriodResolver 创建指定时区的周期解析器，配置了迭代节奏时支持 sprint 表达式
+func (c ReportConfig) PeriodResolver(loc *time.Location) *period.Resolver {
+	if c.Sprint.Start == "" {
+		return period.NewResolver(loc, nil)
+	}
+
+	start, _ := time.ParseInLocation("2006-01-02", c.Sprint.Start, loc) // 已在 Validate 中校验
+	return period.NewResolver(loc, &period.Sprint{
+		Start:       start,
+		Length:      c.Sprint.LengthDays,
+		FirstNumber: c.Sprint.FirstNumber,
+	})
+}
+
 // Validate 验证配置
 func (c *Config) Validate() error {
-	if len(c.GitHub.Tokens) == 0 {
+	if len(c.GitHub.Tokens) == 0 && !c.Sources.LocalGit.Offline {
 		return fmt.Errorf("at least one GitHub token is required")
 	}
 
+	if c.GitHub.BaseURL != "" {
+		if _, err := url.Parse(c.GitHub.BaseURL); err != nil {
+			return fmt.Errorf("invalid github base_url: %w", err)
+		}
+	}
+
 	if c.LLM.APIKey == "" {
 		return fmt.Errorf("LLM API key is required")
 	}
@@ -93,9 +313,129 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("only deepseek provider is supported, got: %s", c.LLM.Provider)
 	}
 
+	if c.LLM.MaxInputTokens < 0 {
+		return fmt.Errorf("llm.max_input_tokens must not be negative")
+	}
+
 	if c.Webhook.Token == "" {
 		return fmt.Errorf("webhook token is required")
 	}
 
+	if c.Sources.LocalGit.Offline && len(c.Sources.LocalGit.Repos) == 0 {
+		return fmt.Errorf("sources.local_git: offline mode requires at least one repo")
+	}
+	for _, repo := range c.Sources.LocalGit.Repos {
+		if repo.Path == "" {
+			return fmt.Errorf("sources.local_git: repo path is required")
+		}
+	}
+
+	reportLoc, err := time.LoadLocation(c.Report.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid report.timezone: %w", err)
+	}
+
+	if c.Report.Style != "" && c.Report.Style != "brief" && c.Report.Style != "detailed" {
+		return fmt.Errorf("report.style must be brief or detailed, got: %s", c.Report.Style)
+	}
+	if c.Report.Language != "" && c.Report.Language != "zh" && c.Report.Language != "en" {
+		return fmt.Errorf("report.language must be zh or en, got: %s", c.Report.Language)
+	}
+
+	switch c.Report.MapReduce.Mode {
+	case "", "auto", "always", "off":
+	default:
+		return fmt.Errorf("report.map_reduce.mode must be auto, always or off, got: %s", c.Report.MapReduce.Mode)
+	}
+	if c.Report.MapReduce.Concurrency < 0 {
+		return fmt.Errorf("report.map_reduce.concurrency must not be negative")
+	}
+	if c.Report.Diffs.TopN < 0 {
+		return fmt.Errorf("report.diffs.top_n must not be negative")
+	}
+	if c.Report.Diffs.MaxTokensPerItem < 0 {
+		return fmt.Errorf("report.diffs.max_tokens_per_item must not be negative")
+	}
+	seen := make(map[string]bool)
+	for _, section := range c.Report.Layout {
+		switch section {
+		case "header", "projects", "roadmap", "analysis", "statistics", "merged_prs", "releases", "comparison":
+		default:
+			return fmt.Errorf("unknown report.layout section: %s", section)
+		}
+		if seen[section] {
+			return fmt.Errorf("duplicate report.layout section: %s", section)
+		}
+		seen[section] = true
+	}
+	for _, pattern := range c.Report.Diffs.ExcludeRepos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid report.diffs.exclude_repos pattern %q: %w", pattern, err)
+		}
+	}
+
+	if c.Report.Sprint.Start != "" {
+		if _, err := time.Parse("2006-01-02", c.Report.Sprint.Start); err != nil {
+			return fmt.Errorf("invalid report.sprint.start: %w", err)
+		}
+		if c.Report.Sprint.LengthDays <= 0 {
+			return fmt.Errorf("report.sprint.length_days must be positive")
+		}
+	}
+	if c.Report.DefaultPeriod != "" {
+		if err := c.Report.PeriodResolver(reportLoc).Check(c.Report.DefaultPeriod); err != nil {
+			return fmt.Errorf("invalid report.default_period: %w", err)
+		}
+	}
+
+	for _, e := range c.Directory.Entries {
+		if e.Login == "" {
+			return fmt.Errorf("directory.entries: login is required")
+		}
+		if e.OpenID == "" && e.UserID == "" && e.Name == "" {
+			return fmt.Errorf("directory.entries.%s: one of open_id, user_id or name is required", e.Login)
+		}
+	}
+
+	aliasOwners := make(map[string]string)
+	for _, u := range c.Users {
+		if u.Login == "" {
+			return fmt.Errorf("users: login is required")
+		}
+		for _, alias := range u.Aliases {
+			key := strings.ToLower(strings.TrimSpace(alias))
+			if key == "" {
+				return fmt.Errorf("users.%s: alias must not be empty", u.Login)
+			}
+			if owner, ok := aliasOwners[key]; ok && owner != u.Login {
+				return fmt.Errorf("users.%s: alias %q is already used by %s", u.Login, alias, owner)
+			}
+			aliasOwners[key] = u.Login
+		}
+		if _, err := time.LoadLocation(u.Timezone); err != nil {
+			return fmt.Errorf("users.%s: invalid timezone: %w", u.Login, err)
+		}
+		for _, id := range u.Identities {
+			switch id.Provider {
+			case "github", "local":
+			case "gitlab":
+				if c.Sources.GitLab.BaseURL == "" {
+					return fmt.Errorf("users.%s: gitlab identity requires sources.gitlab.base_url", u.Login)
+				}
+			case "gitea":
+				if c.Sources.Gitea.BaseURL == "" {
+					return fmt.Errorf("users.%s: gitea identity requires sources.gitea.base_url", u.Login)
+				}
+			default:
+				return fmt.Errorf("users.%s: unsupported identity provider: %s", u.Login, id.Provider)
+			}
+		}
+		for _, p := range u.Roadmap.Projects {
+			if p.Owner == "" || p.Number <= 0 {
+				return fmt.Errorf("users.%s.roadmap: project owner and number are required", u.Login)
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// validConfig 返回一份能通过 Validate 的最小配置
+func validConfig() *Config {
+	return &Config{
+		GitHub:  GitHubConfig{Tokens: []GitHubToken{{Username: "octocat", Token: "t"}}},
+		LLM:     LLMConfig{Provider: "deepseek", APIKey: "k"},
+		Webhook: WebhookConfig{Token: "w"},
+	}
+}
+
+func TestValidateDefaultPeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		period  string
+		sprint  SprintConfig
+		wantErr bool
+	}{
+		{"empty", "", SprintConfig{}, false},
+		{"named period", "last-week", SprintConfig{}, false},
+		{"chinese alias", "上个月", SprintConfig{}, false},
+		{"unknown expression", "fortnight", SprintConfig{}, true},
+		{"sprint without cadence", "last-sprint", SprintConfig{}, true},
+		{"sprint with cadence", "last-sprint", SprintConfig{Start: "2026-03-02", LengthDays: 14}, false},
+		{"ISO week 53 in a 52-week year", "2025-W53", SprintConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Report.DefaultPeriod = tt.period
+			cfg.Report.Sprint = tt.sprint
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenFor(t *testing.T) {
+	cfg := GitHubConfig{Tokens: []GitHubToken{
+		{Token: "default"},
+		{Token: "octo", Username: "OctoCat"},
+	}}
+
+	tests := []struct {
+		username string
+		want     string
+	}{
+		{"OctoCat", "octo"},
+		{"octocat", "octo"},
+		{"someone", "default"},
+	}
+	for _, tt := range tests {
+		if got := cfg.TokenFor(tt.username); got != tt.want {
+			t.Errorf("TokenFor(%q) = %q, want %q", tt.username, got, tt.want)
+		}
+	}
+	if got := (&GitHubConfig{}).TokenFor("octocat"); got != "" {
+		t.Errorf("TokenFor without tokens = %q, want empty", got)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	if _, err := time.LoadLocation("Asia/Shanghai"); err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	cfg := validConfig()
+	cfg.Report.Timezone = "America/New_York"
+	cfg.Users = []UserConfig{
+		{Login: "OctoCat", Timezone: "Asia/Shanghai"},
+		{Login: "hubot"},
+		{Login: "broken", Timezone: "Mars/Olympus"},
+	}
+
+	tests := []struct {
+		name    string
+		login   string
+		report  string
+		want    string
+		wantErr bool
+	}{
+		{"user time zone, login is case-insensitive", "octocat", "America/New_York", "Asia/Shanghai", false},
+		{"report time zone for a user without one", "hubot", "America/New_York", "America/New_York", false},
+		{"report time zone for an unknown user", "someone", "America/New_York", "America/New_York", false},
+		{"server local time zone", "hubot", "", "Local", false},
+		{"invalid user time zone", "broken", "America/New_York", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Report.Timezone = tt.report
+			loc, err := cfg.Location(tt.login)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Location(%s) error = %v, want error %v", tt.login, err, tt.wantErr)
+			}
+			if err == nil && loc.String() != tt.want {
+				t.Errorf("Location(%s) = %s, want %s", tt.login, loc, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package directory 维护聊天用户（飞书 open_id / user_id / 显示名）到 GitHub 登录名的映射
+package directory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Identity 是聊天平台上的一个用户，字段可以只填一部分
+type Identity struct {
+	OpenID string `json:"open_id,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Entry 是一条映射
+type Entry struct {
+	Identity
+	Login string `json:"login"`
+}
+
+// Directory 合并配置中的静态映射和通过聊天命令维护的映射。
+// 两者冲突时以存储中的映射为准，便于在不改配置的情况下修正。
+type Directory struct {
+	mu        sync.RWMutex
+	static    []Entry
+	stored    []Entry
+	storePath string
+}
+
+// New 创建目录并加载 storePath 中已保存的映射；storePath 为空时绑定只保存在内存中
+func New(entries []Entry, storePath string) (*Directory, error) {
+	d := &Directory{
+		static:    entries,
+		storePath: storePath,
+	}
+
+	if storePath == "" {
+		return d, nil
+	}
+
+	data, err := os.ReadFile(storePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory store: %w", err)
+	}
+	if err := json.Unmarshal(data, &d.stored); err != nil {
+		return nil, fmt.Errorf("failed to parse directory store %s: %w", storePath, err)
+	}
+
+	return d, nil
+}
+
+// Lookup 返回聊天用户对应的 GitHub 登录名。依次按 open_id、user_id、显示名匹配。
+func (d *Directory) Lookup(id Identity) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, match := range []func(Entry) bool{
+		func(e Entry) bool { return id.OpenID != "" && e.OpenID == id.OpenID },
+		func(e Entry) bool { return id.UserID != "" && e.UserID == id.UserID },
+		func(e Entry) bool { return id.Name != "" && strings.EqualFold(e.Name, id.Name) },
+	} {
+		// 存储中的映射优先
+		for _, entries := range [][]Entry{d.stored, d.static} {
+			for _, e := range entries {
+				if match(e) {
+					return e.Login, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Bind 添加或更新一条映射并持久化。同一个聊天用户只保留最新的一条。
+func (d *Directory) Bind(entry Entry) error {
+	if entry.Login == "" {
+		return fmt.Errorf("login is required")
+	}
+	if entry.OpenID == "" && entry.UserID == "" && entry.Name == "" {
+		return fmt.Errorf("chat identity is required")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stored := d.without(entry.Identity)
+	stored = append(stored, entry)
+	if err := d.save(stored); err != nil {
+		return err
+	}
+	d.stored = stored
+	return nil
+}
+
+// Unbind 删除聊天用户或 GitHub 登录名对应的映射，返回删除的条数。
+// 只能删除通过 Bind 添加的映射，配置中的映射需要修改配置文件。
+func (d *Directory) Unbind(id Identity, login string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stored := d.without(id)
+	if login != "" {
+		kept := stored[:0:0]
+		for _, e := range stored {
+			if !strings.EqualFold(e.Login, login) {
+				kept = append(kept, e)
+			}
+		}
+		stored = kept
+	}
+
+	removed := len(d.stored) - len(stored)
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := d.save(stored); err != nil {
+		return 0, err
+	}
+	d.stored = stored
+	return removed, nil
+}
+
+// Entries 返回当前生效的所有映射，按登录名排序
+func (d *Directory) Entries() []Entry {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries := append(append([]Entry(nil), d.stored...), d.static...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Login) < strings.ToLower(entries[j].Login)
+	})
+	return entries
+}
+
+// without 返回去掉与 id 匹配的条目后的存储映射副本
+func (d *Directory) without(id Identity) []Entry {
+	var kept []Entry
+	for _, e := range d.stored {
+		if (id.OpenID != "" && e.OpenID == id.OpenID) ||
+			(id.UserID != "" && e.UserID == id.UserID) ||
+			(id.Name != "" && strings.EqualFold(e.Name, id.Name)) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// save 先写临时文件再重命名，避免进程中断时留下半个文件
+func (d *Directory) save(entries []Entry) error {
+	if d.storePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal directory: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.storePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory store dir: %w", err)
+	}
+	tmp := d.storePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write directory store: %w", err)
+	}
+	if err := os.Rename(tmp, d.storePath); err != nil {
+		return fmt.Errorf("failed to replace directory store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package directory
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	static := []Entry{
+		{Identity: Identity{OpenID: "ou_alice", Name: "Alice"}, Login: "alice-static"},
+		{Identity: Identity{UserID: "u_bob"}, Login: "bob"},
+		{Identity: Identity{Name: "Carol"}, Login: "carol"},
+	}
+	d, err := New(static, "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := d.Bind(Entry{Identity: Identity{OpenID: "ou_alice"}, Login: "alice"}); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if err := d.Bind(Entry{Identity: Identity{Name: "Bob"}, Login: "bob-by-name"}); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		id   Identity
+		want string
+	}{
+		{"stored overrides static", Identity{OpenID: "ou_alice"}, "alice"},
+		{"open_id before name", Identity{OpenID: "ou_alice", Name: "Carol"}, "alice"},
+		{"user_id before name", Identity{UserID: "u_bob", Name: "Bob"}, "bob"},
+		{"name is case-insensitive", Identity{Name: "carol"}, "carol"},
+		{"stored name", Identity{Name: "bob"}, "bob-by-name"},
+		{"unknown", Identity{OpenID: "ou_nobody", Name: "Nobody"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := d.Lookup(tt.id)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("Lookup(%+v) = %q, %v, want %q", tt.id, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "directory.json")
+	static := []Entry{{Identity: Identity{Name: "Carol"}, Login: "carol"}}
+
+	d, err := New(static, path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := d.Bind(Entry{Identity: Identity{OpenID: "ou_alice"}, Login: "alice"}); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	// 同一个聊天用户只保留最新的一条
+	if err := d.Bind(Entry{Identity: Identity{OpenID: "ou_alice"}, Login: "alice2"}); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if err := d.Bind(Entry{Identity: Identity{UserID: "u_bob"}, Login: "bob"}); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if err := d.Bind(Entry{Login: "nobody"}); err == nil {
+		t.Error("Bind without identity succeeded")
+	}
+
+	reloaded, err := New(static, path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got, _ := reloaded.Lookup(Identity{OpenID: "ou_alice"}); got != "alice2" {
+		t.Errorf("reloaded alice = %q, want alice2", got)
+	}
+	if n := len(reloaded.Entries()); n != 3 {
+		t.Errorf("reloaded %d entries, want 3", n)
+	}
+
+	// 配置中的映射不能通过 Unbind 删除
+	if removed, err := reloaded.Unbind(Identity{Name: "Carol"}, "carol"); err != nil || removed != 0 {
+		t.Errorf("Unbind(static) = %d, %v, want 0", removed, err)
+	}
+	if got, _ := reloaded.Lookup(Identity{Name: "Carol"}); got != "carol" {
+		t.Errorf("static carol = %q after Unbind, want carol", got)
+	}
+
+	if removed, err := reloaded.Unbind(Identity{}, "BOB"); err != nil || removed != 1 {
+		t.Errorf("Unbind(bob) = %d, %v, want 1", removed, err)
+	}
+	again, err := New(static, path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := again.Lookup(Identity{UserID: "u_bob"}); ok {
+		t.Error("bob is still bound after Unbind and reload")
+	}
+}
@@ -0,0 +1,168 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 变更类型，取值与 Conventional Commits 的 type 一致
+const (
+	ChangeFeat     = "feat"
+	ChangeFix      = "fix"
+	ChangeRefactor = "refactor"
+	ChangePerf     = "perf"
+	ChangeDocs     = "docs"
+	ChangeTest     = "test"
+	ChangeBuild    = "build"
+	ChangeCI       = "ci"
+	ChangeStyle    = "style"
+	ChangeChore    = "chore"
+	ChangeRevert   = "revert"
+	ChangeOther    = "other" // 无法判断
+)
+
+// Change 是对一条 commit 或一个 PR 的变更类型判断
+type Change struct {
+	Type     string
+	Scope    string
+	Breaking bool
+}
+
+// conventionalPattern 匹配 Conventional Commits 标题，例如 "feat(api)!: add search"
+var conventionalPattern = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?\s*[:：]\s*\S`)
+
+// typeAliases 把常见的非标准写法归一到标准类型
+var typeAliases = map[string]string{
+	"feat":        ChangeFeat,
+	"feature":     ChangeFeat,
+	"fix":         ChangeFix,
+	"bugfix":      ChangeFix,
+	"hotfix":      ChangeFix,
+	"refactor":    ChangeRefactor,
+	"perf":        ChangePerf,
+	"docs":        ChangeDocs,
+	"doc":         ChangeDocs,
+	"test":        ChangeTest,
+	"tests":       ChangeTest,
+	"build":       ChangeBuild,
+	"deps":        ChangeBuild,
+	"ci":          ChangeCI,
+	"style":       ChangeStyle,
+	"chore":       ChangeChore,
+	"revert":      ChangeRevert,
+	"release":     ChangeChore,
+	"improvement": ChangeFeat,
+}
+
+// changeKeywords 是自由文本的关键字规则，按顺序匹配，先命中者优先。
+// 英文关键字按整词匹配（允许 s/es/ed/ing 词尾），以 * 结尾的按前缀匹配；中文关键字和多词短语按子串匹配。
+// 排在前面的规则只收录含义明确的词："问题"（如"新增问题反馈"）、"patch"（如"patch version bump"）常出现在其他类型的改动中，不作为修复的依据。
+var changeKeywords = []struct {
+	typ      string
+	keywords []string
+}{
+	{ChangeRevert, []string{"revert", "回滚", "还原"}},
+	{ChangeChore, []string{"merge branch", "merge pull request", "merge remote"}},
+	{ChangeFix, []string{"fix", "bug", "hotfix", "crash*", "修复", "修正", "解决"}},
+	{ChangeDocs, []string{"doc", "docs", "documentation", "readme", "changelog", "文档", "注释"}},
+	{ChangeTest, []string{"test", "spec", "coverage", "测试", "单测", "用例"}},
+	{ChangePerf, []string{"perf", "performance", "optimi*", "speed up", "faster", "cache", "优化", "性能", "提速"}},
+	{ChangeRefactor, []string{"refactor", "restructure", "clean up", "cleanup", "rename", "simplif*", "重构", "整理", "拆分"}},
+	{ChangeCI, []string{"ci", "workflow", "github action", "pipeline", "流水线"}},
+	{ChangeBuild, []string{"bump", "upgrade", "dependenc*", "build", "docker*", "makefile", "go.mod", "依赖", "构建", "升级"}},
+	{ChangeStyle, []string{"format", "lint", "gofmt", "格式"}},
+	{ChangeFeat, []string{"add", "implement", "support", "introduce", "new", "allow", "enable", "新增", "添加", "增加", "支持", "实现", "引入"}},
+	{ChangeChore, []string{"chore", "release", "version", "update", "杂项", "发布", "版本"}},
+}
+
+// ClassifyChange 判断一条 commit message 或 PR 标题的变更类型：
+// 优先解析 Conventional Commits 前缀（type、scope、!），否则退回中英文关键字规则
+func ClassifyChange(message string) Change {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = strings.TrimSpace(subject)
+	breaking := strings.Contains(body, "BREAKING CHANGE:") || strings.Contains(body, "BREAKING-CHANGE:")
+
+	if m := conventionalPattern.FindStringSubmatch(subject); m != nil {
+		if typ, ok := typeAliases[strings.ToLower(m[1])]; ok {
+			return Change{
+				Type:     typ,
+				Scope:    strings.TrimSpace(m[2]),
+				Breaking: breaking || m[3] == "!",
+			}
+		}
+	}
+
+	return Change{Type: classifyKeywords(subject), Breaking: breaking}
+}
+
+// classifyKeywords 用关键字规则判断自由文本的变更类型
+func classifyKeywords(subject string) string {
+	lower := strings.ToLower(subject)
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.')
+	})
+
+	for _, rule := range changeKeywords {
+		for _, kw := range rule.keywords {
+			if matchKeyword(lower, words, kw) {
+				return rule.typ
+			}
+		}
+	}
+	return ChangeOther
+}
+
+func matchKeyword(lower string, words []string, kw string) bool {
+	if kw[0] >= 0x80 || strings.Contains(kw, " ") {
+		return strings.Contains(lower, kw)
+	}
+	if prefix, ok := strings.CutSuffix(kw, "*"); ok {
+		for _, w := range words {
+			if strings.HasPrefix(w, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, w := range words {
+		rest, ok := strings.CutPrefix(w, kw)
+		if !ok {
+			continue
+		}
+		switch rest {
+		case "", "s", "es", "d", "ed", "ing":
+			return true
+		}
+	}
+	return false
+}
+
+// dominantChange 返回一组 commit 中最常见的变更类型（忽略 other），用于标题无法判断的 PR
+func dominantChange(commits []CommitInfo) Change {
+	counts := make(map[string]int)
+	best := Change{Type: ChangeOther}
+	for _, c := range commits {
+		if c.Change.Breaking {
+			best.Breaking = true
+		}
+		if c.Change.Type == ChangeOther || c.Change.Type == "" {
+			continue
+		}
+		counts[c.Change.Type]++
+		if n := counts[c.Change.Type]; n > counts[best.Type] || n == counts[best.Type] && c.Change.Type < best.Type {
+			best.Type = c.Change.Type
+		}
+	}
+	return best
+}
+
+// classifyPullRequest 按标题判断 PR 的变更类型，标题无法判断时采用其 commit 中最常见的类型
+func classifyPullRequest(pr PullRequestInfo, commits []CommitInfo) Change {
+	change := ClassifyChange(pr.Title)
+	if change.Type == ChangeOther {
+		fallback := dominantChange(commits)
+		change.Type = fallback.Type
+		change.Breaking = change.Breaking || fallback.Breaking
+	}
+	return change
+}
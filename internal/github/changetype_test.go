@@ -0,0 +1,65 @@
+package github
+
+import "testing"
+
+func TestClassifyChange(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Change
+	}{
+		// Conventional Commits 前缀
+		{"feat: add search", Change{Type: ChangeFeat}},
+		{"fix(api): handle empty body", Change{Type: ChangeFix, Scope: "api"}},
+		{"feat(parser)!: drop legacy syntax", Change{Type: ChangeFeat, Scope: "parser", Breaking: true}},
+		{"refactor: split store\n\nBREAKING CHANGE: Open takes a path", Change{Type: ChangeRefactor, Breaking: true}},
+		{"feat：新增导出", Change{Type: ChangeFeat}},
+		{"docs(readme)：更新安装说明", Change{Type: ChangeDocs, Scope: "readme"}},
+		{"Hotfix: restore login", Change{Type: ChangeFix}},
+		{"wip: something", Change{Type: ChangeOther}},
+		// 关键字回退
+		{"Fixed crash on startup", Change{Type: ChangeFix}},
+		{"Merge pull request #7 from octo/export", Change{Type: ChangeChore}},
+		{"patch version bump", Change{Type: ChangeBuild}},
+		{"新增问题反馈入口", Change{Type: ChangeFeat}},
+		{"修复登录问题", Change{Type: ChangeFix}},
+		{"优化查询性能", Change{Type: ChangePerf}},
+		{"Add CSV export", Change{Type: ChangeFeat}},
+		{"Addressed review comments", Change{Type: ChangeOther}},
+		{"Update README", Change{Type: ChangeDocs}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := ClassifyChange(tt.message); got != tt.want {
+				t.Errorf("ClassifyChange(%q) = %+v, want %+v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDominantChange(t *testing.T) {
+	commit := func(typ string, breaking bool) CommitInfo {
+		return CommitInfo{Change: Change{Type: typ, Breaking: breaking}}
+	}
+
+	tests := []struct {
+		name    string
+		commits []CommitInfo
+		want    Change
+	}{
+		{"no commits", nil, Change{Type: ChangeOther}},
+		{"other is ignored", []CommitInfo{commit(ChangeOther, false), commit(ChangeOther, false), commit(ChangeFix, false)}, Change{Type: ChangeFix}},
+		{"most common wins", []CommitInfo{commit(ChangeFix, false), commit(ChangeFeat, false), commit(ChangeFeat, false)}, Change{Type: ChangeFeat}},
+		{"tie goes to the alphabetically first type", []CommitInfo{commit(ChangeFix, false), commit(ChangeFeat, false)}, Change{Type: ChangeFeat}},
+		{"tie order does not depend on commit order", []CommitInfo{commit(ChangeFeat, false), commit(ChangeFix, false)}, Change{Type: ChangeFeat}},
+		{"breaking from any commit", []CommitInfo{commit(ChangeOther, true), commit(ChangeDocs, false)}, Change{Type: ChangeDocs, Breaking: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dominantChange(tt.commits); got != tt.want {
+				t.Errorf("dominantChange = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,14 @@ package github
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 	"golang.org/x/oauth2"
@@ -14,20 +21,85 @@ type Client struct {
 	token  string
 }
 
+// ClientOption 配置 Client
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	baseURL       *url.URL
+	httpClient    *http.Client
+	rateLimitWait time.Duration
+}
+
+// WithBaseURL 指定 REST API 根地址，用于 GitHub Enterprise 或本地的假服务器
+func WithBaseURL(u *url.URL) ClientOption {
+	return func(o *clientOptions) {
+		base := *u
+		if !strings.HasSuffix(base.Path, "/") {
+			base.Path += "/"
+		}
+		o.baseURL = &base
+	}
+}
+
+// WithHTTPClient 指定底层 HTTP 客户端，认证会叠加在它的 Transport 之上
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = hc
+	}
+}
+
 // NewClient 创建一个新的 GitHub 客户端
-func NewClient(token string) *Client {
+func NewClient(token string, opts ...ClientOption) *Client {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.rateLimitWait > 0 {
+		hc := &http.Client{}
+		if o.httpClient != nil {
+			*hc = *o.httpClient
+		}
+		base := hc.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		hc.Transport = &rateLimitTransport{base: base, maxWait: o.rateLimitWait, now: time.Now}
+		o.httpClient = hc
+	}
+
 	ctx := context.Background()
+	if o.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, o.httpClient)
+	}
+
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
 
+	client := github.NewClient(tc)
+	if o.baseURL != nil {
+		client.BaseURL = o.baseURL
+	}
+
 	return &Client{
-		client: github.NewClient(tc),
+		client: client,
 		token:  token,
 	}
 }
 
+// Host 返回网页所在的主机名：github.com，或 GitHub Enterprise 的域名（REST API 位于同一域名的 /api/v3 下）
+func (c *Client) Host() string {
+	return strings.TrimPrefix(strings.ToLower(c.client.BaseURL.Hostname()), "api.")
+}
+
+// cacheScope 标识客户端能看到的数据范围（主机和 token 的指纹），用于隔离只对该 token 有效的缓存，不包含 token 本身
+func (c *Client) cacheScope() string {
+	sum := sha256.Sum256([]byte(c.token))
+	return c.Host() + "@" + hex.EncodeToString(sum[:8])
+}
+
 // GetAuthenticatedUser 返回已认证用户的登录名
 func (c *Client) GetAuthenticatedUser(ctx context.Context) (string, error) {
 	user, _, err := c.client.Users.Get(ctx, "")
@@ -41,3 +113,48 @@ func (c *Client) GetAuthenticatedUser(ctx context.Context) (string, error) {
 
 	return *user.Login, nil
 }
+
+// ErrUserNotFound 表示 GitHub 上不存在该用户
+var ErrUserNotFound = errors.New("github user not found")
+
+// LookupUser 通过 Users API 确认用户存在，并返回 GitHub 上规范大小写的登录名
+func (c *Client) LookupUser(ctx context.Context, login string) (string, error) {
+	user, resp, err := c.client.Users.Get(ctx, login)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("%s: %w", login, ErrUserNotFound)
+		}
+		return "", fmt.Errorf("failed to get user %s: %w", login, err)
+	}
+
+	if user.Login == nil {
+		return "", fmt.Errorf("user login is nil")
+	}
+
+	return *user.Login, nil
+}
+
+// OrgMembers 返回组织的所有成员登录名（令牌无权限时只能看到公开成员）
+func (c *Client) OrgMembers(ctx context.Context, org string) ([]string, error) {
+	var members []string
+	opts := &github.ListMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		users, resp, err := c.client.Organizations.ListMembers(ctx, org, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of %s: %w", org, err)
+		}
+		for _, u := range users {
+			if u.Login != nil {
+				members = append(members, *u.Login)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return members, nil
+}
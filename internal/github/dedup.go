@@ -0,0 +1,122 @@
+package github
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// dedupCommits 合并重复的 commit。
+//
+// 同一个 SHA 可能先推到功能分支、再推到主分支，或同时出现在 fork 中；
+// rebase / cherry-pick 后 SHA 会变化但补丁内容不变。两种情况都只保留一条记录，
+// 优先保留默认分支上的那次出现，并把所有出现过的 ref 合并到 Refs 中，避免重复统计代码行数。
+func dedupCommits(commits []CommitInfo, defaultBranches map[string]string) []CommitInfo {
+	result := make([]CommitInfo, 0, len(commits))
+	bySHA := make(map[string]int)
+	byPatch := make(map[string]int)
+
+	for _, c := range commits {
+		idx, ok := bySHA[c.SHA]
+		if !ok && c.PatchID != "" {
+			idx, ok = byPatch[c.PatchID]
+		}
+
+		if !ok {
+			result = append(result, c)
+			idx = len(result) - 1
+		} else {
+			result[idx] = mergeCommit(result[idx], c, defaultBranches)
+		}
+
+		bySHA[c.SHA] = idx
+		if c.PatchID != "" {
+			byPatch[c.PatchID] = idx
+		}
+	}
+
+	return result
+}
+
+// mergeCommit 合并同一改动的两次出现，返回应保留的那一条
+func mergeCommit(kept, dup CommitInfo, defaultBranches map[string]string) CommitInfo {
+	keptDefault := onDefaultBranch(kept, defaultBranches)
+	dupDefault := onDefaultBranch(dup, defaultBranches)
+
+	// 默认分支优先；都不在默认分支时保留最早的一次
+	if (dupDefault && !keptDefault) || (dupDefault == keptDefault && dup.Date.Before(kept.Date)) {
+		kept, dup = dup, kept
+	}
+
+	// 保留的那次出现排在首位，后续据此确定所在分支
+	kept.Refs = mergeRefs(kept.Refs, dup.Refs)
+	return kept
+}
+
+// onDefaultBranch 判断 commit 是否位于所在仓库的默认分支：
+// 已经标注过的直接使用标注，否则比较首个 ref 与默认分支
+func onDefaultBranch(c CommitInfo, defaultBranches map[string]string) bool {
+	if c.IsDefaultBranch {
+		return true
+	}
+	if len(c.Refs) == 0 {
+		return false
+	}
+
+	branch := defaultBranches[c.Repo]
+	return branch != "" && c.Refs[0] == c.Repo+":"+branch
+}
+
+func mergeRefs(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var refs []string
+	for _, ref := range append(append([]string{}, a...), b...) {
+		if ref == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// patchID 计算与 git patch-id 类似的补丁指纹：
+// 只取每个文件的增删行并去掉空白，忽略 hunk 头中的行号，这样 rebase 后的 commit 仍能匹配。
+func patchID(files []*github.CommitFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	sorted := make([]*github.CommitFile, 0, len(files))
+	for _, file := range files {
+		if file != nil && file.Patch != nil {
+			sorted = append(sorted, file)
+		}
+	}
+	if len(sorted) == 0 {
+		return ""
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return getStringValue(sorted[i].Filename) < getStringValue(sorted[j].Filename)
+	})
+
+	h := sha1.New()
+	for _, file := range sorted {
+		h.Write([]byte(getStringValue(file.Filename)))
+		h.Write([]byte{0})
+		for _, line := range strings.Split(*file.Patch, "\n") {
+			if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "-") {
+				continue
+			}
+			h.Write([]byte(line[:1]))
+			h.Write([]byte(strings.Join(strings.Fields(line[1:]), "")))
+			h.Write([]byte{'\n'})
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
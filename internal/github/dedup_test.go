@@ -0,0 +1,108 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDedupCommits(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 1, d, 12, 0, 0, 0, time.UTC)
+	}
+	defaults := map[string]string{"o/r": "main"}
+
+	tests := []struct {
+		name        string
+		commits     []CommitInfo
+		wantSHAs    []string
+		wantRefs    [][]string
+		wantDefault []bool
+	}{
+		{
+			name: "same sha on feature branch then main keeps main ref first",
+			commits: []CommitInfo{
+				{SHA: "a", Repo: "o/r", Date: day(1), Refs: []string{"o/r:feature"}},
+				{SHA: "a", Repo: "o/r", Date: day(2), Refs: []string{"o/r:main"}},
+			},
+			wantSHAs:    []string{"a"},
+			wantRefs:    [][]string{{"o/r:main", "o/r:feature"}},
+			wantDefault: []bool{true},
+		},
+		{
+			name: "same sha on main then feature branch keeps main",
+			commits: []CommitInfo{
+				{SHA: "a", Repo: "o/r", Date: day(2), Refs: []string{"o/r:main"}},
+				{SHA: "a", Repo: "o/r", Date: day(1), Refs: []string{"o/r:feature"}},
+			},
+			wantSHAs:    []string{"a"},
+			wantRefs:    [][]string{{"o/r:main", "o/r:feature"}},
+			wantDefault: []bool{true},
+		},
+		{
+			name: "same sha on two feature branches keeps the earliest",
+			commits: []CommitInfo{
+				{SHA: "a", Repo: "o/r", Date: day(3), Refs: []string{"o/r:b"}},
+				{SHA: "a", Repo: "o/r", Date: day(1), Refs: []string{"o/r:a"}},
+			},
+			wantSHAs:    []string{"a"},
+			wantRefs:    [][]string{{"o/r:a", "o/r:b"}},
+			wantDefault: []bool{false},
+		},
+		{
+			name: "rebased commit matched by patch id keeps the one on main",
+			commits: []CommitInfo{
+				{SHA: "old", Repo: "o/r", Date: day(1), PatchID: "p", Refs: []string{"o/r:feature"}},
+				{SHA: "new", Repo: "o/r", Date: day(2), PatchID: "p", Refs: []string{"o/r:main"}},
+			},
+			wantSHAs:    []string{"new"},
+			wantRefs:    [][]string{{"o/r:main", "o/r:feature"}},
+			wantDefault: []bool{true},
+		},
+		{
+			name: "patch id match followed by the original sha again",
+			commits: []CommitInfo{
+				{SHA: "old", Repo: "o/r", Date: day(1), PatchID: "p", Refs: []string{"o/r:feature"}},
+				{SHA: "new", Repo: "o/r", Date: day(2), PatchID: "p", Refs: []string{"o/r:release"}},
+				{SHA: "old", Repo: "o/r", Date: day(3), Refs: []string{"o/r:other"}},
+			},
+			wantSHAs:    []string{"old"},
+			wantRefs:    [][]string{{"o/r:feature", "o/r:release", "o/r:other"}},
+			wantDefault: []bool{false},
+		},
+		{
+			name: "different patches are kept apart",
+			commits: []CommitInfo{
+				{SHA: "a", Repo: "o/r", Date: day(1), PatchID: "p1", Refs: []string{"o/r:main"}},
+				{SHA: "b", Repo: "o/r", Date: day(2), PatchID: "p2", Refs: []string{"o/r:main"}},
+			},
+			wantSHAs:    []string{"a", "b"},
+			wantRefs:    [][]string{{"o/r:main"}, {"o/r:main"}},
+			wantDefault: []bool{true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dedupCommits(tt.commits, defaults)
+
+			var shas []string
+			var refs [][]string
+			var onDefault []bool
+			for _, c := range got {
+				shas = append(shas, c.SHA)
+				refs = append(refs, c.Refs)
+				onDefault = append(onDefault, onDefaultBranch(c, defaults))
+			}
+			if !reflect.DeepEqual(shas, tt.wantSHAs) {
+				t.Errorf("SHAs = %v, want %v", shas, tt.wantSHAs)
+			}
+			if !reflect.DeepEqual(refs, tt.wantRefs) {
+				t.Errorf("Refs = %v, want %v", refs, tt.wantRefs)
+			}
+			if !reflect.DeepEqual(onDefault, tt.wantDefault) {
+				t.Errorf("onDefaultBranch = %v, want %v", onDefault, tt.wantDefault)
+			}
+		})
+	}
+}
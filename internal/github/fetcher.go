@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v60/github"
@@ -11,11 +12,30 @@ import (
 // Fetcher 获取 GitHub 活动
 type Fetcher struct {
 	client *Client
+
+	commitCache     map[string]*github.RepositoryCommit // SHA -> commit 详情，同一 SHA 出现在多个分支时只请求一次
+	defaultBranches map[string]string                   // owner/repo -> 默认分支
+	commitPRs       map[string]*commitPR                // owner/repo@SHA -> 关联的 PR，nil 表示没有
+	listedPRs       map[string]bool                     // 已列出 commit 的 PR（owner/repo#number）
+}
+
+// commitPR 是 commit 所属 PR 中用于标注 commit 的字段
+type commitPR struct {
+	Number int
+	Title  string
+	URL    string
+	Merged bool
 }
 
 // NewFetcher 创建一个新的 Fetcher
 func NewFetcher(client *Client) *Fetcher {
-	return &Fetcher{client: client}
+	return &Fetcher{
+		client:          client,
+		commitCache:     make(map[string]*github.RepositoryCommit),
+		defaultBranches: make(map[string]string),
+		commitPRs:       make(map[string]*commitPR),
+		listedPRs:       make(map[string]bool),
+	}
 }
 
 // FetchActivities 获取指定时间范围内用户的所有活动
@@ -29,44 +49,68 @@ func (f *Fetcher) FetchActivities(ctx context.Context, username string, since, u
 	println("[Fetcher]", username, "- 正在拉取用户活动...")
 
 	// Fetch all events from user's timeline (single API call)
-	commits, prs, issues, reviews, err := f.fetchFromEvents(ctx, username, since, until)
+	commits, prs, issues, reviews, releases, truncated, err := f.fetchFromEvents(ctx, username, since, until)
 	if err != nil {
 		println("[Fetcher]", username, "- 拉取活动失败:", err.Error())
 		return nil, fmt.Errorf("failed to fetch activities: %w", err)
 	}
+	if truncated {
+		println("[Fetcher]", username, "- 事件时间线达到 Events API 的上限（", eventsLimit, "条 / 90 天），更早的活动缺失")
+		activity.Truncated = true
+	}
 
-	activity.Commits = commits
+	activity.Commits = dedupCommits(commits, f.defaultBranches)
+	f.attributeCommits(ctx, activity.Commits, prs)
 	activity.PullRequests = prs
 	activity.Issues = issues
 	activity.Reviews = reviews
+	activity.Releases = releases
+
+	f.linkClosingIssues(ctx, activity.PullRequests)
+	activity.LinkWorkItems()
 
-	println("[Fetcher]", username, "- 找到", len(commits), "个 Commits,", len(prs), "个 Pull Requests,", len(issues), "个 Issues,", len(reviews), "个 Code Reviews")
+	if removed := len(commits) - len(activity.Commits); removed > 0 {
+		println("[Fetcher]", username, "- 合并了", removed, "个重复的 Commits（跨分支 / rebase / fork）")
+	}
+
+	println("[Fetcher]", username, "- 找到", len(activity.Commits), "个 Commits,", len(prs), "个 Pull Requests,", len(issues), "个 Issues,", len(reviews), "个 Code Reviews,", len(releases), "个 Releases")
 
 	return activity, nil
 }
 
-// fetchFromEvents 一次性从用户事件时间线获取所有活动
-func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, until time.Time) ([]CommitInfo, []PullRequestInfo, []IssueInfo, []ReviewInfo, error) {
+// eventsLimit 是 Events API 最多返回的事件数
+const eventsLimit = 300
+
+// fetchFromEvents 一次性从用户事件时间线获取所有活动。
+// 时间线翻到底时最早的事件仍晚于 since，且达到了 Events API 的条数或时间上限，说明更早的事件被截断，返回 truncated
+func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, until time.Time) ([]CommitInfo, []PullRequestInfo, []IssueInfo, []ReviewInfo, []ReleaseInfo, bool, error) {
 	var commits []CommitInfo
 	var prs []PullRequestInfo
 	var issues []IssueInfo
 	var reviews []ReviewInfo
+	var releases []ReleaseInfo
 
 	opts := &github.ListOptions{PerPage: 100}
-	prMap := make(map[string]bool)   // Track PRs to avoid duplicates
+	prMap := make(map[string]bool)    // Track PRs to avoid duplicates
 	issueMap := make(map[string]bool) // Track Issues to avoid duplicates
 
+	total := 0
+	var oldest time.Time
 	for {
 		events, resp, err := f.client.client.Activity.ListEventsPerformedByUser(ctx, username, false, opts)
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, false, err
 		}
+		total += len(events)
 
 		for _, event := range events {
 			// Check if event is within time range
 			if event.CreatedAt == nil {
 				continue
 			}
+			if oldest.IsZero() || event.CreatedAt.Before(oldest) {
+				oldest = event.CreatedAt.Time
+			}
 			if event.CreatedAt.Before(since) || event.CreatedAt.After(until) {
 				continue
 			}
@@ -90,6 +134,11 @@ func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, u
 					repo = *event.Repo.Name
 				}
 
+				branch := branchFromRef(getStringValue(pushPayload.Ref))
+				if repo != "" {
+					f.loadDefaultBranch(ctx, repo)
+				}
+
 				for _, commit := range pushPayload.Commits {
 					if commit.SHA == nil || commit.Message == nil {
 						continue
@@ -101,7 +150,7 @@ func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, u
 					if repo != "" {
 						owner, repoName := parseRepoName(repo)
 						if owner != "" && repoName != "" {
-							c, _, err := f.client.client.Repositories.GetCommit(ctx, owner, repoName, *commit.SHA, nil)
+							c, err := f.getCommit(ctx, owner, repoName, *commit.SHA)
 							if err == nil {
 								// Verify the commit author matches the target user
 								isAuthor := false
@@ -143,6 +192,9 @@ func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, u
 									Date:      event.CreatedAt.Time,
 									Additions: additions,
 									Deletions: deletions,
+									Refs:      []string{repo + ":" + branch},
+									PatchID:   patchID(c.Files),
+									Files:     fileChanges(c.Files),
 								})
 							}
 						}
@@ -190,11 +242,19 @@ func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, u
 					Comments:  getIntValue(pr.Comments),
 					Additions: getIntValue(pr.Additions),
 					Deletions: getIntValue(pr.Deletions),
+
+					HeadSHA:        pr.GetHead().GetSHA(),
+					MergeCommitSHA: pr.GetMergeCommitSHA(),
+
+					ClosingIssues: parseClosingRefs(f.client.Host(), repo, getStringValue(pr.Body)),
 				}
 
 				if pr.MergedAt != nil {
 					prInfo.MergedAt = getTimePointer(pr.MergedAt)
 				}
+				if pr.Milestone != nil {
+					prInfo.Milestone = getStringValue(pr.Milestone.Title)
+				}
 
 				prs = append(prs, prInfo)
 			}
@@ -247,6 +307,9 @@ func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, u
 				if issue.ClosedAt != nil {
 					issueInfo.ClosedAt = getTimePointer(issue.ClosedAt)
 				}
+				if issue.Milestone != nil {
+					issueInfo.Milestone = getStringValue(issue.Milestone.Title)
+				}
 
 				issues = append(issues, issueInfo)
 			}
@@ -294,6 +357,34 @@ func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, u
 
 				reviews = append(reviews, review)
 			}
+
+			// Process ReleaseEvent
+			if eventType == "ReleaseEvent" {
+				payload, err := event.ParsePayload()
+				if err != nil {
+					continue
+				}
+
+				releasePayload, ok := payload.(*github.ReleaseEvent)
+				if !ok || releasePayload.Release == nil || releasePayload.GetAction() != "published" {
+					continue
+				}
+
+				release := releasePayload.Release
+				repo := ""
+				if event.Repo != nil && event.Repo.Name != nil {
+					repo = *event.Repo.Name
+				}
+
+				releases = append(releases, ReleaseInfo{
+					Repo:        repo,
+					Tag:         release.GetTagName(),
+					Name:        release.GetName(),
+					URL:         release.GetHTMLURL(),
+					Prerelease:  release.GetPrerelease(),
+					PublishedAt: event.CreatedAt.Time,
+				})
+			}
 		}
 
 		if resp.NextPage == 0 {
@@ -302,7 +393,184 @@ func (f *Fetcher) fetchFromEvents(ctx context.Context, username string, since, u
 		opts.Page = resp.NextPage
 	}
 
-	return commits, prs, issues, reviews, nil
+	truncated := !oldest.IsZero() && oldest.After(since) && (total >= eventsLimit || time.Since(since) > EventsRetention)
+	return commits, prs, issues, reviews, releases, truncated, nil
+}
+
+// getCommit 获取 commit 详情，按 SHA 缓存
+func (f *Fetcher) getCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, error) {
+	if c, ok := f.commitCache[sha]; ok {
+		return c, nil
+	}
+
+	c, _, err := f.client.client.Repositories.GetCommit(ctx, owner, repo, sha, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	f.commitCache[sha] = c
+	return c, nil
+}
+
+// loadDefaultBranch 查询并缓存仓库的默认分支，失败时不记录（去重时视为未知）
+func (f *Fetcher) loadDefaultBranch(ctx context.Context, fullName string) {
+	if _, ok := f.defaultBranches[fullName]; ok {
+		return
+	}
+
+	owner, repoName := parseRepoName(fullName)
+	if owner == "" || repoName == "" {
+		return
+	}
+
+	r, _, err := f.client.client.Repositories.Get(ctx, owner, repoName)
+	if err != nil {
+		println("[Fetcher] 获取仓库默认分支失败:", fullName, err.Error())
+		f.defaultBranches[fullName] = ""
+		return
+	}
+
+	f.defaultBranches[fullName] = getStringValue(r.DefaultBranch)
+}
+
+// attributeCommits 为去重后的 commit 填充分支信息和关联的 PR。
+// 先用本次已拉取到的 PR（head / merge commit SHA，以及每个 PR 的 commit 列表）匹配，
+// 仍未匹配的 commit 才逐个调用 commits-pulls API
+func (f *Fetcher) attributeCommits(ctx context.Context, commits []CommitInfo, prs []PullRequestInfo) {
+	f.indexKnownPRs(ctx, commits, prs)
+
+	for i := range commits {
+		c := &commits[i]
+
+		if len(c.Refs) > 0 {
+			if _, branch, ok := strings.Cut(c.Refs[0], ":"); ok {
+				c.Branch = branch
+			}
+		}
+		c.IsDefaultBranch = c.Branch != "" && c.Branch == f.defaultBranches[c.Repo]
+
+		pr := f.findCommitPR(ctx, c.Repo, c.SHA)
+		if pr == nil {
+			continue
+		}
+
+		c.PRNumber = pr.Number
+		c.PRTitle = pr.Title
+		c.PRURL = pr.URL
+		c.PRMerged = pr.Merged
+	}
+}
+
+// indexKnownPRs 把已知 PR 包含的 commit 写入缓存：head 和 merge commit 直接记录，
+// 其余 commit 通过每个 PR 一次的 commit 列表获取；只处理仍有未匹配 commit 的仓库中的 PR
+func (f *Fetcher) indexKnownPRs(ctx context.Context, commits []CommitInfo, prs []PullRequestInfo) {
+	for _, pr := range prs {
+		info := &commitPR{Number: pr.Number, Title: pr.Title, URL: pr.URL, Merged: pr.MergedAt != nil}
+		for _, sha := range []string{pr.HeadSHA, pr.MergeCommitSHA} {
+			if sha != "" {
+				f.cacheCommitPR(pr.Repo, sha, info)
+			}
+		}
+	}
+
+	unmatched := make(map[string]bool)
+	for _, c := range commits {
+		if _, ok := f.commitPRs[commitKey(c.Repo, c.SHA)]; !ok {
+			unmatched[c.Repo] = true
+		}
+	}
+
+	for _, pr := range prs {
+		key := fmt.Sprintf("%s#%d", pr.Repo, pr.Number)
+		if !unmatched[pr.Repo] || f.listedPRs[key] {
+			continue
+		}
+		f.listedPRs[key] = true
+
+		owner, repoName := parseRepoName(pr.Repo)
+		if owner == "" || repoName == "" {
+			continue
+		}
+
+		info := &commitPR{Number: pr.Number, Title: pr.Title, URL: pr.URL, Merged: pr.MergedAt != nil}
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			list, resp, err := f.client.client.PullRequests.ListCommits(ctx, owner, repoName, pr.Number, opts)
+			if err != nil {
+				println("[Fetcher] 获取 PR 的 commit 列表失败:", key, err.Error())
+				break
+			}
+			for _, c := range list {
+				f.cacheCommitPR(pr.Repo, c.GetSHA(), info)
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+}
+
+// cacheCommitPR 记录 commit 所属的 PR，同一 commit 属于多个 PR 时优先已合并的
+func (f *Fetcher) cacheCommitPR(repo, sha string, pr *commitPR) {
+	key := commitKey(repo, sha)
+	if existing := f.commitPRs[key]; existing != nil && (existing.Merged || !pr.Merged) {
+		return
+	}
+	f.commitPRs[key] = pr
+}
+
+// commitKey 返回 commitPRs 的键，不同仓库（如 fork）中的同一 SHA 分开缓存
+func commitKey(repo, sha string) string {
+	return strings.ToLower(repo) + "@" + sha
+}
+
+// linkClosingIssues 通过 GraphQL 补充 PR 关闭的 issue；失败时保留从描述中解析出的结果
+func (f *Fetcher) linkClosingIssues(ctx context.Context, prs []PullRequestInfo) {
+	for i := range prs {
+		pr := &prs[i]
+		refs, err := f.client.ClosingIssues(ctx, pr.Repo, pr.Number)
+		if err != nil {
+			println("[Fetcher] 查询 PR 关闭的 issue 失败:", pr.Repo, pr.Number, err.Error())
+			continue
+		}
+		pr.ClosingIssues = mergeIssueRefs(pr.ClosingIssues, refs)
+	}
+}
+
+// findCommitPR 查找包含该 commit 的 PR，优先返回已合并的。
+// 先查缓存，未命中时调用 commits-pulls API，结果（包括没有 PR）同样缓存
+func (f *Fetcher) findCommitPR(ctx context.Context, fullName, sha string) *commitPR {
+	key := commitKey(fullName, sha)
+	if pr, ok := f.commitPRs[key]; ok {
+		return pr
+	}
+
+	owner, repoName := parseRepoName(fullName)
+	if owner == "" || repoName == "" {
+		return nil
+	}
+
+	prs, _, err := f.client.client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repoName, sha, nil)
+	if err != nil {
+		println("[Fetcher] 查询 commit 关联的 PR 失败:", sha[:7], err.Error())
+		return nil
+	}
+
+	var found *commitPR
+	for _, pr := range prs {
+		if found == nil || (!found.Merged && pr.MergedAt != nil) {
+			found = &commitPR{
+				Number: pr.GetNumber(),
+				Title:  pr.GetTitle(),
+				URL:    pr.GetHTMLURL(),
+				Merged: pr.MergedAt != nil,
+			}
+		}
+	}
+
+	f.commitPRs[key] = found
+	return found
 }
 
 // Helper functions
@@ -315,6 +583,11 @@ func parseRepoName(fullName string) (owner, repo string) {
 	return "", ""
 }
 
+// branchFromRef 将 refs/heads/xxx 转为分支名，其他 ref（如 tag）保持原样
+func branchFromRef(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
 func getStringValue(s *string) string {
 	if s == nil {
 		return ""
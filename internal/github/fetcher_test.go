@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github-reports/internal/github/githubtest"
+)
+
+const (
+	shaExport = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	shaOther  = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+// newReplayFetcher 启动回放 testdata/events.json 的假服务器，返回连接到它的 Fetcher
+func newReplayFetcher(t *testing.T) (*Fetcher, *githubtest.Server) {
+	t.Helper()
+
+	fixtures, err := githubtest.LoadFixtures("githubtest/testdata/events.json")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	srv := githubtest.NewServer(fixtures)
+	t.Cleanup(srv.Close)
+
+	return NewFetcher(NewClient("test-token", WithBaseURL(srv.BaseURL()))), srv
+}
+
+func replayRange() (time.Time, time.Time) {
+	return time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFetchFromEvents(t *testing.T) {
+	f, srv := newReplayFetcher(t)
+	since, until := replayRange()
+
+	commits, prs, issues, reviews, _, _, err := f.fetchFromEvents(context.Background(), "octocat", since, until)
+	if err != nil {
+		t.Fatalf("fetchFromEvents: %v", err)
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		var pages int
+		for _, req := range srv.Requests() {
+			if strings.HasPrefix(req, "GET /users/octocat/events") {
+				pages++
+			}
+		}
+		if pages != 2 {
+			t.Errorf("requested %d event pages, want 2", pages)
+		}
+		// 第二页的 issue #3 只有翻页后才能拿到
+		if len(issues) != 1 || issues[0].Number != 3 {
+			t.Errorf("issues = %+v, want only #3 from page 2", issues)
+		}
+	})
+
+	t.Run("commits by other authors in a push are skipped", func(t *testing.T) {
+		for _, c := range commits {
+			if c.SHA == shaOther {
+				t.Errorf("commit %s by another author was kept", shaOther[:7])
+			}
+		}
+		// 同一 commit 先推到 feature-x、再推到 main，去重前各出现一次
+		if len(commits) != 2 {
+			t.Errorf("got %d commits before dedup, want 2", len(commits))
+		}
+	})
+
+	t.Run("duplicate pull request events", func(t *testing.T) {
+		if len(prs) != 1 {
+			t.Fatalf("got %d pull requests, want 1", len(prs))
+		}
+		// 事件按时间倒序返回，保留的是最新的 closed 事件
+		if prs[0].MergedAt == nil || prs[0].Additions != 120 {
+			t.Errorf("pull request = %+v, want the merged state from the latest event", prs[0])
+		}
+	})
+
+	t.Run("issues that are pull requests", func(t *testing.T) {
+		for _, issue := range issues {
+			if issue.Number == 7 {
+				t.Errorf("pull request #7 was reported as an issue")
+			}
+		}
+	})
+
+	if len(reviews) != 1 || reviews[0].PRNumber != 9 || reviews[0].Repo != "octo/lib" {
+		t.Errorf("reviews = %+v, want one review of octo/lib#9", reviews)
+	}
+}
+
+func TestFetchActivities(t *testing.T) {
+	f, srv := newReplayFetcher(t)
+	since, until := replayRange()
+
+	activity, err := f.FetchActivities(context.Background(), "octocat", since, until)
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+
+	if len(activity.Commits) != 1 {
+		t.Fatalf("got %d commits after dedup, want 1", len(activity.Commits))
+	}
+	c := activity.Commits[0]
+	if c.Branch != "main" || !c.IsDefaultBranch {
+		t.Errorf("commit branch = %q (default %v), want main", c.Branch, c.IsDefaultBranch)
+	}
+	if c.PRNumber != 7 || !c.PRMerged {
+		t.Errorf("commit PR = #%d (merged %v), want merged #7", c.PRNumber, c.PRMerged)
+	}
+	if len(activity.PullRequests) != 1 || len(activity.PullRequests[0].ClosingIssues) == 0 {
+		t.Errorf("pull requests = %+v, want #7 closing #3", activity.PullRequests)
+	}
+
+	// commit 已通过 PR #7 的 commit 列表匹配，不应再逐个查询 commits-pulls
+	for _, req := range srv.Requests() {
+		if strings.HasSuffix(req, "/commits/"+shaExport+"/pulls") {
+			t.Errorf("unexpected per-commit PR lookup: %s", req)
+		}
+	}
+	if unmatched := srv.Unmatched(); len(unmatched) > 0 {
+		t.Errorf("requests without fixtures: %v", unmatched)
+	}
+}
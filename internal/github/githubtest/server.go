@@ -0,0 +1,303 @@
+// Package githubtest 提供基于 httptest 的假 GitHub REST 服务器。
+//
+// 回放模式下按 fixture 文件应答请求，用于离线、确定性地运行 Fetcher；
+// 录制模式下把请求转发给真实 API，并把响应保存为 fixture 文件。
+package githubtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BaseURLPlaceholder 出现在 fixture 的响应头和响应体中，回放时替换为假服务器地址，
+// 使分页 Link 头等绝对地址指向假服务器
+const BaseURLPlaceholder = "{{base_url}}"
+
+// Fixture 是一条录制好的请求与响应
+type Fixture struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Query 为空时匹配任意查询参数；否则参数需完全一致（与顺序无关）
+	Query string `json:"query,omitempty"`
+	// BodyContains 可选，要求请求体包含该字符串，用于区分不同的 GraphQL 查询
+	BodyContains string `json:"body_contains,omitempty"`
+
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// Server 是假 GitHub 服务器
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	fixtures  []Fixture
+	requests  []string
+	unmatched []string
+
+	// 录制模式
+	upstream  *url.URL
+	token     string
+	transport http.RoundTripper
+}
+
+// NewServer 创建一个按 fixture 应答的回放服务器
+func NewServer(fixtures []Fixture) *Server {
+	s := &Server{fixtures: fixtures}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.replay))
+	return s
+}
+
+// NewRecordingServer 创建一个录制服务器：请求会带上 token 转发到 upstream
+// （通常为 https://api.github.com/），响应被记录下来，调用 Save 写入 fixture 文件
+func NewRecordingServer(upstream *url.URL, token string) *Server {
+	s := &Server{
+		upstream:  upstream,
+		token:     token,
+		transport: http.DefaultTransport,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.record))
+	return s
+}
+
+// BaseURL 返回可直接传给 github.WithBaseURL 的地址
+func (s *Server) BaseURL() *url.URL {
+	u, _ := url.Parse(s.URL + "/")
+	return u
+}
+
+// Requests 返回已处理的请求，格式为 "METHOD /path?query"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Unmatched 返回回放模式下没有 fixture 可匹配的请求
+func (s *Server) Unmatched() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.unmatched...)
+}
+
+// Fixtures 返回当前的 fixture（录制模式下为已录制的内容）
+func (s *Server) Fixtures() []Fixture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Fixture(nil), s.fixtures...)
+}
+
+// Save 将 fixture 写入 JSON 文件
+func (s *Server) Save(path string) error {
+	data, err := json.MarshalIndent(s.Fixtures(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixtures: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadFixtures 读取一个 fixture 文件；path 为目录时按文件名顺序读取其中所有 .json 文件
+func LoadFixtures(path string) ([]Fixture, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	var fixtures []Fixture
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []Fixture
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		fixtures = append(fixtures, batch...)
+	}
+
+	return fixtures, nil
+}
+
+func (s *Server) replay(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	key := requestKey(r)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, key)
+	fixture, ok := s.match(r, body)
+	if !ok {
+		s.unmatched = append(s.unmatched, key)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"message":"Not Found","documentation_url":"githubtest: no fixture for %s"}`, key)
+		return
+	}
+
+	s.writeFixture(w, fixture)
+}
+
+// match 返回第一个匹配的 fixture
+func (s *Server) match(r *http.Request, body []byte) (Fixture, bool) {
+	for _, f := range s.fixtures {
+		if !strings.EqualFold(f.Method, r.Method) || f.Path != r.URL.Path {
+			continue
+		}
+		if f.Query != "" && normalizeQuery(f.Query) != normalizeQuery(r.URL.RawQuery) {
+			continue
+		}
+		if f.BodyContains != "" && !bytes.Contains(body, []byte(f.BodyContains)) {
+			continue
+		}
+		return f, true
+	}
+	return Fixture{}, false
+}
+
+func (s *Server) writeFixture(w http.ResponseWriter, f Fixture) {
+	// 默认提供宽裕的速率限制头，fixture 中可覆盖以模拟限流
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", "4999")
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	for k, v := range f.Headers {
+		w.Header().Set(k, strings.ReplaceAll(v, BaseURLPlaceholder, s.URL))
+	}
+
+	status := f.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+
+	w.Write(bytes.ReplaceAll(f.Body, []byte(BaseURLPlaceholder), []byte(s.URL)))
+}
+
+// recordedHeaders 是录制时保留的响应头
+var recordedHeaders = []string{"Link", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"}
+
+func (s *Server) record(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	target := s.upstream.ResolveReference(&url.URL{Path: strings.TrimPrefix(r.URL.Path, "/"), RawQuery: r.URL.RawQuery})
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	req.Header.Set("Accept", r.Header.Get("Accept"))
+	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.transport.RoundTrip(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	// 把真实 API 地址替换为占位符，回放时分页链接会指向假服务器
+	upstreamBase := strings.TrimSuffix(s.upstream.String(), "/")
+	fixture := Fixture{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   r.URL.RawQuery,
+		Status:  resp.StatusCode,
+		Headers: make(map[string]string),
+		Body:    json.RawMessage(bytes.ReplaceAll(respBody, []byte(upstreamBase), []byte(BaseURLPlaceholder))),
+	}
+	if !json.Valid(fixture.Body) {
+		fixture.Body, _ = json.Marshal(string(respBody))
+	}
+	if strings.HasSuffix(r.URL.Path, "/graphql") {
+		fixture.BodyContains = graphQLOperation(body)
+	}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			fixture.Headers[h] = strings.ReplaceAll(v, upstreamBase, BaseURLPlaceholder)
+		}
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, requestKey(r))
+	s.fixtures = append(s.fixtures, fixture)
+	s.mu.Unlock()
+
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			w.Header().Set(h, strings.ReplaceAll(v, upstreamBase, s.URL))
+		}
+	}
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	w.Write(bytes.ReplaceAll(respBody, []byte(upstreamBase), []byte(s.URL)))
+}
+
+// graphQLOperation 取 GraphQL 查询的第一行作为匹配条件，避免把整段查询写进 fixture
+func graphQLOperation(body []byte) string {
+	var req struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(req.Query), "\n")
+	encoded, _ := json.Marshal(strings.TrimSpace(line))
+	// 请求体中的查询是 JSON 转义后的字符串，去掉引号后按子串匹配
+	return strings.Trim(string(encoded), `"`)
+}
+
+func requestKey(r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return r.Method + " " + r.URL.Path
+	}
+	return r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery
+}
+
+func normalizeQuery(q string) string {
+	values, err := url.ParseQuery(q)
+	if err != nil {
+		return q
+	}
+	return values.Encode()
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// graphQL 通过与 REST 相同的认证客户端调用 GitHub GraphQL API，结果解析到 out 的 data 字段
+func (c *Client) graphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	req, err := c.client.NewRequest("POST", c.graphQLURL().String(), graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("failed to create graphql request: %w", err)
+	}
+
+	resp := struct {
+		Data   interface{}    `json:"data"`
+		Errors []graphQLError `json:"errors"`
+	}{Data: out}
+
+	if _, err := c.client.Do(ctx, req, &resp); err != nil {
+		return fmt.Errorf("graphql request failed: %w", err)
+	}
+
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("graphql error: %s", resp.Errors[0].Message)
+	}
+
+	return nil
+}
+
+// graphQLURL 返回 GraphQL 端点：github.com 为 https://api.github.com/graphql，
+// GitHub Enterprise 的 REST 根地址为 /api/v3/，对应的 GraphQL 端点是 /api/graphql
+func (c *Client) graphQLURL() *url.URL {
+	u := *c.client.BaseURL
+	if strings.HasSuffix(u.Path, "/api/v3/") {
+		u.Path = strings.TrimSuffix(u.Path, "v3/") + "graphql"
+		return &u
+	}
+	return u.ResolveReference(&url.URL{Path: "graphql"})
+}
+
+const closingIssuesQuery = `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      closingIssuesReferences(first: 25) {
+        nodes {
+          number
+          title
+          url
+          state
+          repository { nameWithOwner }
+        }
+      }
+    }
+  }
+}`
+
+// ClosingIssues 返回 PR 合并后会关闭（或已关闭）的 issue
+func (c *Client) ClosingIssues(ctx context.Context, fullName string, number int) ([]IssueRef, error) {
+	owner, name := parseRepoName(fullName)
+	if owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repository name: %s", fullName)
+	}
+
+	var data struct {
+		Repository struct {
+			PullRequest struct {
+				ClosingIssuesReferences struct {
+					Nodes []struct {
+						Number     int    `json:"number"`
+						Title      string `json:"title"`
+						URL        string `json:"url"`
+						State      string `json:"state"`
+						Repository struct {
+							NameWithOwner string `json:"nameWithOwner"`
+						} `json:"repository"`
+					} `json:"nodes"`
+				} `json:"closingIssuesReferences"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}
+
+	vars := map[string]interface{}{"owner": owner, "name": name, "number": number}
+	if err := c.graphQL(ctx, closingIssuesQuery, vars, &data); err != nil {
+		return nil, err
+	}
+
+	var refs []IssueRef
+	for _, n := range data.Repository.PullRequest.ClosingIssuesReferences.Nodes {
+		refs = append(refs, IssueRef{
+			Repo:   n.Repository.NameWithOwner,
+			Number: n.Number,
+			Title:  n.Title,
+			URL:    n.URL,
+			State:  strings.ToLower(n.State),
+		})
+	}
+
+	return refs, nil
+}
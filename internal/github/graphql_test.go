@@ -0,0 +1,54 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github-reports/internal/github/githubtest"
+)
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"https://api.github.com/", "https://api.github.com/graphql"},
+		{"https://ghe.example.com/api/v3/", "https://ghe.example.com/api/graphql"},
+		{"http://127.0.0.1:8080/", "http://127.0.0.1:8080/graphql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base, func(t *testing.T) {
+			base, err := url.Parse(tt.base)
+			if err != nil {
+				t.Fatalf("parse %s: %v", tt.base, err)
+			}
+			c := NewClient("test-token", WithBaseURL(base))
+			if got := c.graphQLURL().String(); got != tt.want {
+				t.Errorf("graphQLURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClosingIssuesEnterprise 验证 REST 根地址为 /api/v3/ 时 GraphQL 请求发往 /api/graphql
+func TestClosingIssuesEnterprise(t *testing.T) {
+	fixtures, err := githubtest.LoadFixtures("githubtest/testdata/enterprise.json")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	srv := githubtest.NewServer(fixtures)
+	t.Cleanup(srv.Close)
+
+	base, _ := url.Parse(srv.URL + "/api/v3/")
+	c := NewClient("test-token", WithBaseURL(base))
+
+	refs, err := c.ClosingIssues(context.Background(), "corp/portal", 5)
+	if err != nil {
+		t.Fatalf("ClosingIssues: %v (unmatched %v)", err, srv.Unmatched())
+	}
+	if len(refs) != 1 || refs[0].Key() != "corp/portal#12" || refs[0].State != "open" {
+		t.Errorf("refs = %+v, want open corp/portal#12", refs)
+	}
+}
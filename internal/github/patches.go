@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// FilePatch 是一个文件的 diff
+type FilePatch struct {
+	Path      string
+	Additions int
+	Deletions int
+	Patch     string // unified diff 片段，二进制文件或过大的文件为空
+}
+
+// CommitPatch 获取 commit 中每个文件的 diff
+func (c *Client) CommitPatch(ctx context.Context, repo, sha string) ([]FilePatch, error) {
+	owner, name := parseRepoName(repo)
+	if owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repository name: %s", repo)
+	}
+
+	commit, _, err := c.client.Repositories.GetCommit(ctx, owner, name, sha, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s@%s: %w", repo, sha, err)
+	}
+	return filePatches(commit.Files), nil
+}
+
+// PullRequestPatch 获取 PR 中每个文件的 diff，最多取第一页的 100 个文件
+func (c *Client) PullRequestPatch(ctx context.Context, repo string, number int) ([]FilePatch, error) {
+	owner, name := parseRepoName(repo)
+	if owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repository name: %s", repo)
+	}
+
+	files, _, err := c.client.PullRequests.ListFiles(ctx, owner, name, number, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s#%d: %w", repo, number, err)
+	}
+	return filePatches(files), nil
+}
+
+func filePatches(files []*github.CommitFile) []FilePatch {
+	result := make([]FilePatch, 0, len(files))
+	for _, f := range files {
+		result = append(result, FilePatch{
+			Path:      f.GetFilename(),
+			Additions: f.GetAdditions(),
+			Deletions: f.GetDeletions(),
+			Patch:     f.GetPatch(),
+		})
+	}
+	return result
+}
+
+// lockFiles 是依赖锁文件，内容由工具生成，对理解改动没有帮助
+var lockFiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"bun.lockb":           true,
+	"go.sum":              true,
+	"cargo.lock":          true,
+	"poetry.lock":         true,
+	"pipfile.lock":        true,
+	"uv.lock":             true,
+	"composer.lock":       true,
+	"gemfile.lock":        true,
+	"mix.lock":            true,
+	"pubspec.lock":        true,
+	"podfile.lock":        true,
+	"packages.lock.json":  true,
+	"flake.lock":          true,
+}
+
+// generatedSuffixes 是生成文件和构建产物的后缀
+var generatedSuffixes = []string{
+	".pb.go", ".pb.gw.go", "_generated.go", ".gen.go", "_gen.go", "_string.go",
+	"_pb2.py", "_pb2_grpc.py",
+	".min.js", ".min.css", ".map", ".snap",
+	".svg", ".png", ".jpg", ".jpeg", ".gif", ".ico", ".pdf", ".woff", ".woff2", ".ttf",
+}
+
+// generatedDirs 是存放第三方代码和生成代码的目录，其中的文件都算噪音
+var generatedDirs = map[string]bool{
+	"vendor":        true,
+	"node_modules":  true,
+	"third_party":   true,
+	"__snapshots__": true,
+	"generated":     true,
+}
+
+// outputDirs 是常见的构建输出目录。同名目录也常用来放构建脚本和源码（如 Go 的 internal/build、
+// 打包用的 build/Dockerfile），因此只有 outputExts 中的打包产物才算噪音
+var outputDirs = map[string]bool{
+	"dist":  true,
+	"build": true,
+}
+
+// outputExts 是打包、编译产物的扩展名
+var outputExts = map[string]bool{
+	".js": true, ".mjs": true, ".cjs": true, ".css": true, ".html": true,
+	".class": true, ".jar": true, ".war": true, ".o": true, ".a": true, ".so": true, ".dylib": true, ".dll": true, ".exe": true,
+	".wasm": true, ".whl": true, ".egg": true, ".gz": true, ".tgz": true, ".zip": true,
+}
+
+// IsNoiseFile 判断文件的 diff 是否对描述改动没有帮助：锁文件、生成代码、构建产物和二进制资源
+func IsNoiseFile(file string) bool {
+	lower := strings.ToLower(file)
+	base := path.Base(lower)
+	if lockFiles[base] || strings.HasPrefix(base, "zz_generated") {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	output := outputExts[path.Ext(base)]
+	for _, dir := range strings.Split(path.Dir(lower), "/") {
+		if generatedDirs[dir] || (output && outputDirs[dir]) {
+			return true
+		}
+	}
+	return false
+}
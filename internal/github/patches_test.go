@@ -0,0 +1,39 @@
+package github
+
+import "testing"
+
+func TestIsNoiseFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"go.sum", true},
+		{"web/package-lock.json", true},
+		{"Cargo.lock", true},
+		{"api/v1/service.pb.go", true},
+		{"pkg/apis/zz_generated.deepcopy.go", true},
+		{"static/app.min.js", true},
+		{"docs/logo.svg", true},
+		{"vendor/github.com/pkg/errors/errors.go", true},
+		{"web/node_modules/react/index.js", true},
+		{"src/__snapshots__/view.test.js.snap", true},
+		{"dist/bundle.js", true},
+		{"packages/ui/dist/index.css", true},
+		{"build/libs/app.jar", true},
+		{"build/package/Dockerfile", false},
+		{"build/ci/release.yaml", false},
+		{"internal/build/build.go", false},
+		{"scripts/dist/publish.sh", false},
+		{"internal/github/patches.go", false},
+		{"README.md", false},
+		{"src/builder/index.js", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := IsNoiseFile(tt.file); got != tt.want {
+				t.Errorf("IsNoiseFile(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
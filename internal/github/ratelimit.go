@@ -0,0 +1,88 @@
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithRateLimitWait 在触发 GitHub 主限流或次级限流时等待重置后重试，而不是直接返回错误。
+// 单次等待超过 maxWait 时放弃并返回原始响应。适合批量回填这类可以慢慢跑的任务，
+// 不适合需要及时响应的 HTTP 请求。
+func WithRateLimitWait(maxWait time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.rateLimitWait = maxWait
+	}
+}
+
+// rateLimitTransport 识别 403 / 429 限流响应，按 Retry-After 或 X-RateLimit-Reset 等待后重试
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	maxWait time.Duration
+	now     func() time.Time
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, limited := t.retryAfter(resp)
+		if !limited || wait > t.maxWait {
+			return resp, nil
+		}
+
+		// 请求体只能读一次，重试前需要重新获取
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req.Body = body
+		}
+		resp.Body.Close()
+
+		println("[GitHub] 触发限流，等待", wait.Round(time.Second).String(), "后重试:", req.URL.Path)
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter 判断响应是否为限流，并计算需要等待的时间
+func (t *rateLimitTransport) retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	// 次级限流
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	// 主限流：额度用完，等到重置时间（多等一秒避免时钟误差）
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		wait := time.Unix(reset, 0).Sub(t.now()) + time.Second
+		if wait < time.Second {
+			wait = time.Second
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tr := &rateLimitTransport{now: func() time.Time { return now }}
+	reset := func(d time.Duration) string {
+		return strconv.FormatInt(now.Add(d).Unix(), 10)
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		headers     map[string]string
+		wantWait    time.Duration
+		wantLimited bool
+	}{
+		{"success", http.StatusOK, map[string]string{"Retry-After": "30"}, 0, false},
+		{"secondary limit", http.StatusForbidden, map[string]string{"Retry-After": "30"}, 30 * time.Second, true},
+		{"too many requests", http.StatusTooManyRequests, map[string]string{"Retry-After": "5"}, 5 * time.Second, true},
+		{"primary limit waits until reset", http.StatusForbidden,
+			map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(10 * time.Minute)}, 10*time.Minute + time.Second, true},
+		{"reset already passed", http.StatusForbidden,
+			map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(-time.Minute)}, time.Second, true},
+		{"malformed reset", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "soon"}, 0, false},
+		{"permission denied", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "4000"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: make(http.Header)}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+			wait, limited := tr.retryAfter(resp)
+			if wait != tt.wantWait || limited != tt.wantLimited {
+				t.Errorf("retryAfter = %s, %v, want %s, %v", wait, limited, tt.wantWait, tt.wantLimited)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// RepoInfoTTL 是仓库元数据的缓存有效期，描述和 README 很少变化
+const RepoInfoTTL = 7 * 24 * time.Hour
+
+// RepoInfoMissingTTL 是「仓库不存在或无权访问」的缓存有效期，权限可能随时被授予，因此比 RepoInfoTTL 短得多
+const RepoInfoMissingTTL = 6 * time.Hour
+
+// readmeSummaryLimit 是 README 首段保留的最大字符数
+const readmeSummaryLimit = 300
+
+// RepoInfo 是仓库的元数据，用于让报告说明每个项目是做什么的
+type RepoInfo struct {
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description,omitempty"`
+	Topics      []string  `json:"topics,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	Stars       int       `json:"stars"`
+	Readme      string    `json:"readme,omitempty"` // README 的第一段正文
+	Visibility  string    `json:"visibility"`       // public、private 或 internal；早期缓存的条目没有此字段
+	Fork        bool      `json:"fork,omitempty"`
+	Archived    bool      `json:"archived,omitempty"`
+	Missing     bool      `json:"missing,omitempty"` // GitHub 上不存在或无权访问，缓存下来避免重复请求
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// RepoInfo 获取仓库的描述、topics、主要语言、star 数和 README 首段。
+// 仓库不存在或无权访问（404，或不是限流导致的 403）时返回 Missing 为 true 的结果而不是错误。
+func (c *Client) RepoInfo(ctx context.Context, fullName string) (*RepoInfo, error) {
+	owner, name := parseRepoName(fullName)
+	if owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repository name: %s", fullName)
+	}
+
+	info := &RepoInfo{FullName: fullName, FetchedAt: time.Now()}
+	repo, resp, err := c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || (resp.StatusCode == http.StatusForbidden && !isRateLimit(err))) {
+			info.Missing = true
+			return info, nil
+		}
+		return nil, fmt.Errorf("failed to get repository %s: %w", fullName, err)
+	}
+
+	info.FullName = repo.GetFullName()
+	info.Description = repo.GetDescription()
+	info.Topics = repo.Topics
+	info.Language = repo.GetLanguage()
+	info.Stars = repo.GetStargazersCount()
+	info.Visibility = repo.GetVisibility()
+	if info.Visibility == "" {
+		// 较老的 GitHub Enterprise 不返回 visibility
+		info.Visibility = "public"
+		if repo.GetPrivate() {
+			info.Visibility = "private"
+		}
+	}
+	info.Fork = repo.GetFork()
+	info.Archived = repo.GetArchived()
+
+	readme, _, err := c.client.Repositories.GetReadme(ctx, owner, name, nil)
+	if err != nil {
+		// 没有 README 不影响其他元数据
+		var errResp *github.ErrorResponse
+		if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusNotFound {
+			println("[GitHub] 获取", fullName, "的 README 失败:", err.Error())
+		}
+		return info, nil
+	}
+	if content, err := readme.GetContent(); err == nil {
+		info.Readme = readmeSummary(content)
+	}
+
+	return info, nil
+}
+
+// isRateLimit 判断错误是否来自主限流或次级限流，这类 403 不代表无权访问
+func isRateLimit(err error) bool {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	return errors.As(err, &rateErr) || errors.As(err, &abuseErr)
+}
+
+var (
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	htmlTag       = regexp.MustCompile(`<[^>]+>`)
+)
+
+// readmeSummary 提取 README 的第一段正文：跳过标题、徽章、图片、HTML 和代码块，去掉链接语法
+func readmeSummary(content string) string {
+	var paragraph []string
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		line = markdownImage.ReplaceAllString(line, "")
+		line = htmlTag.ReplaceAllString(line, "")
+		line = markdownLink.ReplaceAllString(line, "$1")
+		line = strings.TrimSpace(line)
+
+		isText := line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "|") &&
+			!strings.HasPrefix(line, "---") && !strings.HasPrefix(line, "===")
+		if !isText {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, strings.TrimLeft(line, "> "))
+	}
+
+	summary := strings.Join(paragraph, " ")
+	if runes := []rune(summary); len(runes) > readmeSummaryLimit {
+		summary = string(runes[:readmeSummaryLimit]) + "…"
+	}
+	return summary
+}
+
+// RepoInfoStore 持久化仓库元数据
+type RepoInfoStore interface {
+	// RepoInfo 读取缓存的元数据，没有时返回 nil
+	RepoInfo(key string) (*RepoInfo, error)
+	// SaveRepoInfo 以缓存键保存元数据。键由主机或客户端范围加上查询时使用的仓库名组成（仓库改名后 info.FullName 可能不同）
+	SaveRepoInfo(key string, info *RepoInfo) error
+}
+
+// RepoInfoCache 缓存仓库元数据，跨请求共享：先查内存，再查持久化存储（可选），过期或缺失时才请求 GitHub。
+// 公开仓库的元数据对所有客户端相同，按主机共享；私有仓库和「不存在或无权访问」的结果只对拉取它的 token 有效，
+// 按客户端隔离，避免私有仓库的描述、README 出现在其他用户的报告中，也避免一个 token 无权访问导致其他 token 取不到元数据
+type RepoInfoCache struct {
+	store   RepoInfoStore
+	mu      sync.Mutex
+	entries map[string]*RepoInfo
+}
+
+// NewRepoInfoCache 创建缓存，store 为 nil 时只缓存在内存中
+func NewRepoInfoCache(store RepoInfoStore) *RepoInfoCache {
+	return &RepoInfoCache{
+		store:   store,
+		entries: make(map[string]*RepoInfo),
+	}
+}
+
+// Source 返回使用 client 拉取、共享此缓存的元数据来源
+func (c *RepoInfoCache) Source(client *Client) *RepoInfoSource {
+	return &RepoInfoSource{cache: c, client: client}
+}
+
+// get 返回 key 下未过期的缓存
+func (c *RepoInfoCache) get(key string) *RepoInfo {
+	c.mu.Lock()
+	info := c.entries[key]
+	c.mu.Unlock()
+
+	if info == nil && c.store != nil {
+		stored, err := c.store.RepoInfo(key)
+		if err != nil {
+			println("[GitHub] 读取仓库元数据缓存失败:", err.Error())
+		}
+		info = stored
+	}
+	ttl := RepoInfoTTL
+	if info != nil && info.Missing {
+		ttl = RepoInfoMissingTTL
+	}
+	if info == nil || time.Since(info.FetchedAt) > ttl {
+		return nil
+	}
+
+	c.mu.Lock()
+	c.entries[key] = info
+	c.mu.Unlock()
+	return info
+}
+
+func (c *RepoInfoCache) put(key string, info *RepoInfo) {
+	c.mu.Lock()
+	c.entries[key] = info
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.SaveRepoInfo(key, info); err != nil {
+			println("[GitHub] 保存仓库元数据缓存失败:", err.Error())
+		}
+	}
+}
+
+// RepoInfoSource 通过 RepoInfoCache 获取仓库元数据
+type RepoInfoSource struct {
+	cache  *RepoInfoCache
+	client *Client
+}
+
+// Host 返回仓库所在的主机名，见 Client.Host
+func (s *RepoInfoSource) Host() string {
+	return s.client.Host()
+}
+
+// sharedKey 是公开仓库在所有客户端间共享的缓存键
+func (s *RepoInfoSource) sharedKey(repo string) string {
+	return s.client.Host() + "/" + strings.ToLower(repo)
+}
+
+// scopedKey 是只对当前客户端有效的缓存键
+func (s *RepoInfoSource) scopedKey(repo string) string {
+	return s.client.cacheScope() + "/" + strings.ToLower(repo)
+}
+
+// FetchRepositories 返回 repos 中能取到元数据的仓库，键为传入的仓库名。单个仓库失败时只记录日志。
+func (s *RepoInfoSource) FetchRepositories(ctx context.Context, repos []string) map[string]*RepoInfo {
+	result := make(map[string]*RepoInfo, len(repos))
+	for _, repo := range repos {
+		info := s.cache.get(s.sharedKey(repo))
+		if info == nil {
+			info = s.cache.get(s.scopedKey(repo))
+		}
+		if info == nil {
+			fetched, err := s.client.RepoInfo(ctx, repo)
+			if err != nil {
+				println("[GitHub] 获取仓库元数据失败:", err.Error())
+				continue
+			}
+			if !fetched.Missing && fetched.Visibility == "public" {
+				s.cache.put(s.sharedKey(repo), fetched)
+			} else {
+				s.cache.put(s.scopedKey(repo), fetched)
+			}
+			info = fetched
+		}
+		if !info.Missing {
+			result[repo] = info
+		}
+	}
+	return result
+}
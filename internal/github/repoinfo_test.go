@@ -0,0 +1,133 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github-reports/internal/github/githubtest"
+)
+
+func TestRepoInfoMissing(t *testing.T) {
+	reset := fmt.Sprint(time.Now().Add(time.Hour).Unix())
+	tests := []struct {
+		name        string
+		fixture     githubtest.Fixture
+		wantMissing bool
+		wantErr     bool
+	}{
+		{
+			name:        "not found",
+			fixture:     githubtest.Fixture{Status: 404, Body: json.RawMessage(`{"message": "Not Found"}`)},
+			wantMissing: true,
+		},
+		{
+			name:        "forbidden",
+			fixture:     githubtest.Fixture{Status: 403, Body: json.RawMessage(`{"message": "Resource not accessible by integration"}`)},
+			wantMissing: true,
+		},
+		{
+			name: "primary rate limit",
+			fixture: githubtest.Fixture{
+				Status:  403,
+				Headers: map[string]string{"X-RateLimit-Limit": "5000", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset},
+				Body:    json.RawMessage(`{"message": "API rate limit exceeded"}`),
+			},
+			wantErr: true,
+		},
+		{
+			name: "secondary rate limit",
+			fixture: githubtest.Fixture{
+				Status: 403,
+				Body: json.RawMessage(`{"message": "You have exceeded a secondary rate limit.",
+					"documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fixture.Method, tt.fixture.Path = "GET", "/repos/octo/app"
+			srv := githubtest.NewServer([]githubtest.Fixture{tt.fixture})
+			defer srv.Close()
+
+			info, err := NewClient("test-token", WithBaseURL(srv.BaseURL())).RepoInfo(context.Background(), "octo/app")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RepoInfo error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && info.Missing != tt.wantMissing {
+				t.Errorf("Missing = %v, want %v", info.Missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestRepoInfoCacheMissingTTL(t *testing.T) {
+	tests := []struct {
+		name   string
+		info   RepoInfo
+		wantOK bool
+	}{
+		{"fresh metadata", RepoInfo{FetchedAt: time.Now().Add(-24 * time.Hour)}, true},
+		{"fresh missing entry", RepoInfo{Missing: true, FetchedAt: time.Now().Add(-time.Hour)}, true},
+		{"stale missing entry", RepoInfo{Missing: true, FetchedAt: time.Now().Add(-RepoInfoMissingTTL - time.Minute)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewRepoInfoCache(nil)
+			info := tt.info
+			cache.put("octo/app", &info)
+			if got := cache.get("octo/app") != nil; got != tt.wantOK {
+				t.Errorf("cached = %v, want %v", got, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestRepoInfoCacheScopes 验证公开仓库的元数据在客户端间共享，私有仓库和无权访问的结果只对拉取它的 token 有效
+func TestRepoInfoCacheScopes(t *testing.T) {
+	fixture := func(path string, status int, body string) githubtest.Fixture {
+		return githubtest.Fixture{Method: "GET", Path: path, Status: status, Body: json.RawMessage(body)}
+	}
+	// alice 的 token 能看到私有仓库；bob 的 token 对两个仓库都得到 404
+	aliceSrv := githubtest.NewServer([]githubtest.Fixture{
+		fixture("/repos/octo/public", 200, `{"full_name": "octo/public", "description": "open", "visibility": "public"}`),
+		fixture("/repos/octo/secret", 200, `{"full_name": "octo/secret", "description": "classified", "visibility": "private"}`),
+	})
+	defer aliceSrv.Close()
+	bobSrv := githubtest.NewServer(nil)
+	defer bobSrv.Close()
+
+	cache := NewRepoInfoCache(nil)
+	alice := cache.Source(NewClient("alice-token", WithBaseURL(aliceSrv.BaseURL())))
+	bob := cache.Source(NewClient("bob-token", WithBaseURL(bobSrv.BaseURL())))
+	repos := []string{"octo/public", "octo/secret"}
+
+	if got := alice.FetchRepositories(context.Background(), repos); len(got) != 2 {
+		t.Fatalf("alice got %d repositories, want 2", len(got))
+	}
+
+	got := bob.FetchRepositories(context.Background(), repos)
+	if got["octo/public"] == nil || got["octo/public"].Description != "open" {
+		t.Errorf("bob did not get the shared public metadata: %+v", got["octo/public"])
+	}
+	if got["octo/secret"] != nil {
+		t.Errorf("alice's private metadata leaked to bob: %+v", got["octo/secret"])
+	}
+	if reqs := bobSrv.Requests(); fmt.Sprint(reqs) != "[GET /repos/octo/secret]" {
+		t.Errorf("bob requested %v, want only the private repository", reqs)
+	}
+
+	// bob 的「无权访问」不影响 alice
+	before := len(aliceSrv.Requests())
+	if got := alice.FetchRepositories(context.Background(), repos); got["octo/secret"] == nil {
+		t.Error("bob's missing result hid the private repository from alice")
+	}
+	if after := len(aliceSrv.Requests()); after != before {
+		t.Errorf("alice made %d more requests, want everything from the cache", after-before)
+	}
+}
@@ -0,0 +1,323 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// RoadmapTargets 描述需要关联到报告中的 Projects v2 看板和里程碑所在仓库
+type RoadmapTargets struct {
+	Projects       []ProjectTarget
+	MilestoneRepos []string // owner/repo
+}
+
+// ProjectTarget 指向一个 Projects v2 看板
+type ProjectTarget struct {
+	Owner       string // 组织或用户登录名
+	Number      int
+	StatusField string // 状态字段名，默认 Status
+	DoneStatus  string // 表示完成的状态值，默认 Done
+}
+
+// Empty 判断是否没有配置任何路线图来源
+func (t RoadmapTargets) Empty() bool {
+	return len(t.Projects) == 0 && len(t.MilestoneRepos) == 0
+}
+
+// projectItemsMaxPages 限制单个看板最多翻页次数，避免超大看板耗尽配额；超出时看板标记为 Partial
+const projectItemsMaxPages = 5
+
+const projectItemsQuery = `query($owner: String!, $number: Int!, $status: String!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on Organization { projectV2(number: $number) { ...projectFields } }
+    ... on User { projectV2(number: $number) { ...projectFields } }
+  }
+}
+
+fragment projectFields on ProjectV2 {
+  title
+  url
+  items(first: 100, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      type
+      updatedAt
+      fieldValueByName(name: $status) {
+        ... on ProjectV2ItemFieldSingleSelectValue {
+          name
+          updatedAt
+          creator { login }
+        }
+      }
+      content {
+        ... on Issue { title url repository { nameWithOwner } author { login } assignees(first: 10) { nodes { login } } }
+        ... on PullRequest { title url repository { nameWithOwner } author { login } assignees(first: 10) { nodes { login } } }
+        ... on DraftIssue { title creator { login } assignees(first: 10) { nodes { login } } }
+      }
+    }
+  }
+}`
+
+type projectItemNode struct {
+	Type             string    `json:"type"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	FieldValueByName *struct {
+		Name      string    `json:"name"`
+		UpdatedAt time.Time `json:"updatedAt"`
+		Creator   *struct {
+			Login string `json:"login"`
+		} `json:"creator"`
+	} `json:"fieldValueByName"`
+	Content *struct {
+		Title      string `json:"title"`
+		URL        string `json:"url"`
+		Repository *struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"repository"`
+		Author *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+		Creator *struct {
+			Login string `json:"login"`
+		} `json:"creator"`
+		Assignees struct {
+			Nodes []struct {
+				Login string `json:"login"`
+			} `json:"nodes"`
+		} `json:"assignees"`
+	} `json:"content"`
+}
+
+type projectV2Node struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Items struct {
+		PageInfo struct {
+			HasNextPage bool   `json:"hasNextPage"`
+			EndCursor   string `json:"endCursor"`
+		} `json:"pageInfo"`
+		Nodes []projectItemNode `json:"nodes"`
+	} `json:"items"`
+}
+
+// FetchRoadmap 获取用户在时间范围内推动过的看板条目和相关里程碑进度。
+// 单个看板或仓库失败只记录日志，不影响其余部分。
+func (f *Fetcher) FetchRoadmap(ctx context.Context, activity *UserActivity, targets RoadmapTargets) *Roadmap {
+	roadmap := &Roadmap{}
+
+	for _, target := range targets.Projects {
+		project, err := f.fetchProjectProgress(ctx, activity, target)
+		if err != nil {
+			println("[Fetcher] 拉取 Project 失败:", target.Owner, target.Number, err.Error())
+			continue
+		}
+		if len(project.Items) > 0 || project.Partial {
+			roadmap.Projects = append(roadmap.Projects, *project)
+		}
+	}
+
+	for _, repo := range targets.MilestoneRepos {
+		milestones, err := f.fetchMilestoneProgress(ctx, activity, repo)
+		if err != nil {
+			println("[Fetcher] 拉取里程碑失败:", repo, err.Error())
+			continue
+		}
+		roadmap.Milestones = append(roadmap.Milestones, milestones...)
+	}
+
+	println("[Fetcher]", activity.Username, "- 路线图:", len(roadmap.Projects), "个 Project,", len(roadmap.Milestones), "个里程碑")
+
+	return roadmap
+}
+
+func (f *Fetcher) fetchProjectProgress(ctx context.Context, activity *UserActivity, target ProjectTarget) (*ProjectProgress, error) {
+	statusField := target.StatusField
+	if statusField == "" {
+		statusField = "Status"
+	}
+	doneStatus := target.DoneStatus
+	if doneStatus == "" {
+		doneStatus = "Done"
+	}
+
+	touched := activity.touchedURLs()
+	progress := &ProjectProgress{}
+
+	var cursor *string
+	for page := 0; ; page++ {
+		if page == projectItemsMaxPages {
+			progress.Partial = true
+			println("[Fetcher] Project", target.Owner, target.Number, "条目超过", projectItemsMaxPages*100, "个，只检查了前面的部分")
+			break
+		}
+
+		var data struct {
+			RepositoryOwner *struct {
+				ProjectV2 *projectV2Node `json:"projectV2"`
+			} `json:"repositoryOwner"`
+		}
+
+		vars := map[string]interface{}{
+			"owner":  target.Owner,
+			"number": target.Number,
+			"status": statusField,
+			"cursor": cursor,
+		}
+		if err := f.client.graphQL(ctx, projectItemsQuery, vars, &data); err != nil {
+			return nil, err
+		}
+		if data.RepositoryOwner == nil || data.RepositoryOwner.ProjectV2 == nil {
+			return nil, fmt.Errorf("project %s/%d not found", target.Owner, target.Number)
+		}
+
+		project := data.RepositoryOwner.ProjectV2
+		progress.Title = project.Title
+		progress.URL = project.URL
+
+		for _, node := range project.Items.Nodes {
+			item, ok := projectItemFromNode(node, activity, touched, doneStatus)
+			if ok {
+				progress.Items = append(progress.Items, item)
+			}
+		}
+
+		if !project.Items.PageInfo.HasNextPage {
+			break
+		}
+		end := project.Items.PageInfo.EndCursor
+		cursor = &end
+	}
+
+	return progress, nil
+}
+
+// projectItemFromNode 判断看板条目是否在时间范围内被该用户推动过：
+// 状态字段在范围内被修改过（只知道当前值，不知道之前的值），且状态由用户修改、条目由用户创建 / 负责，或对应本期拉取到的 PR / issue
+func projectItemFromNode(node projectItemNode, activity *UserActivity, touched map[string]bool, doneStatus string) (ProjectItem, bool) {
+	if node.Content == nil || node.FieldValueByName == nil {
+		return ProjectItem{}, false
+	}
+
+	status := node.FieldValueByName
+	if status.UpdatedAt.Before(activity.Since) || status.UpdatedAt.After(activity.Until) {
+		return ProjectItem{}, false
+	}
+
+	content := node.Content
+	byUser := touched[content.URL]
+	if status.Creator != nil && strings.EqualFold(status.Creator.Login, activity.Username) {
+		byUser = true
+	}
+	if content.Author != nil && strings.EqualFold(content.Author.Login, activity.Username) {
+		byUser = true
+	}
+	if content.Creator != nil && strings.EqualFold(content.Creator.Login, activity.Username) {
+		byUser = true
+	}
+	for _, a := range content.Assignees.Nodes {
+		if strings.EqualFold(a.Login, activity.Username) {
+			byUser = true
+		}
+	}
+	if !byUser {
+		return ProjectItem{}, false
+	}
+
+	item := ProjectItem{
+		Title:           content.Title,
+		URL:             content.URL,
+		Type:            node.Type,
+		Status:          status.Name,
+		StatusUpdatedAt: status.UpdatedAt,
+		Done:            strings.EqualFold(status.Name, doneStatus),
+	}
+	if content.Repository != nil {
+		item.Repo = content.Repository.NameWithOwner
+	}
+
+	return item, true
+}
+
+// fetchMilestoneProgress 返回在 [Since, Until] 内有更新、或包含该用户本期 PR / issue 的里程碑及其完成度，
+// 并统计其中属于该用户的条目
+func (f *Fetcher) fetchMilestoneProgress(ctx context.Context, activity *UserActivity, fullName string) ([]MilestoneProgress, error) {
+	owner, repoName := parseRepoName(fullName)
+	if owner == "" || repoName == "" {
+		return nil, fmt.Errorf("invalid repository name: %s", fullName)
+	}
+
+	opts := &github.MilestoneListOptions{
+		State:       "all",
+		Sort:        "due_on",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var milestones []*github.Milestone
+	for {
+		page, resp, err := f.client.client.Issues.ListMilestones(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	userItems := make(map[string]int)
+	for _, pr := range activity.PullRequests {
+		if pr.Repo == fullName && pr.Milestone != "" {
+			userItems[pr.Milestone]++
+		}
+	}
+	for _, issue := range activity.Issues {
+		if issue.Repo == fullName && issue.Milestone != "" {
+			userItems[issue.Milestone]++
+		}
+	}
+
+	var result []MilestoneProgress
+	for _, m := range milestones {
+		title := getStringValue(m.Title)
+		updated := getTimeValue(m.UpdatedAt)
+		inRange := !updated.Before(activity.Since) && !updated.After(activity.Until)
+		if !inRange && userItems[title] == 0 {
+			continue
+		}
+
+		open, closed := getIntValue(m.OpenIssues), getIntValue(m.ClosedIssues)
+		progress := MilestoneProgress{
+			Repo:         fullName,
+			Title:        title,
+			URL:          getStringValue(m.HTMLURL),
+			State:        getStringValue(m.State),
+			OpenIssues:   open,
+			ClosedIssues: closed,
+			DueOn:        getTimePointer(m.DueOn),
+			UserItems:    userItems[title],
+		}
+		if open+closed > 0 {
+			progress.Percent = float64(closed) * 100 / float64(open+closed)
+		}
+
+		result = append(result, progress)
+	}
+
+	return result, nil
+}
+
+// touchedURLs 返回本期用户 PR 和 issue 的链接集合
+func (a *UserActivity) touchedURLs() map[string]bool {
+	urls := make(map[string]bool)
+	for _, pr := range a.PullRequests {
+		urls[pr.URL] = true
+	}
+	for _, issue := range a.Issues {
+		urls[issue.URL] = true
+	}
+	return urls
+}
@@ -0,0 +1,190 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github-reports/internal/github/githubtest"
+)
+
+func roadmapActivity() *UserActivity {
+	since, until := replayRange()
+	return &UserActivity{
+		Username: "octocat",
+		Since:    since,
+		Until:    until,
+		PullRequests: []PullRequestInfo{
+			{Number: 7, Repo: "octo/app", URL: "https://github.com/octo/app/pull/7", Milestone: "v2.0"},
+		},
+		Issues: []IssueInfo{
+			{Number: 9, Repo: "octo/lib", URL: "https://github.com/octo/lib/issues/9", Milestone: "v1.0"},
+		},
+	}
+}
+
+func TestProjectItemFromNode(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     string
+		wantOK   bool
+		wantDone bool
+	}{
+		{
+			name:   "status changed by the user",
+			node:   `{"type":"ISSUE","fieldValueByName":{"name":"In Progress","updatedAt":"2026-10-12T08:00:00Z","creator":{"login":"OctoCat"}},"content":{"title":"a","url":"https://github.com/octo/x/issues/1"}}`,
+			wantOK: true,
+		},
+		{
+			name:     "authored by the user and done",
+			node:     `{"type":"PULL_REQUEST","fieldValueByName":{"name":"done","updatedAt":"2026-10-12T08:00:00Z","creator":{"login":"bot"}},"content":{"title":"b","url":"https://github.com/octo/x/pull/2","author":{"login":"octocat"},"repository":{"nameWithOwner":"octo/x"}}}`,
+			wantOK:   true,
+			wantDone: true,
+		},
+		{
+			name:   "draft created by the user",
+			node:   `{"type":"DRAFT_ISSUE","fieldValueByName":{"name":"Todo","updatedAt":"2026-10-12T08:00:00Z"},"content":{"title":"c","creator":{"login":"octocat"}}}`,
+			wantOK: true,
+		},
+		{
+			name:   "assigned to the user",
+			node:   `{"type":"ISSUE","fieldValueByName":{"name":"Todo","updatedAt":"2026-10-12T08:00:00Z"},"content":{"title":"d","url":"https://github.com/octo/x/issues/4","assignees":{"nodes":[{"login":"hubot"},{"login":"octocat"}]}}}`,
+			wantOK: true,
+		},
+		{
+			name:   "pull request fetched this period",
+			node:   `{"type":"PULL_REQUEST","fieldValueByName":{"name":"Review","updatedAt":"2026-10-12T08:00:00Z"},"content":{"title":"e","url":"https://github.com/octo/app/pull/7"}}`,
+			wantOK: true,
+		},
+		{
+			name: "not touched by the user",
+			node: `{"type":"ISSUE","fieldValueByName":{"name":"Todo","updatedAt":"2026-10-12T08:00:00Z","creator":{"login":"hubot"}},"content":{"title":"f","url":"https://github.com/octo/x/issues/6","author":{"login":"hubot"}}}`,
+		},
+		{
+			name: "status changed before the range",
+			node: `{"type":"ISSUE","fieldValueByName":{"name":"Done","updatedAt":"2026-10-01T08:00:00Z","creator":{"login":"octocat"}},"content":{"title":"g","url":"https://github.com/octo/x/issues/7"}}`,
+		},
+		{
+			name: "status changed after the range",
+			node: `{"type":"ISSUE","fieldValueByName":{"name":"Done","updatedAt":"2026-10-18T08:00:00Z","creator":{"login":"octocat"}},"content":{"title":"h","url":"https://github.com/octo/x/issues/8"}}`,
+		},
+		{
+			name: "no status field",
+			node: `{"type":"ISSUE","content":{"title":"i","url":"https://github.com/octo/x/issues/9","author":{"login":"octocat"}}}`,
+		},
+		{
+			name: "no content",
+			node: `{"type":"REDACTED","fieldValueByName":{"name":"Done","updatedAt":"2026-10-12T08:00:00Z","creator":{"login":"octocat"}}}`,
+		},
+	}
+
+	activity := roadmapActivity()
+	touched := activity.touchedURLs()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node projectItemNode
+			if err := json.Unmarshal([]byte(tt.node), &node); err != nil {
+				t.Fatalf("unmarshal node: %v", err)
+			}
+
+			item, ok := projectItemFromNode(node, activity, touched, "Done")
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && item.Done != tt.wantDone {
+				t.Errorf("Done = %v, want %v (status %q)", item.Done, tt.wantDone, item.Status)
+			}
+		})
+	}
+}
+
+func TestFetchMilestoneProgress(t *testing.T) {
+	srv := githubtest.NewServer([]githubtest.Fixture{
+		{
+			Method:  "GET",
+			Path:    "/repos/octo/app/milestones",
+			Query:   "state=all&sort=due_on&per_page=100",
+			Headers: map[string]string{"Link": `<{{base_url}}/repos/octo/app/milestones?state=all&sort=due_on&per_page=100&page=2>; rel="next"`},
+			Body: json.RawMessage(`[
+				{"title":"v2.0","state":"open","open_issues":3,"closed_issues":1,"updated_at":"2026-09-01T00:00:00Z"},
+				{"title":"v1.9","state":"closed","open_issues":0,"closed_issues":0,"updated_at":"2026-10-11T00:00:00Z"}
+			]`),
+		},
+		{
+			Method: "GET",
+			Path:   "/repos/octo/app/milestones",
+			Query:  "state=all&sort=due_on&per_page=100&page=2",
+			Body: json.RawMessage(`[
+				{"title":"v3.0","state":"open","open_issues":1,"closed_issues":1,"updated_at":"2026-10-15T00:00:00Z"},
+				{"title":"v0.1","state":"closed","open_issues":0,"closed_issues":5,"updated_at":"2025-01-01T00:00:00Z"}
+			]`),
+		},
+	})
+	t.Cleanup(srv.Close)
+
+	f := NewFetcher(NewClient("test-token", WithBaseURL(srv.BaseURL())))
+	milestones, err := f.fetchMilestoneProgress(context.Background(), roadmapActivity(), "octo/app")
+	if err != nil {
+		t.Fatalf("fetchMilestoneProgress: %v (unmatched %v)", err, srv.Unmatched())
+	}
+
+	want := map[string]struct {
+		userItems int
+		percent   float64
+	}{
+		"v2.0": {1, 25}, // 不在时间范围内，但包含用户本期的 PR
+		"v1.9": {0, 0},  // 没有条目时完成度为 0
+		"v3.0": {0, 50}, // 只有第二页才能拿到
+	}
+	if len(milestones) != len(want) {
+		t.Fatalf("milestones = %+v, want %d entries", milestones, len(want))
+	}
+	for _, m := range milestones {
+		w, ok := want[m.Title]
+		if !ok {
+			t.Errorf("unexpected milestone %q", m.Title)
+			continue
+		}
+		if m.Repo != "octo/app" || m.UserItems != w.userItems || m.Percent != w.percent {
+			t.Errorf("%s = %+v, want user items %d, percent %v", m.Title, m, w.userItems, w.percent)
+		}
+	}
+}
+
+func TestFetchProjectProgressPartial(t *testing.T) {
+	// 每一页都声称还有下一页，翻到上限后看板应标记为不完整
+	srv := githubtest.NewServer([]githubtest.Fixture{{
+		Method:       "POST",
+		Path:         "/graphql",
+		BodyContains: "projectV2",
+		Body: json.RawMessage(`{"data":{"repositoryOwner":{"projectV2":{"title":"Roadmap","url":"https://github.com/orgs/octo/projects/1","items":{
+			"pageInfo":{"hasNextPage":true,"endCursor":"next"},
+			"nodes":[{"type":"ISSUE","fieldValueByName":{"name":"Done","updatedAt":"2026-10-12T08:00:00Z","creator":{"login":"octocat"}},"content":{"title":"a","url":"https://github.com/octo/x/issues/1"}}]
+		}}}}}`),
+	}})
+	t.Cleanup(srv.Close)
+
+	f := NewFetcher(NewClient("test-token", WithBaseURL(srv.BaseURL())))
+	progress, err := f.fetchProjectProgress(context.Background(), roadmapActivity(), ProjectTarget{Owner: "octo", Number: 1})
+	if err != nil {
+		t.Fatalf("fetchProjectProgress: %v (unmatched %v)", err, srv.Unmatched())
+	}
+
+	var pages int
+	for _, req := range srv.Requests() {
+		if strings.HasPrefix(req, "POST /graphql") {
+			pages++
+		}
+	}
+	if pages != projectItemsMaxPages {
+		t.Errorf("requested %d pages, want %d", pages, projectItemsMaxPages)
+	}
+	if !progress.Partial {
+		t.Error("Partial = false, want true after hitting the page cap")
+	}
+	if len(progress.Items) != projectItemsMaxPages || !progress.Items[0].Done {
+		t.Errorf("items = %+v, want %d done items", progress.Items, projectItemsMaxPages)
+	}
+}
@@ -0,0 +1,304 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// EventsRetention 是 Events API 能回溯的最长时间，更早的活动只能通过 Search API 获取
+const EventsRetention = 90 * 24 * time.Hour
+
+// searchResultLimit 是 Search API 单个查询最多返回的结果数
+const searchResultLimit = 1000
+
+// FetchHistory 按时间范围选择拉取方式：仍在 Events API 保留期内时使用事件时间线，
+// 否则使用 Search API。用于回填历史周期。
+func (f *Fetcher) FetchHistory(ctx context.Context, username string, since, until time.Time) (*UserActivity, error) {
+	if time.Since(since) < EventsRetention {
+		return f.FetchActivities(ctx, username, since, until)
+	}
+	return f.FetchActivitiesBySearch(ctx, username, since, until)
+}
+
+// FetchActivitiesBySearch 通过 Search API 获取活动。
+// Search API 只索引默认分支上的 commit，因此功能分支上未合并的工作不会出现；
+// review 通过 reviewed-by 找到 PR 后再逐个列出该用户在范围内提交的 review。
+// 任一查询超过 Search API 的结果上限时，活动标记为不完整。
+func (f *Fetcher) FetchActivitiesBySearch(ctx context.Context, username string, since, until time.Time) (*UserActivity, error) {
+	activity := &UserActivity{
+		Username: username,
+		Since:    since,
+		Until:    until,
+	}
+
+	println("[Fetcher]", username, "- 正在通过 Search API 拉取历史活动...")
+	dateRange := since.UTC().Format(time.RFC3339) + ".." + until.UTC().Format(time.RFC3339)
+
+	commits, truncated, err := f.searchCommits(ctx, fmt.Sprintf("author:%s author-date:%s", username, dateRange))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search commits: %w", err)
+	}
+	activity.Truncated = truncated
+
+	// 本期创建的 PR，以及更早创建但在本期合并的 PR
+	prs, truncated, err := f.searchPullRequests(ctx, username,
+		fmt.Sprintf("author:%s type:pr created:%s", username, dateRange),
+		fmt.Sprintf("author:%s type:pr merged:%s", username, dateRange))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search pull requests: %w", err)
+	}
+	activity.Truncated = activity.Truncated || truncated
+	activity.PullRequests = prs
+
+	activity.Commits = dedupCommits(commits, f.defaultBranches)
+	f.attributeCommits(ctx, activity.Commits, prs)
+
+	issues, truncated, err := f.searchIssues(ctx, fmt.Sprintf("author:%s type:issue created:%s", username, dateRange))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+	activity.Truncated = activity.Truncated || truncated
+	activity.Issues = issues
+
+	reviews, truncated, err := f.searchReviews(ctx, username, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search reviews: %w", err)
+	}
+	activity.Truncated = activity.Truncated || truncated
+	activity.Reviews = reviews
+
+	f.linkClosingIssues(ctx, activity.PullRequests)
+	activity.LinkWorkItems()
+
+	println("[Fetcher]", username, "- 找到", len(activity.Commits), "个 Commits,", len(prs), "个 Pull Requests,", len(issues), "个 Issues,", len(reviews), "个 Code Reviews")
+
+	return activity, nil
+}
+
+// searchCommits 搜索 commit，结果超过 Search API 上限时返回 truncated
+func (f *Fetcher) searchCommits(ctx context.Context, query string) ([]CommitInfo, bool, error) {
+	var commits []CommitInfo
+	truncated := false
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		result, resp, err := f.client.client.Search.Commits(ctx, query, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		if opts.Page == 0 && result.GetTotal() > searchResultLimit {
+			truncated = true
+			println("[Fetcher] Search API 最多返回", searchResultLimit, "条结果，实际", result.GetTotal(), "条，请缩短回填周期:", query)
+		}
+
+		for _, r := range result.Commits {
+			repo := r.GetRepository().GetFullName()
+			sha := r.GetSHA()
+			if repo == "" || sha == "" {
+				continue
+			}
+
+			f.loadDefaultBranch(ctx, repo)
+			info := CommitInfo{
+				SHA:     sha,
+				Message: r.GetCommit().GetMessage(),
+				Repo:    repo,
+				URL:     r.GetHTMLURL(),
+				Author:  r.GetCommit().GetAuthor().GetName(),
+				Date:    r.GetCommit().GetAuthor().GetDate().Time,
+				Refs:    []string{repo + ":" + f.defaultBranches[repo]},
+			}
+
+			owner, repoName := parseRepoName(repo)
+			if c, err := f.getCommit(ctx, owner, repoName, sha); err == nil {
+				info.Additions = c.GetStats().GetAdditions()
+				info.Deletions = c.GetStats().GetDeletions()
+				info.PatchID = patchID(c.Files)
+				info.Files = fileChanges(c.Files)
+				info.IsMerge = len(c.Parents) > 1
+			}
+
+			commits = append(commits, info)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return commits, truncated, nil
+}
+
+// searchIssueResults 执行一个 issue 搜索并返回所有结果，结果超过 Search API 上限时返回 truncated
+func (f *Fetcher) searchIssueResults(ctx context.Context, query string) ([]*github.Issue, bool, error) {
+	var issues []*github.Issue
+	truncated := false
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		result, resp, err := f.client.client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		if opts.Page == 0 && result.GetTotal() > searchResultLimit {
+			truncated = true
+			println("[Fetcher] Search API 最多返回", searchResultLimit, "条结果，实际", result.GetTotal(), "条，请缩短回填周期:", query)
+		}
+		issues = append(issues, result.Issues...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return issues, truncated, nil
+}
+
+// searchPullRequests 合并多个查询的结果，并逐个获取 PR 详情以拿到增删行数和合并时间
+func (f *Fetcher) searchPullRequests(ctx context.Context, username string, queries ...string) ([]PullRequestInfo, bool, error) {
+	var prs []PullRequestInfo
+	seen := make(map[string]bool)
+	truncated := false
+
+	for _, query := range queries {
+		results, more, err := f.searchIssueResults(ctx, query)
+		if err != nil {
+			return nil, false, err
+		}
+		truncated = truncated || more
+
+		for _, issue := range results {
+			owner, repoName, ok := repoFromIssue(issue)
+			if !ok {
+				continue
+			}
+			repo := owner + "/" + repoName
+			key := fmt.Sprintf("%s#%d", repo, issue.GetNumber())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			pr, _, err := f.client.client.PullRequests.Get(ctx, owner, repoName, issue.GetNumber())
+			if err != nil {
+				println("[Fetcher] 获取 PR 详情失败:", key, err.Error())
+				continue
+			}
+
+			info := PullRequestInfo{
+				Number:    pr.GetNumber(),
+				Title:     pr.GetTitle(),
+				Repo:      repo,
+				URL:       pr.GetHTMLURL(),
+				State:     pr.GetState(),
+				CreatedAt: pr.GetCreatedAt().Time,
+				MergedAt:  getTimePointer(pr.MergedAt),
+				Additions: pr.GetAdditions(),
+				Deletions: pr.GetDeletions(),
+				Comments:  pr.GetComments(),
+				Milestone: pr.GetMilestone().GetTitle(),
+
+				HeadSHA:        pr.GetHead().GetSHA(),
+				MergeCommitSHA: pr.GetMergeCommitSHA(),
+
+				ClosingIssues: parseClosingRefs(f.client.Host(), repo, pr.GetBody()),
+			}
+			prs = append(prs, info)
+		}
+	}
+
+	return prs, truncated, nil
+}
+
+func (f *Fetcher) searchIssues(ctx context.Context, query string) ([]IssueInfo, bool, error) {
+	results, truncated, err := f.searchIssueResults(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var issues []IssueInfo
+	for _, issue := range results {
+		owner, repoName, ok := repoFromIssue(issue)
+		if !ok {
+			continue
+		}
+		issues = append(issues, IssueInfo{
+			Number:    issue.GetNumber(),
+			Title:     issue.GetTitle(),
+			Repo:      owner + "/" + repoName,
+			URL:       issue.GetHTMLURL(),
+			State:     issue.GetState(),
+			CreatedAt: issue.GetCreatedAt().Time,
+			ClosedAt:  getTimePointer(issue.ClosedAt),
+			Comments:  issue.GetComments(),
+			Milestone: issue.GetMilestone().GetTitle(),
+		})
+	}
+	return issues, truncated, nil
+}
+
+// searchReviews 找到用户 review 过且在范围开始后有更新的 PR，再列出该用户在范围内提交的 review
+func (f *Fetcher) searchReviews(ctx context.Context, username string, since, until time.Time) ([]ReviewInfo, bool, error) {
+	query := fmt.Sprintf("reviewed-by:%s type:pr updated:>=%s created:<=%s",
+		username, since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339))
+	results, truncated, err := f.searchIssueResults(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var reviews []ReviewInfo
+	for _, issue := range results {
+		owner, repoName, ok := repoFromIssue(issue)
+		if !ok {
+			continue
+		}
+
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			prReviews, resp, err := f.client.client.PullRequests.ListReviews(ctx, owner, repoName, issue.GetNumber(), opts)
+			if err != nil {
+				println("[Fetcher] 获取 PR review 失败:", owner+"/"+repoName, issue.GetNumber(), err.Error())
+				break
+			}
+
+			for _, r := range prReviews {
+				submitted := r.GetSubmittedAt().Time
+				if r.GetUser().GetLogin() != username || submitted.Before(since) || submitted.After(until) {
+					continue
+				}
+				reviews = append(reviews, ReviewInfo{
+					PRNumber:  issue.GetNumber(),
+					PRTitle:   issue.GetTitle(),
+					Repo:      owner + "/" + repoName,
+					URL:       r.GetHTMLURL(),
+					State:     r.GetState(),
+					CreatedAt: submitted,
+				})
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	return reviews, truncated, nil
+}
+
+// repoFromIssue 从搜索结果的 repository_url（.../repos/{owner}/{repo}）中解析仓库
+func repoFromIssue(issue *github.Issue) (owner, repo string, ok bool) {
+	u := issue.GetRepositoryURL()
+	i := strings.LastIndex(u, "/repos/")
+	if i < 0 {
+		return "", "", false
+	}
+	owner, repo = parseRepoName(u[i+len("/repos/"):])
+	return owner, repo, owner != "" && repo != ""
+}
@@ -0,0 +1,230 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 活动类型，用于 Stats.ByType
+const (
+	ActivityCommit      = "commit"
+	ActivityPullRequest = "pull_request"
+	ActivityIssue       = "issue"
+	ActivityReview      = "review"
+)
+
+// Stats 是一段时间内活动的统计结果，可直接序列化给 LLM 和 API
+type Stats struct {
+	TotalCommits   int `json:"total_commits"`
+	TotalPRs       int `json:"total_prs"`
+	MergedPRs      int `json:"merged_prs"`
+	TotalIssues    int `json:"total_issues"`
+	ClosedIssues   int `json:"closed_issues"`
+	TotalReviews   int `json:"total_reviews"`
+	TotalReleases  int `json:"total_releases"`
+	CodeAdditions  int `json:"code_additions"` // 增删行数每项改动只计一次：PR 有行数时以 PR 为准，已计入 PR 的 commit 和 merge commit 不再累加
+	CodeDeletions  int `json:"code_deletions"`
+	NetCodeChanges int `json:"net_code_changes"`
+
+	ActiveDays    int         `json:"active_days"`              // 有任意活动的自然日数
+	LargestCommit *CommitSize `json:"largest_commit,omitempty"` // 增删行数最多的非 merge commit
+	MedianPRSize  int         `json:"median_pr_size"`           // PR 增删行数之和的中位数
+	// ReviewRatio 是 review 数与本人提交 PR 数之比；没有提交 PR 时为空
+	ReviewRatio *float64 `json:"review_to_authored_ratio,omitempty"`
+
+	// ByChangeType 按变更类型统计工作单元：每个工作项（PR）计一次，未关联 PR 的 commit 各计一次
+	ByChangeType    map[string]int `json:"by_change_type"`
+	BreakingChanges int            `json:"breaking_changes"`
+
+	// Testing 是测试纪律指标；UntestedFeatures 列出新增了生产代码却没有改动任何测试的功能 PR
+	Testing          TestingStats `json:"testing"`
+	UntestedFeatures []string     `json:"untested_features,omitempty"`
+
+	ByRepo map[string]*RepoStats `json:"by_repo,omitempty"`
+	ByDay  map[string]*DayStats  `json:"by_day,omitempty"` // 键为 YYYY-MM-DD
+	ByType map[string]int        `json:"by_type"`
+}
+
+// CommitSize 标识一个 commit 及其规模
+type CommitSize struct {
+	SHA       string `json:"sha"`
+	Repo      string `json:"repo"`
+	Message   string `json:"message"` // 只保留第一行
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// RepoStats 是单个仓库内的活动计数，增删行数的口径与 Stats 相同
+type RepoStats struct {
+	Commits   int `json:"commits"`
+	PRs       int `json:"prs"`
+	MergedPRs int `json:"merged_prs"`
+	Issues    int `json:"issues"`
+	Reviews   int `json:"reviews"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+
+	Testing TestingStats `json:"testing"`
+}
+
+// DayStats 是某一天的活动计数
+type DayStats struct {
+	Commits      int `json:"commits"`
+	PullRequests int `json:"pull_requests"`
+	Issues       int `json:"issues"`
+	Reviews      int `json:"reviews"`
+}
+
+// Total 返回当天的活动总数
+func (d *DayStats) Total() int {
+	return d.Commits + d.PullRequests + d.Issues + d.Reviews
+}
+
+// TotalActivities 返回 commit、PR、issue、review 和 release 的总数
+func (s *Stats) TotalActivities() int {
+	return s.TotalCommits + s.TotalPRs + s.TotalIssues + s.TotalReviews + s.TotalReleases
+}
+
+// Statistics 计算活动统计，按天的分组使用 loc 时区的自然日
+func (a *UserActivity) Statistics(loc *time.Location) *Stats {
+	s := &Stats{
+		TotalCommits:  len(a.Commits),
+		TotalPRs:      len(a.PullRequests),
+		TotalIssues:   len(a.Issues),
+		TotalReviews:  len(a.Reviews),
+		TotalReleases: len(a.Releases),
+		ByChangeType:  make(map[string]int),
+		ByRepo:        make(map[string]*RepoStats),
+		ByDay:         make(map[string]*DayStats),
+		ByType: map[string]int{
+			ActivityCommit:      len(a.Commits),
+			ActivityPullRequest: len(a.PullRequests),
+			ActivityIssue:       len(a.Issues),
+			ActivityReview:      len(a.Reviews),
+		},
+	}
+
+	repo := func(name string) *RepoStats {
+		if s.ByRepo[name] == nil {
+			s.ByRepo[name] = &RepoStats{}
+		}
+		return s.ByRepo[name]
+	}
+	day := func(t time.Time) *DayStats {
+		key := t.In(loc).Format("2006-01-02")
+		if s.ByDay[key] == nil {
+			s.ByDay[key] = &DayStats{}
+		}
+		return s.ByDay[key]
+	}
+
+	inPR := make(map[string]bool)
+	for _, item := range a.WorkItems {
+		if pr := item.PullRequest; pr.Additions+pr.Deletions > 0 {
+			for _, c := range item.Commits {
+				inPR[c.Repo+"@"+c.SHA] = true
+			}
+		}
+	}
+
+	for _, c := range a.Commits {
+		r := repo(c.Repo)
+		r.Commits++
+		if !inPR[c.Repo+"@"+c.SHA] && !c.IsMerge {
+			s.CodeAdditions += c.Additions
+			s.CodeDeletions += c.Deletions
+			r.Additions += c.Additions
+			r.Deletions += c.Deletions
+		}
+		day(c.Date).Commits++
+		s.Testing.add(c)
+		r.Testing.add(c)
+
+		// merge commit 的行数是整条分支的改动，不算单个 commit 的规模
+		if !c.IsMerge && (s.LargestCommit == nil || c.Additions+c.Deletions > s.LargestCommit.Additions+s.LargestCommit.Deletions) {
+			message, _, _ := strings.Cut(c.Message, "\n")
+			s.LargestCommit = &CommitSize{
+				SHA:       c.SHA,
+				Repo:      c.Repo,
+				Message:   message,
+				Additions: c.Additions,
+				Deletions: c.Deletions,
+			}
+		}
+	}
+
+	prSizes := make([]int, 0, len(a.PullRequests))
+	for _, pr := range a.PullRequests {
+		s.CodeAdditions += pr.Additions
+		s.CodeDeletions += pr.Deletions
+		prSizes = append(prSizes, pr.Additions+pr.Deletions)
+
+		r := repo(pr.Repo)
+		r.PRs++
+		r.Additions += pr.Additions
+		r.Deletions += pr.Deletions
+		if pr.MergedAt != nil {
+			s.MergedPRs++
+			r.MergedPRs++
+		}
+		day(pr.CreatedAt).PullRequests++
+	}
+
+	for _, issue := range a.Issues {
+		if issue.ClosedAt != nil {
+			s.ClosedIssues++
+		}
+		repo(issue.Repo).Issues++
+		day(issue.CreatedAt).Issues++
+	}
+
+	for _, review := range a.Reviews {
+		repo(review.Repo).Reviews++
+		day(review.CreatedAt).Reviews++
+	}
+
+	countChange := func(c Change) {
+		typ := c.Type
+		if typ == "" {
+			typ = ChangeOther
+		}
+		s.ByChangeType[typ]++
+		if c.Breaking {
+			s.BreakingChanges++
+		}
+	}
+	for _, item := range a.WorkItems {
+		countChange(item.PullRequest.Change)
+		if t := item.Testing(); item.PullRequest.Change.Type == ChangeFeat && t != nil && t.Untested() {
+			s.UntestedFeatures = append(s.UntestedFeatures, fmt.Sprintf("%s#%d %s", item.PullRequest.Repo, item.PullRequest.Number, item.PullRequest.Title))
+		}
+	}
+	for _, c := range a.UnlinkedCommits() {
+		countChange(c.Change)
+	}
+
+	s.NetCodeChanges = s.CodeAdditions - s.CodeDeletions
+	s.ActiveDays = len(s.ByDay)
+	s.MedianPRSize = median(prSizes)
+	if s.TotalPRs > 0 {
+		ratio := float64(s.TotalReviews) / float64(s.TotalPRs)
+		s.ReviewRatio = &ratio
+	}
+
+	return s
+}
+
+func median(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+// TestStatisticsCountsChangesOnce 验证增删行数每项改动只计一次：PR 有行数时以 PR 为准，
+// 已计入 PR 的 commit 和 merge commit 不再累加
+func TestStatisticsCountsChangesOnce(t *testing.T) {
+	activity := &UserActivity{
+		PullRequests: []PullRequestInfo{
+			{Repo: "octo/app", Number: 7, Additions: 100, Deletions: 20},
+		},
+		Commits: []CommitInfo{
+			{SHA: "a1", Repo: "octo/app", PRNumber: 7, Additions: 60, Deletions: 10},
+			{SHA: "a2", Repo: "octo/app", PRNumber: 7, Additions: 40, Deletions: 10},
+			{SHA: "b1", Repo: "octo/app", Additions: 5, Deletions: 1},
+			{SHA: "m1", Repo: "octo/app", IsMerge: true, Additions: 100, Deletions: 20},
+			{SHA: "c1", Repo: "octo/lib", Additions: 3},
+		},
+	}
+	activity.LinkWorkItems()
+
+	s := activity.Statistics(time.UTC)
+	if s.CodeAdditions != 108 || s.CodeDeletions != 21 || s.NetCodeChanges != 87 {
+		t.Errorf("code changes = +%d -%d net %d, want +108 -21 net 87", s.CodeAdditions, s.CodeDeletions, s.NetCodeChanges)
+	}
+	want := map[string][2]int{
+		"octo/app": {105, 21},
+		"octo/lib": {3, 0},
+	}
+	for repo, lines := range want {
+		r := s.ByRepo[repo]
+		if r == nil || r.Additions != lines[0] || r.Deletions != lines[1] {
+			t.Errorf("ByRepo[%s] = %+v, want +%d -%d", repo, r, lines[0], lines[1])
+		}
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	// 东八区的 10-12 00:30 在 UTC 仍是 10-11
+	loc := time.FixedZone("UTC+8", 8*3600)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, loc)
+	}
+	merged := at(13, 18, 0)
+
+	activity := &UserActivity{
+		Commits: []CommitInfo{
+			{SHA: "a1", Repo: "octo/app", Message: "feat: export\n\nlong body", Date: at(12, 0, 30), Additions: 80, Deletions: 20},
+			{SHA: "m1", Repo: "octo/app", Message: "Merge branch 'main'", Date: at(12, 9, 0), IsMerge: true, Additions: 900, Deletions: 300},
+			{SHA: "b1", Repo: "octo/lib", Message: "fix: typo", Date: at(13, 23, 59), Additions: 1, Deletions: 1},
+		},
+		PullRequests: []PullRequestInfo{
+			{Repo: "octo/app", Number: 1, CreatedAt: at(12, 10, 0), Additions: 10, Deletions: 0},
+			{Repo: "octo/app", Number: 2, CreatedAt: at(13, 10, 0), MergedAt: &merged, Additions: 30, Deletions: 20},
+			{Repo: "octo/lib", Number: 3, CreatedAt: at(14, 1, 0), Additions: 200, Deletions: 100},
+			{Repo: "octo/lib", Number: 4, CreatedAt: at(14, 2, 0), Additions: 5, Deletions: 5},
+		},
+		Issues: []IssueInfo{
+			{Repo: "octo/lib", Number: 9, CreatedAt: at(14, 3, 0), ClosedAt: &merged},
+		},
+		Reviews: []ReviewInfo{
+			{Repo: "octo/web", PRNumber: 5, CreatedAt: at(15, 0, 10)},
+			{Repo: "octo/web", PRNumber: 6, CreatedAt: at(15, 8, 0)},
+		},
+	}
+	activity.LinkWorkItems()
+
+	s := activity.Statistics(loc)
+
+	if s.LargestCommit == nil || s.LargestCommit.SHA != "a1" || s.LargestCommit.Message != "feat: export" {
+		t.Errorf("LargestCommit = %+v, want a1 (merge commits skipped, first line only)", s.LargestCommit)
+	}
+	// PR 规模为 10、50、300、10，中位数取中间两个的平均值
+	if s.MedianPRSize != 30 {
+		t.Errorf("MedianPRSize = %d, want 30", s.MedianPRSize)
+	}
+	if s.ReviewRatio == nil || *s.ReviewRatio != 0.5 {
+		t.Errorf("ReviewRatio = %v, want 0.5", s.ReviewRatio)
+	}
+	if s.MergedPRs != 1 || s.ClosedIssues != 1 {
+		t.Errorf("merged PRs = %d, closed issues = %d, want 1 and 1", s.MergedPRs, s.ClosedIssues)
+	}
+
+	wantDays := map[string]DayStats{
+		"2026-10-12": {Commits: 2, PullRequests: 1},
+		"2026-10-13": {Commits: 1, PullRequests: 1},
+		"2026-10-14": {PullRequests: 2, Issues: 1},
+		"2026-10-15": {Reviews: 2},
+	}
+	if len(s.ByDay) != len(wantDays) || s.ActiveDays != len(wantDays) {
+		t.Errorf("ByDay has %d days (active %d), want %d: %v", len(s.ByDay), s.ActiveDays, len(wantDays), s.ByDay)
+	}
+	for day, want := range wantDays {
+		if got := s.ByDay[day]; got == nil || *got != want {
+			t.Errorf("ByDay[%s] = %+v, want %+v", day, got, want)
+		}
+	}
+
+	wantRepos := map[string]RepoStats{
+		"octo/app": {Commits: 2, PRs: 2, MergedPRs: 1, Additions: 120, Deletions: 40},
+		"octo/lib": {Commits: 1, PRs: 2, Issues: 1, Additions: 206, Deletions: 106},
+		"octo/web": {Reviews: 2},
+	}
+	if len(s.ByRepo) != len(wantRepos) {
+		t.Errorf("ByRepo = %v, want %d repos", s.ByRepo, len(wantRepos))
+	}
+	for repo, want := range wantRepos {
+		got := s.ByRepo[repo]
+		if got == nil {
+			t.Errorf("ByRepo[%s] missing", repo)
+			continue
+		}
+		got.Testing = TestingStats{}
+		if *got != want {
+			t.Errorf("ByRepo[%s] = %+v, want %+v", repo, *got, want)
+		}
+	}
+}
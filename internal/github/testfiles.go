@@ -0,0 +1,173 @@
+package github
+
+import (
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// FileChange 是 commit 中一个文件的改动
+type FileChange struct {
+	Path      string
+	Additions int
+	Deletions int
+	Test      bool // 是否为测试代码，由 IsTestFile 判断
+}
+
+// testDirs 是按惯例存放测试代码的目录名
+var testDirs = map[string]bool{
+	"test":        true,
+	"tests":       true,
+	"__tests__":   true,
+	"testdata":    true,
+	"e2e":         true,
+	"androidtest": true,
+}
+
+// specDirs 是 RSpec、Jasmine 等存放测试的目录名。spec/ 也常用来存放接口规范（如 spec/openapi.yaml），
+// 因此只有其中的代码文件才算测试
+var specDirs = map[string]bool{
+	"spec":  true,
+	"specs": true,
+}
+
+// specCodeExts 是 spec 目录下视为测试代码的文件扩展名
+var specCodeExts = map[string]bool{
+	".rb": true, ".js": true, ".jsx": true, ".mjs": true, ".cjs": true, ".ts": true, ".tsx": true,
+	".py": true, ".go": true, ".java": true, ".kt": true, ".scala": true, ".cs": true, ".swift": true,
+	".php": true, ".ex": true, ".exs": true, ".dart": true, ".rs": true, ".c": true, ".cc": true, ".cpp": true,
+}
+
+// testSuffixes 是下划线命名的语言中测试文件的后缀（不区分大小写）
+var testSuffixes = []string{
+	"_test.go",
+	"_test.py", "_tests.py",
+	"_test.rs",
+	"_spec.rb", "_test.rb",
+	"_test.exs",
+	"_test.dart",
+	"_test.c", "_test.cc", "_test.cpp", "_unittest.cc",
+}
+
+// classSuffixes 是按类名命名测试的语言（Java、Kotlin、C#、Swift、PHP 等）中测试文件的后缀，区分大小写，
+// 避免把 Contest.java 之类的文件误判为测试
+var classSuffixes = []string{
+	"Test.java", "Tests.java", "IT.java",
+	"Test.kt", "Tests.kt",
+	"Test.scala", "Spec.scala",
+	"Test.cs", "Tests.cs",
+	"Test.swift", "Tests.swift",
+	"Test.php",
+}
+
+// testInfixes 匹配 foo.test.ts、foo.spec.js、foo.e2e-spec.ts 这类 JavaScript / TypeScript 测试文件
+var testInfixes = []string{".test.", ".spec.", ".e2e.", "-spec.", ".cy."}
+
+// IsTestFile 按各语言的常见约定判断文件是否为测试代码：
+// 测试目录（tests/、__tests__/、spec/ 下的代码文件等）、_test.go、test_*.py、*.spec.ts、*Test.java 等
+func IsTestFile(file string) bool {
+	lower := strings.ToLower(file)
+
+	dirs := strings.Split(path.Dir(lower), "/")
+	for _, d := range dirs {
+		if testDirs[d] || specDirs[d] && specCodeExts[path.Ext(lower)] {
+			return true
+		}
+	}
+
+	base := path.Base(lower)
+	if strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py") {
+		return true
+	}
+	for _, suffix := range testSuffixes {
+		if strings.HasSuffix(base, suffix) && len(base) > len(suffix) {
+			return true
+		}
+	}
+	original := path.Base(file)
+	for _, suffix := range classSuffixes {
+		if strings.HasSuffix(original, suffix) && len(original) > len(suffix) {
+			return true
+		}
+	}
+	for _, infix := range testInfixes {
+		if strings.Contains(base, infix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileChanges 将 GetCommit 返回的文件列表转换为 FileChange 并标注测试文件
+func fileChanges(files []*github.CommitFile) []FileChange {
+	if len(files) == 0 {
+		return nil
+	}
+	result := make([]FileChange, 0, len(files))
+	for _, f := range files {
+		result = append(result, NewFileChange(f.GetFilename(), f.GetAdditions(), f.GetDeletions()))
+	}
+	return result
+}
+
+// NewFileChange 创建一条文件改动记录，并判断是否为测试代码
+func NewFileChange(file string, additions, deletions int) FileChange {
+	return FileChange{
+		Path:      file,
+		Additions: additions,
+		Deletions: deletions,
+		Test:      IsTestFile(file),
+	}
+}
+
+// TestingStats 是「测试纪律」指标：新增的测试代码行数与生产代码行数之比。
+// 只统计带有文件级数据的 commit（部分数据源只提供总增删行数）。
+type TestingStats struct {
+	Commits             int      `json:"commits_with_files"` // 参与统计的 commit 数
+	TestAdditions       int      `json:"test_additions"`
+	ProductionAdditions int      `json:"production_additions"`
+	Ratio               *float64 `json:"test_to_code_ratio,omitempty"` // 每新增一行生产代码对应的测试行数；没有生产代码时为空
+}
+
+// add 累加一个 commit 的文件改动，merge commit 和没有文件数据的 commit 会被跳过
+func (t *TestingStats) add(c CommitInfo) {
+	if c.IsMerge || len(c.Files) == 0 {
+		return
+	}
+	t.Commits++
+	for _, f := range c.Files {
+		if f.Test {
+			t.TestAdditions += f.Additions
+		} else {
+			t.ProductionAdditions += f.Additions
+		}
+	}
+	t.Ratio = nil
+	if t.ProductionAdditions > 0 {
+		ratio := float64(t.TestAdditions) / float64(t.ProductionAdditions)
+		t.Ratio = &ratio
+	}
+}
+
+// Untested 判断是否新增了生产代码却没有任何测试改动
+func (t *TestingStats) Untested() bool {
+	return t.Commits > 0 && t.ProductionAdditions > 0 && t.TestAdditions == 0
+}
+
+// Testing 统计工作项中各 commit 的测试纪律指标，没有文件级数据时返回 nil
+func (w WorkItem) Testing() *TestingStats {
+	return CommitTesting(w.Commits)
+}
+
+// CommitTesting 统计一组 commit 的测试纪律指标，没有文件级数据时返回 nil
+func CommitTesting(commits []CommitInfo) *TestingStats {
+	t := &TestingStats{}
+	for _, c := range commits {
+		t.add(c)
+	}
+	if t.Commits == 0 {
+		return nil
+	}
+	return t
+}
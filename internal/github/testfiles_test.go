@@ -0,0 +1,41 @@
+package github
+
+import "testing"
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		// 测试文件
+		{"internal/github/stats_test.go", true},
+		{"tests/test_api.py", true},
+		{"pkg/test_x.py", true},
+		{"web/src/foo.test.ts", true},
+		{"web/src/app.spec.js", true},
+		{"web/e2e/login.e2e-spec.ts", true},
+		{"src/__tests__/Button.jsx", true},
+		{"spec/models/user_spec.rb", true},
+		{"spec/support/helpers.rb", true},
+		{"src/test/java/com/example/UserServiceTest.java", true},
+		{"app/src/main/java/com/example/ParserTests.kt", true},
+		{"internal/github/testdata/events.json", true},
+		// 非测试文件
+		{"src/main/java/com/example/Contest.java", false},
+		{"spec/openapi.yaml", false},
+		{"docs/specs/design.md", false},
+		{"pkg/latest.go", false},
+		{"pkg/contest_x.py", false},
+		{"src/attest.ts", false},
+		{"_test.go", false},
+		{"README.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := IsTestFile(tt.file); got != tt.want {
+				t.Errorf("IsTestFile(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package github
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // UserActivity represents all GitHub activities for a user
 type UserActivity struct {
@@ -11,6 +15,22 @@ type UserActivity struct {
 	PullRequests []PullRequestInfo
 	Issues       []IssueInfo
 	Reviews      []ReviewInfo
+	Releases     []ReleaseInfo        // 本人发布的 release，只能从事件时间线获取
+	WorkItems    []WorkItem           // 由 LinkWorkItems 构建的 PR -> issue / commit 关系
+	Roadmap      *Roadmap             // 可选：配置了看板或里程碑时才会拉取
+	Repositories map[string]*RepoInfo // 可选：活动涉及的 GitHub 仓库的元数据，键为仓库名
+	Patches      map[string]string    // 可选：重点 PR / commit 的精简 diff，键见 PatchKey
+	// Truncated 表示拉取不完整：有来源失败，或 Events API、Search API 达到了返回上限。
+	// 不完整的数据不能作为该时间范围的快照
+	Truncated bool
+}
+
+// PatchKey 返回 Patches 中 PR（number 非 0）或 commit 的键
+func PatchKey(repo string, number int, sha string) string {
+	if number != 0 {
+		return fmt.Sprintf("%s#%d", strings.ToLower(repo), number)
+	}
+	return strings.ToLower(repo) + "@" + sha
 }
 
 // CommitInfo represents a commit
@@ -23,6 +43,24 @@ type CommitInfo struct {
 	Date      time.Time
 	Additions int
 	Deletions int
+	Refs      []string // 出现过的位置，格式为 owner/repo:branch
+	PatchID   string   // 与空白和行号无关的补丁指纹，用于识别 rebase / cherry-pick 后的同一改动
+
+	Branch          string // 保留的那次出现所在的分支
+	IsDefaultBranch bool   // Branch 是否为仓库默认分支
+	PRNumber        int    // 关联的 PR，0 表示没有
+	PRTitle         string
+	PRURL           string
+	PRMerged        bool
+	IsMerge         bool // merge commit 不计入增删行数
+
+	Change Change       // 由 LinkWorkItems 根据 message 判断的变更类型
+	Files  []FileChange // 逐文件的改动，数据源不提供时为空
+}
+
+// Landed 判断 commit 是否已经进入主线：直接推到默认分支，或所属 PR 已合并
+func (c CommitInfo) Landed() bool {
+	return c.IsDefaultBranch || c.PRMerged
 }
 
 // PullRequestInfo represents a pull request
@@ -37,6 +75,13 @@ type PullRequestInfo struct {
 	Additions int
 	Deletions int
 	Comments  int
+	Milestone string
+
+	HeadSHA        string // 用于把 commit 匹配到 PR，省去逐个 commit 查询
+	MergeCommitSHA string // squash / rebase 合并时默认分支上的 commit
+
+	ClosingIssues []IssueRef // 描述中的关闭关键字和 GraphQL closingIssuesReferences 的合集
+	Change        Change     // 由 LinkWorkItems 根据标题（或其 commit）判断的变更类型
 }
 
 // IssueInfo represents an issue
@@ -49,6 +94,64 @@ type IssueInfo struct {
 	CreatedAt time.Time
 	ClosedAt  *time.Time
 	Comments  int
+	Milestone string
+}
+
+// IssueRef 是对某个 issue 的引用，可能不属于本期拉取到的 Issues
+type IssueRef struct {
+	Repo   string
+	Number int
+	Title  string
+	URL    string
+	State  string
+}
+
+// WorkItem 是以 PR 为中心的工作项：它关闭的 issue 和包含的 commit
+type WorkItem struct {
+	PullRequest  PullRequestInfo
+	ClosedIssues []IssueRef
+	Commits      []CommitInfo
+}
+
+// Roadmap 是本期工作在路线图上的映射
+type Roadmap struct {
+	Projects   []ProjectProgress
+	Milestones []MilestoneProgress
+}
+
+// ProjectProgress 是一个 Projects v2 看板中被该用户推动过的条目
+type ProjectProgress struct {
+	Title string
+	URL   string
+	Items []ProjectItem
+	// Partial 表示看板条目超过 projectItemsMaxPages 页，只检查了前面的部分，Items 可能不完整
+	Partial bool
+}
+
+// ProjectItem 是看板中的一个条目。
+// Projects v2 API 只提供状态字段的当前值和最后修改时间，不提供变更历史，
+// 因此这里只能说明「当前状态是什么、在本期内改过」，不能说明从哪个状态变来
+type ProjectItem struct {
+	Title           string
+	URL             string
+	Repo            string
+	Type            string // ISSUE, PULL_REQUEST, DRAFT_ISSUE
+	Status          string // 当前状态
+	StatusUpdatedAt time.Time
+	Done            bool // 当前状态是否为完成状态
+}
+
+// MilestoneProgress 是里程碑完成度
+type MilestoneProgress struct {
+	Repo         string
+	Title        string
+	URL          string
+	State        string
+	OpenIssues   int
+	ClosedIssues int
+	Percent      float64
+	DueOn        *time.Time
+	UserItems    int // 本期该用户 PR / issue 中属于此里程碑的数量
 }
 
 // ReviewInfo represents a code review
@@ -61,44 +164,69 @@ type ReviewInfo struct {
 	CreatedAt time.Time
 }
 
-// Statistics calculates activity statistics
-func (a *UserActivity) Statistics() map[string]interface{} {
-	totalAdditions := 0
-	totalDeletions := 0
+// ReleaseInfo represents a published release
+type ReleaseInfo struct {
+	Repo        string
+	Tag         string
+	Name        string
+	URL         string
+	Prerelease  bool
+	PublishedAt time.Time
+}
 
-	for _, c := range a.Commits {
-		totalAdditions += c.Additions
-		totalDeletions += c.Deletions
+// Merge 将另一个来源的活动合并进来，重复的 commit 只保留一份，并重新构建工作项
+func (a *UserActivity) Merge(other *UserActivity) {
+	a.Commits = dedupCommits(append(a.Commits, other.Commits...), nil)
+	a.PullRequests = append(a.PullRequests, other.PullRequests...)
+	a.Issues = append(a.Issues, other.Issues...)
+	a.Reviews = append(a.Reviews, other.Reviews...)
+	a.Releases = append(a.Releases, other.Releases...)
+	if a.Roadmap == nil {
+		a.Roadmap = other.Roadmap
 	}
-
-	for _, pr := range a.PullRequests {
-		totalAdditions += pr.Additions
-		totalDeletions += pr.Deletions
+	if a.Repositories == nil {
+		a.Repositories = other.Repositories
 	}
+	if a.Patches == nil {
+		a.Patches = other.Patches
+	}
+	a.Truncated = a.Truncated || other.Truncated
+	a.LinkWorkItems()
+}
 
-	mergedPRs := 0
+// FilterRepos 只保留 keep 返回 true 的仓库中的活动，并重新构建工作项
+func (a *UserActivity) FilterRepos(keep func(repo string) bool) {
+	var commits []CommitInfo
+	for _, c := range a.Commits {
+		if keep(c.Repo) {
+			commits = append(commits, c)
+		}
+	}
+	var prs []PullRequestInfo
 	for _, pr := range a.PullRequests {
-		if pr.MergedAt != nil {
-			mergedPRs++
+		if keep(pr.Repo) {
+			prs = append(prs, pr)
 		}
 	}
-
-	closedIssues := 0
+	var issues []IssueInfo
 	for _, issue := range a.Issues {
-		if issue.ClosedAt != nil {
-			closedIssues++
+		if keep(issue.Repo) {
+			issues = append(issues, issue)
 		}
 	}
-
-	return map[string]interface{}{
-		"total_commits":      len(a.Commits),
-		"total_prs":          len(a.PullRequests),
-		"merged_prs":         mergedPRs,
-		"total_issues":       len(a.Issues),
-		"closed_issues":      closedIssues,
-		"total_reviews":      len(a.Reviews),
-		"code_additions":     totalAdditions,
-		"code_deletions":     totalDeletions,
-		"net_code_changes":   totalAdditions - totalDeletions,
+	var reviews []ReviewInfo
+	for _, review := range a.Reviews {
+		if keep(review.Repo) {
+			reviews = append(reviews, review)
+		}
+	}
+	var releases []ReleaseInfo
+	for _, release := range a.Releases {
+		if keep(release.Repo) {
+			releases = append(releases, release)
+		}
 	}
+
+	a.Commits, a.PullRequests, a.Issues, a.Reviews, a.Releases = commits, prs, issues, reviews, releases
+	a.LinkWorkItems()
 }
@@ -0,0 +1,160 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// closingRefPattern 匹配 PR 描述中的关闭关键字，例如 "Fixes #123"、"Closes org/repo#45"
+// 以及 "Resolves https://github.com/org/repo/issues/7"（GitHub Enterprise 的域名同样适用）
+var closingRefPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+(?:([\w.-]+/[\w.-]+)?#(\d+)|https?://([\w.-]+(?::\d+)?)/([\w.-]+/[\w.-]+)/issues/(\d+))`)
+
+// parseClosingRefs 从 PR 描述中解析关闭的 issue，未写仓库名的引用视为 PR 所在仓库。
+// 链接形式的引用只接受 host（github.com 或 GitHub Enterprise 域名）下的 issue，GitHub 不会关闭其他站点的 issue
+func parseClosingRefs(host, repo, body string) []IssueRef {
+	var refs []IssueRef
+	for _, m := range closingRefPattern.FindAllStringSubmatch(body, -1) {
+		ref := IssueRef{Repo: repo}
+		num := m[2]
+		if m[1] != "" {
+			ref.Repo = m[1]
+		}
+		if m[5] != "" {
+			if !sameHost(m[3], host) {
+				continue
+			}
+			ref.Repo = m[4]
+			num = m[5]
+		}
+		ref.Number, _ = strconv.Atoi(num)
+		if ref.Number == 0 {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return mergeIssueRefs(nil, refs)
+}
+
+// sameHost 判断链接中的主机（可能带端口）是否为 host，忽略大小写和 www. 前缀
+func sameHost(linkHost, host string) bool {
+	name, _, _ := strings.Cut(linkHost, ":")
+	return strings.EqualFold(strings.TrimPrefix(strings.ToLower(name), "www."), host)
+}
+
+// mergeIssueRefs 合并两组 issue 引用，后者中信息更完整的条目（有标题）覆盖前者
+func mergeIssueRefs(a, b []IssueRef) []IssueRef {
+	var result []IssueRef
+	index := make(map[string]int)
+	for _, ref := range append(append([]IssueRef{}, a...), b...) {
+		key := ref.Key()
+		if i, ok := index[key]; ok {
+			if ref.Title != "" {
+				result[i] = ref
+			}
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, ref)
+	}
+	return result
+}
+
+// Key 返回 owner/repo#number 形式的唯一标识
+func (r IssueRef) Key() string {
+	return fmt.Sprintf("%s#%d", strings.ToLower(r.Repo), r.Number)
+}
+
+// LinkWorkItems 构建工作项关系图：每个 PR 关联其关闭的 issue，每个 commit 关联其所属 PR。
+// 用户自己创建的 PR 优先；commit 所属 PR 由他人创建时，也会根据 commit 上的 PR 信息生成工作项。
+// 同时为每个 commit 和 PR 标注变更类型。
+func (a *UserActivity) LinkWorkItems() {
+	for i := range a.Commits {
+		a.Commits[i].Change = ClassifyChange(a.Commits[i].Message)
+	}
+
+	a.WorkItems = nil
+	index := make(map[string]int)
+
+	prKey := func(repo string, number int) string {
+		return fmt.Sprintf("%s#%d", strings.ToLower(repo), number)
+	}
+
+	for _, pr := range a.PullRequests {
+		index[prKey(pr.Repo, pr.Number)] = len(a.WorkItems)
+		a.WorkItems = append(a.WorkItems, WorkItem{
+			PullRequest:  pr,
+			ClosedIssues: a.fillIssueRefs(pr.ClosingIssues),
+		})
+	}
+
+	for _, c := range a.Commits {
+		if c.PRNumber == 0 {
+			continue
+		}
+
+		key := prKey(c.Repo, c.PRNumber)
+		i, ok := index[key]
+		if !ok {
+			pr := PullRequestInfo{
+				Number: c.PRNumber,
+				Title:  c.PRTitle,
+				Repo:   c.Repo,
+				URL:    c.PRURL,
+				State:  "open",
+			}
+			if c.PRMerged {
+				pr.State = "merged"
+			}
+			i = len(a.WorkItems)
+			index[key] = i
+			a.WorkItems = append(a.WorkItems, WorkItem{PullRequest: pr})
+		}
+
+		a.WorkItems[i].Commits = append(a.WorkItems[i].Commits, c)
+	}
+
+	// 前 len(a.PullRequests) 个工作项与 a.PullRequests 一一对应
+	for i := range a.WorkItems {
+		item := &a.WorkItems[i]
+		item.PullRequest.Change = classifyPullRequest(item.PullRequest, item.Commits)
+		if i < len(a.PullRequests) {
+			a.PullRequests[i].Change = item.PullRequest.Change
+		}
+	}
+}
+
+// UnlinkedCommits 返回不属于任何 PR 的 commit
+func (a *UserActivity) UnlinkedCommits() []CommitInfo {
+	var result []CommitInfo
+	for _, c := range a.Commits {
+		if c.PRNumber == 0 {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// fillIssueRefs 用本期拉取到的 issue 补全引用中缺失的标题、链接和状态
+func (a *UserActivity) fillIssueRefs(refs []IssueRef) []IssueRef {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	known := make(map[string]IssueInfo, len(a.Issues))
+	for _, issue := range a.Issues {
+		known[IssueRef{Repo: issue.Repo, Number: issue.Number}.Key()] = issue
+	}
+
+	result := make([]IssueRef, 0, len(refs))
+	for _, ref := range refs {
+		if issue, ok := known[ref.Key()]; ok && ref.Title == "" {
+			ref.Title = issue.Title
+			ref.URL = issue.URL
+			ref.State = issue.State
+		}
+		result = append(result, ref)
+	}
+	return result
+}
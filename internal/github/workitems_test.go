@@ -0,0 +1,88 @@
+package github
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseClosingRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		body string
+		want []string
+	}{
+		{"same repository", "github.com", "fixes #1", []string{"octo/app#1"}},
+		{"other repository", "github.com", "Closes owner/repo#2", []string{"owner/repo#2"}},
+		{"keyword with colon", "github.com", "Fix: #6", []string{"octo/app#6"}},
+		{"several refs deduplicated", "github.com", "Fixes #1, closes #4 and resolves Octo/App#1", []string{"octo/app#1", "octo/app#4"}},
+		{"no keyword", "github.com", "prefix #3", nil},
+		{"keyword inside a word", "github.com", "unfixes #3", nil},
+		{"mention only", "github.com", "See #5, related to org/lib#6", nil},
+		{"issue zero", "github.com", "fixes #0", nil},
+		{"github.com link", "github.com", "Resolves https://github.com/org/lib/issues/7", []string{"org/lib#7"}},
+		{"pull request link", "github.com", "Fixes https://github.com/org/lib/pull/8", nil},
+		{"enterprise link", "ghe.example.com", "Fixes https://ghe.example.com/corp/portal/issues/12", []string{"corp/portal#12"}},
+		{"enterprise link with port", "ghe.example.com", "fixes http://GHE.example.com:8443/corp/portal/issues/3", []string{"corp/portal#3"}},
+		{"link to another host", "ghe.example.com", "Fixes https://github.com/org/lib/issues/7", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ref := range parseClosingRefs(tt.host, "octo/app", tt.body) {
+				got = append(got, ref.Key())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parseClosingRefs(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkWorkItems(t *testing.T) {
+	activity := &UserActivity{
+		PullRequests: []PullRequestInfo{{
+			Number:        10,
+			Title:         "fix: login timeout",
+			Repo:          "octo/app",
+			ClosingIssues: parseClosingRefs("github.com", "octo/app", "Fixes #3\n\nCloses https://github.com/octo/lib/issues/9"),
+		}},
+		Issues: []IssueInfo{{Number: 3, Title: "Login times out", Repo: "Octo/App", URL: "https://github.com/octo/app/issues/3", State: "closed"}},
+		Commits: []CommitInfo{
+			{SHA: "a1", Message: "fix: raise timeout", Repo: "octo/app", PRNumber: 10},
+			{SHA: "b1", Message: "feat: export", Repo: "octo/app", PRNumber: 11, PRTitle: "feat: export", PRMerged: true},
+			{SHA: "c1", Message: "chore: bump", Repo: "octo/app"},
+		},
+	}
+
+	activity.LinkWorkItems()
+
+	if len(activity.WorkItems) != 2 {
+		t.Fatalf("work items = %+v, want 2", activity.WorkItems)
+	}
+
+	own := activity.WorkItems[0]
+	var closed []string
+	for _, ref := range own.ClosedIssues {
+		closed = append(closed, ref.Key()+":"+ref.Title)
+	}
+	if want := "[octo/app#3:Login times out octo/lib#9:]"; fmt.Sprint(closed) != want {
+		t.Errorf("closed issues = %v, want %s", closed, want)
+	}
+	if len(own.Commits) != 1 || own.Commits[0].SHA != "a1" {
+		t.Errorf("commits of #10 = %+v, want a1", own.Commits)
+	}
+	if own.PullRequest.Change.Type != ChangeFix || activity.PullRequests[0].Change != own.PullRequest.Change {
+		t.Errorf("change of #10 = %+v, PullRequests copy = %+v, want fix", own.PullRequest.Change, activity.PullRequests[0].Change)
+	}
+
+	other := activity.WorkItems[1]
+	if other.PullRequest.Number != 11 || other.PullRequest.State != "merged" || len(other.Commits) != 1 {
+		t.Errorf("work item from commit = %+v, want merged #11 with b1", other)
+	}
+
+	if unlinked := activity.UnlinkedCommits(); len(unlinked) != 1 || unlinked[0].SHA != "c1" {
+		t.Errorf("unlinked commits = %+v, want c1", unlinked)
+	}
+}
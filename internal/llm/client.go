@@ -10,7 +10,12 @@ import (
 // Client 是 LLM 客户端的接口
 type Client interface {
 	GenerateReport(ctx context.Context, activityData string) (string, error)
-	ExtractGitHubUsername(ctx context.Context, content string) (string, error)
+	// SummarizeRepo 为单个仓库的活动生成中间摘要（map 阶段）
+	SummarizeRepo(ctx context.Context, repoData string) (string, error)
+	// ReduceReport 把各仓库的摘要合成最终报告（reduce 阶段）
+	ReduceReport(ctx context.Context, summaryData string) (string, error)
+	// ExtractIntent 从聊天消息中提取报告意图，返回模型输出的原始 JSON，由 ParseIntent 校验
+	ExtractIntent(ctx context.Context, content string) (string, error)
 }
 
 // NewClient 根据提供商创建一个新的 LLM 客户端
@@ -29,8 +29,13 @@ func NewDeepSeekClient(cfg config.LLMConfig) *DeepSeekClient {
 }
 
 type deepseekRequest struct {
-	Model    string            `json:"model"`
-	Messages []deepseekMessage `json:"messages"`
+	Model          string                  `json:"model"`
+	Messages       []deepseekMessage       `json:"messages"`
+	ResponseFormat *deepseekResponseFormat `json:"response_format,omitempty"`
+}
+
+type deepseekResponseFormat struct {
+	Type string `json:"type"` // text 或 json_object
 }
 
 type deepseekMessage struct {
@@ -47,10 +52,11 @@ type deepseekResponse struct {
 	} `json:"error,omitempty"`
 }
 
-// GenerateReport 使用 DeepSeek 生成报告
-func (c *DeepSeekClient) GenerateReport(ctx context.Context, activityData string) (string, error) {
-	// 直接将活动数据作为用户输入，使用固定的系统提示词
-	systemPrompt := `你是一名「个人 GitHub 技术动态总结助手」。请根据以下 GitHub 活动数据，生成一份**简洁、技术性强、周报风格**的总结。
+// PromptVersion 标识报告提示词的版本，随报告一起存档；修改报告相关的提示词（包括 map-reduce 的两段提示词）时需要递增
+const PromptVersion = "13"
+
+// reportPrompt 是生成报告的系统提示词，GenerateReport 和 ReduceReport 共用
+const reportPrompt = `你是一名「个人 GitHub 技术动态总结助手」。请根据以下 GitHub 活动数据，生成一份**简洁、技术性强、周报风格**的总结。
 
 ### 输出要求
 
@@ -59,25 +65,67 @@ func (c *DeepSeekClient) GenerateReport(ctx context.Context, activityData string
    * 每个项目仅用 **2–3 行**概述本人的主要技术进展。
    * 突出成果、优化点或解决的技术难题。
    * 不要逐条列出 commit/PR 详情，只提最核心的工作。
+   * repositories 给出了仓库的描述、topics、主要语言、star 数和 README 首段。项目标题写成「仓库名（一句话说明项目是做什么的）」，例如「github-reports（飞书周报机器人）」；没有元数据的仓库只写仓库名，不要猜测用途。
 
 2. **总体技术分析**
 
    * 总结近期的主要技术方向（如新功能开发、性能优化、架构演进）。
    * 简要点出代表性的难题及解决方式。
-   * 用数据支撑（commit 数、代码增删行数），但不要展开逐条解释。
+   * 不要在正文中罗列 commit 数、增删行数等统计数字：报告中另有程序生成的统计表、已合并 PR 列表和 release 列表，数字以它们为准。确有必要引用数字时，只能照抄 statistics 中的值。
+   * statistics 中的 active_days、largest_commit、median_pr_size、review_to_authored_ratio 可用于描述工作节奏和规模，by_repo / by_day 可用于判断精力分布。
+   * 提到功能数、修复数等变更类型数量时，只能使用 statistics.by_change_type 和 breaking_changes 中的数字，不要自行数 commit 或估算。
+   * statistics.testing（以及 by_repo 中各仓库、work_streams 中各工作流和 PR 的 testing）给出新增测试代码行数与生产代码行数之比（test_to_code_ratio），可简要评价测试纪律；untested_features 中的功能 PR 新增了生产代码却没有任何测试改动，需要如实指出。没有 testing 数据时不要评价测试情况。
+
+3. **以工作流为单位**
+
+   * work_streams 是程序预先聚类好的工作流：同一个 PR、同一个功能分支、同一个 scope、同一目录或 message 相近的改动已合并为一项，name 是工作流名称，grouped 是聚类依据。
+   * 每个工作流给出汇总数据（commits、additions、deletions、change_types）、相关 PR（pull_requests，含关闭的 issue closes）和代表性的 commit 标题（messages），请按「完成了哪些功能 / 修复了哪些问题」来描述，不要逐条复述 messages。
+   * 部分重要的 PR 或工作流附带了 diff（已去掉锁文件和生成文件并截断）。commit message 含糊（如「fix」「update」）时，请根据 diff 说明实际改了什么，用一句话概括改动的效果，不要贴代码或逐行解读；diff 被截断时不要臆测未展示的部分。
+   * PR 上的 change_type（feat、fix、refactor 等）、scope 和 breaking 由规则判断得出，可据此区分新功能、修复和重构；breaking 为 true 的改动需要明确指出。
+   * work_streams、issues、reviews 已按重要性从高到低排列。输入包含 omitted 时，说明活动较多，次要条目只给出了数量（按类型和仓库），可以用一句话概括（如「另有 12 项较小的改动」），不要臆测其内容。
+
+4. **区分已交付与进行中**
+
+   * status 为 landed 的工作流已进入默认分支（或所属 PR 已合并），可以描述为已完成的成果。
+   * status 为 in_progress 的工作流仍停留在功能分支或未合并的 PR 上，只能描述为「进行中」，不要写成已上线或已交付；mixed 表示部分已合并。
+   * releases 是本期发布的版本，可以作为交付成果提及；prerelease 为 true 的是预发布版本。
+
+5. **路线图进展（仅当输入包含 roadmap 时）**
 
-3. **风格要求**
+   * 在 roadmap 段落中输出「## 路线图进展」一节，把本周工作对应到看板条目和里程碑上。
+   * 看板条目给出的是当前状态（status）和状态最后修改的日期（status_updated），程序只知道状态在本期内被改过，不知道之前是什么状态。done 为 true 的条目当前处于完成状态，可以写成「本期完成」或「本期处于完成状态」，不要编造具体的状态流转过程（如「从进行中移到完成」）。同时给出相关里程碑的完成百分比。
+   * partial 为 true 的看板条目过多，程序只检查了其中一部分，列出的条目不完整；不要把它写成该看板的全部进展，也不要据此推断其他条目没有变化。
+   * 输入中没有 roadmap 时不要输出这一段。
+
+6. **环比变化（仅当输入包含 comparison 时）**
+
+   * comparison 给出了每个统计指标和每个仓库相对上一周期（previous_period）的变化，percent 为变化百分比。
+   * 在「总体技术分析」中用一两句话点出显著变化，例如产出明显增减、新投入或停止投入的仓库（status 为 new / gone），并结合本期工作给出可能的原因。
+   * 不要逐项复述数字，完整的对比表会由程序附在报告中。
+
+7. **时间**
+
+   * 输入中的所有日期都已换算到 timezone 指定的时区，直接使用即可，不要再做时区换算。
+
+8. **篇幅与语言**
+
+   * report_style 为 detailed 时，每个项目可展开到 4–6 行，并补充关键 PR 的链接；为 brief 或未提供时按下方风格要求保持凝练。
+   * output_language 为 en 时整份报告（包括小标题）使用英文；否则使用中文。
+
+9. **风格要求**
 
    * 输出要**高度凝练**，像周会上口头汇报一样简明。
    * 重点在「做了什么」和「技术价值」，而不是「做了多少」。
    * 避免冗长的 commit 描述，保持报告感。
 
-### 输出模板
+### 输出格式
 
----
+报告由程序拼装：标题、统计周期、统计表、已合并 PR 列表和 release 列表由程序生成，你只负责叙述部分。
+请不要输出报告标题和统计周期，按以下标记分段输出，每个标记单独占一行，标记本身原样保留：
 
-# [{username}](https://github.com/{username}) 的 GitHub 一周动态分析
+---
 
+<!-- section: projects -->
 ## 项目A
 - 核心进展 1（简要）
 - 核心进展 2（简要）
@@ -86,10 +134,14 @@ func (c *DeepSeekClient) GenerateReport(ctx context.Context, activityData string
 - 核心进展 1（简要）
 - 核心进展 2（简要）
 
+<!-- section: roadmap -->
+## 路线图进展
+（仅当输入包含 roadmap 时输出这一段，否则连同标记一起省略）
+
+<!-- section: analysis -->
 ## 总体技术分析
 近期主要精力集中在 {方向概述}。  
-代表性难题：{难题简述 + 解决方式}。  
-本期共提交 {C} 个 commits，新增 {A} 行代码，删除 {D} 行代码。
+代表性难题：{难题简述 + 解决方式}。
 
 ---
 
@@ -100,25 +152,25 @@ GitHub 活动数据：
 ---
 `
 
-	userPrompt := activityData
-
-	return c.completeWithSystem(ctx, systemPrompt, userPrompt)
+// GenerateReport 使用 DeepSeek 生成报告
+func (c *DeepSeekClient) GenerateReport(ctx context.Context, activityData string) (string, error) {
+	// 直接将活动数据作为用户输入，使用固定的系统提示词
+	return c.completeWithSystem(ctx, reportPrompt, activityData)
 }
 
-// ExtractGitHubUsername 使用 LLM 从内容中提取 GitHub 用户名
-func (c *DeepSeekClient) ExtractGitHubUsername(ctx context.Context, content string) (string, error) {
-	prompt := fmt.Sprintf(`从以下内容中提取 GitHub 用户名。只返回用户名，不要有任何其他文字。
+// ExtractIntent 使用 LLM 从内容中提取结构化的报告意图（JSON 模式输出）
+func (c *DeepSeekClient) ExtractIntent(ctx context.Context, content string) (string, error) {
+	systemPrompt := `你负责把用户发给周报机器人的消息解析为 JSON。只输出一个符合以下 JSON Schema 的对象，不要输出任何其他文字：
 
-内容：
-%s
+` + intentSchema + `
 
-GitHub 用户名：`, content)
+规则：
+- users：消息中提到的 GitHub 用户名，去掉 @ 和链接前缀；没有则为空数组。
+- org / repo：只有用户明确限定了组织或仓库时才填写，否则为空字符串。
+- period：把「上周」「上个月」「本季度」「最近 30 天」「第 42 个迭代」等说法转换为对应的表达式；未提及则为空字符串。
+- style：用户要求简要 / 概览时为 brief，要求详细 / 展开时为 detailed；未提及则为空字符串。
+- language：用户要求英文报告时为 en，要求中文时为 zh；未提及则为空字符串。`
 
-	return c.complete(ctx, prompt)
-}
-
-// completeWithSystem 使用系统提示词和用户提示词发起补全请求
-func (c *DeepSeekClient) completeWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	baseURL := c.config.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.deepseek.com/v1"
@@ -127,22 +179,17 @@ func (c *DeepSeekClient) completeWithSystem(ctx context.Context, systemPrompt, u
 	reqBody := deepseekRequest{
 		Model: c.config.Model,
 		Messages: []deepseekMessage{
-			{
-				Role:    "system",
-				Content: systemPrompt,
-			},
-			{
-				Role:    "user",
-				Content: userPrompt,
-			},
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: content},
 		},
+		ResponseFormat: &deepseekResponseFormat{Type: "json_object"},
 	}
 
 	return c.doRequest(ctx, baseURL, reqBody)
 }
 
-// complete 是用于发起补全请求的辅助方法
-func (c *DeepSeekClient) complete(ctx context.Context, prompt string) (string, error) {
+// completeWithSystem 使用系统提示词和用户提示词发起补全请求
+func (c *DeepSeekClient) completeWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	baseURL := c.config.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.deepseek.com/v1"
@@ -151,9 +198,13 @@ func (c *DeepSeekClient) complete(ctx context.Context, prompt string) (string, e
 	reqBody := deepseekRequest{
 		Model: c.config.Model,
 		Messages: []deepseekMessage{
+			{
+				Role:    "system",
+				Content: systemPrompt,
+			},
 			{
 				Role:    "user",
-				Content: prompt,
+				Content: userPrompt,
 			},
 		},
 	}
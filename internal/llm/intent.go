@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github-reports/internal/username"
+)
+
+// 报告风格与语言的可选值
+const (
+	StyleBrief    = "brief"
+	StyleDetailed = "detailed"
+
+	LanguageZh = "zh"
+	LanguageEn = "en"
+)
+
+// Intent 是从聊天消息中提取的报告意图
+type Intent struct {
+	Users    []string `json:"users"`    // 目标 GitHub 用户名
+	Org      string   `json:"org"`      // 可选：只统计该组织下的仓库
+	Repo     string   `json:"repo"`     // 可选：只统计该仓库，owner/name
+	Period   string   `json:"period"`   // 周期表达式，如 last-week、last-month、Q3、sprint 42
+	Style    string   `json:"style"`    // brief 或 detailed
+	Language string   `json:"language"` // zh 或 en
+}
+
+// intentSchema 是约束 LLM 输出的 JSON Schema，会原样放入提示词
+const intentSchema = `{
+  "type": "object",
+  "properties": {
+    "users":    {"type": "array", "items": {"type": "string", "pattern": "^[A-Za-z0-9](?:[A-Za-z0-9-]{0,37}[A-Za-z0-9])?$"}},
+    "org":      {"type": "string"},
+    "repo":     {"type": "string", "pattern": "^[A-Za-z0-9-]+/[A-Za-z0-9._-]+$"},
+    "period":   {"type": "string",
+                 "description": "周期表达式，未提及时为空：this-week、last-week、this-month、last-month、this-quarter、last-quarter、this-sprint、last-sprint、last-N-days、YYYY-Www、YYYY-MM、YYYY-Qn、sprint N 或 YYYY-MM-DD..YYYY-MM-DD"},
+    "style":    {"type": "string", "enum": ["", "brief", "detailed"]},
+    "language": {"type": "string", "enum": ["", "zh", "en"]}
+  },
+  "required": ["users", "org", "repo", "period", "style", "language"],
+  "additionalProperties": false
+}`
+
+var (
+	repoPattern = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9._-]+$`)
+)
+
+// ParseIntent 解析并校验 LLM 返回的 JSON。每个字段单独校验，
+// 缺失或不合法的字段使用 defaults 中的值，因此即使输出部分有误也能得到可用的意图。
+// 返回的 invalid 列出被回退的字段名，便于记录日志。
+func ParseIntent(raw string, defaults Intent) (intent Intent, invalid []string) {
+	intent = defaults
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &fields); err != nil {
+		return intent, []string{"*"}
+	}
+
+	str := func(name string) (string, bool) {
+		var v string
+		if err := json.Unmarshal(fields[name], &v); err != nil {
+			return "", false
+		}
+		return strings.TrimSpace(v), true
+	}
+
+	// users 同时接受数组和单个字符串
+	var users []string
+	if err := json.Unmarshal(fields["users"], &users); err != nil {
+		if u, ok := str("users"); ok && u != "" {
+			users = []string{u}
+		}
+	}
+	var validUsers []string
+	for _, u := range users {
+		u = strings.TrimPrefix(strings.TrimSpace(u), "@")
+		if username.ValidLogin(u) {
+			validUsers = append(validUsers, u)
+		}
+	}
+	if len(validUsers) > 0 {
+		intent.Users = validUsers
+	} else if len(users) > 0 {
+		invalid = append(invalid, "users")
+	}
+
+	if v, ok := str("org"); ok && v != "" {
+		if username.ValidLogin(v) {
+			intent.Org = v
+		} else {
+			invalid = append(invalid, "org")
+		}
+	}
+
+	if v, ok := str("repo"); ok && v != "" {
+		if repoPattern.MatchString(v) {
+			intent.Repo = v
+		} else {
+			invalid = append(invalid, "repo")
+		}
+	}
+
+	// 周期表达式由调用方结合时区和迭代配置解析，这里只做基本清理
+	if v, ok := str("period"); ok && v != "" {
+		intent.Period = v
+	}
+
+	if v, ok := str("style"); ok && v != "" {
+		if v = strings.ToLower(v); v == StyleBrief || v == StyleDetailed {
+			intent.Style = v
+		} else {
+			invalid = append(invalid, "style")
+		}
+	}
+
+	if v, ok := str("language"); ok && v != "" {
+		if v = strings.ToLower(v); v == LanguageZh || v == LanguageEn {
+			intent.Language = v
+		} else {
+			invalid = append(invalid, "language")
+		}
+	}
+
+	return intent, invalid
+}
+
+// stripCodeFence 去掉模型偶尔包裹在 JSON 外的 ``` 代码块标记
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	return strings.TrimSpace(s)
+}
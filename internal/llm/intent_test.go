@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIntent(t *testing.T) {
+	defaults := Intent{Users: []string{"octocat"}, Period: "last-week", Style: StyleBrief, Language: LanguageZh}
+
+	tests := []struct {
+		name        string
+		raw         string
+		want        Intent
+		wantInvalid []string
+	}{
+		{
+			name: "valid",
+			raw:  `{"users":["@alice","bob"],"org":"octo","repo":"octo/app","period":"2026-W41","style":"Detailed","language":"en"}`,
+			want: Intent{Users: []string{"alice", "bob"}, Org: "octo", Repo: "octo/app", Period: "2026-W41", Style: StyleDetailed, Language: LanguageEn},
+		},
+		{
+			name:        "bad json",
+			raw:         `{"users": ["alice"`,
+			want:        defaults,
+			wantInvalid: []string{"*"},
+		},
+		{
+			name: "code fence",
+			raw:  "```json\n{\"users\":\"alice\",\"org\":\"\",\"repo\":\"\",\"period\":\"sprint 42\",\"style\":\"\",\"language\":\"\"}\n```",
+			want: Intent{Users: []string{"alice"}, Period: "sprint 42", Style: StyleBrief, Language: LanguageZh},
+		},
+		{
+			name:        "invalid users, org and repo",
+			raw:         `{"users":["-bad-","a b"],"org":"octo_org!","repo":"octo","period":"","style":"","language":""}`,
+			want:        defaults,
+			wantInvalid: []string{"users", "org", "repo"},
+		},
+		{
+			name:        "out-of-enum style and language",
+			raw:         `{"users":[],"org":"","repo":"","period":"last-month","style":"verbose","language":"fr"}`,
+			want:        Intent{Users: []string{"octocat"}, Period: "last-month", Style: StyleBrief, Language: LanguageZh},
+			wantInvalid: []string{"style", "language"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, invalid := ParseIntent(tt.raw, defaults)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("intent = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(invalid, tt.wantInvalid) {
+				t.Errorf("invalid = %v, want %v", invalid, tt.wantInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package llm
+
+import "context"
+
+// repoSummaryPrompt 是 map 阶段的系统提示词：只总结一个仓库，输出供 reduce 阶段使用的中间摘要
+const repoSummaryPrompt = `你是一名 GitHub 技术动态分析助手。以下是某位开发者在**一个仓库**中的活动数据，它是一份大型报告的一部分，你的输出会与其他仓库的摘要合并成最终报告。
+
+### 输出要求
+
+* 用 5–10 条要点总结本人在这个仓库的工作，每条一行，以「- 」开头。
+* 按 work_streams 描述「完成了哪些功能 / 修复了哪些问题 / 做了哪些重构」，并保留关键 PR 的编号和链接。
+* 附带 diff 的 PR 或工作流，请根据 diff 说明实际改了什么，尤其是 commit message 含糊时；不要贴代码。
+* 区分已交付（status 为 landed）和进行中（in_progress / mixed）的工作，进行中的要明确标注。
+* change_type、breaking、testing 等由程序计算得出，可以引用；提到数量时只使用输入中的数字，不要自行估算。
+* 输入包含 omitted 时，用一句话概括被省略的次要条目数量，不要臆测其内容。
+* 不要输出标题、开场白或总结段落，只输出要点。
+* output_language 为 en 时使用英文，否则使用中文。`
+
+// reducePreface 说明 reduce 阶段的输入格式，之后接 reportPrompt 中的完整输出要求
+const reducePreface = `本次输入不是原始活动数据，而是分两步处理的结果：repo_summaries 是已经按仓库总结好的要点（summary），并附有该仓库的统计数据（statistics）。
+statistics、releases、roadmap、comparison 等字段与原始活动数据的含义相同，statistics 中不再重复各仓库和按天的分布。
+输入包含 omitted 时，活动较少的仓库因篇幅只给出了活动数（by_repo），可以用一句话概括，不要臆测其内容。failed_repos 中的仓库摘要生成失败，请在「总体技术分析」末尾用一句话说明这些仓库的内容未能纳入本次报告。
+请把各仓库的要点合并、提炼为最终报告，不要逐条照抄要点。
+
+`
+
+// SummarizeRepo 使用 DeepSeek 为单个仓库生成中间摘要
+func (c *DeepSeekClient) SummarizeRepo(ctx context.Context, repoData string) (string, error) {
+	return c.completeWithSystem(ctx, repoSummaryPrompt, repoData)
+}
+
+// ReduceReport 使用 DeepSeek 把各仓库的摘要合成最终报告，输出格式与 GenerateReport 相同
+func (c *DeepSeekClient) ReduceReport(ctx context.Context, summaryData string) (string, error) {
+	return c.completeWithSystem(ctx, reducePreface+reportPrompt, summaryData)
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github-reports/internal/config"
+)
+
+// contextWindows 是已知模型的上下文长度（token）
+var contextWindows = map[string]int{
+	"deepseek-chat":     65536,
+	"deepseek-reasoner": 65536,
+	"deepseek-coder":    16384,
+}
+
+const (
+	// defaultContextWindow 用于未知模型，取一个保守值
+	defaultContextWindow = 32768
+	// outputReserve 是为模型输出和系统提示词预留的 token 数
+	outputReserve = 12000
+	// maxDefaultBudget 是自动计算预算的上限：输入越长生成越慢，过长的输入容易超过 60 秒的请求超时
+	maxDefaultBudget = 24000
+)
+
+// EstimateTokens 粗略估计文本的 token 数：ASCII 约 4 个字符一个 token，
+// 中文等非 ASCII 字符约一个字符一个 token。只用于预算控制，宁可高估。
+func EstimateTokens(text string) int {
+	ascii := 0
+	other := 0
+	for i := 0; i < len(text); {
+		if text[i] < utf8.RuneSelf {
+			ascii++
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		other++
+		i += size
+	}
+	return ascii/4 + other + 1
+}
+
+// ContextWindow 返回模型的上下文长度，未知模型返回保守的默认值
+func ContextWindow(model string) int {
+	if n, ok := contextWindows[strings.ToLower(model)]; ok {
+		return n
+	}
+	return defaultContextWindow
+}
+
+// InputBudget 返回报告活动数据的 token 预算：优先使用配置的 max_input_tokens，
+// 否则由模型的上下文长度减去输出预留得到
+func InputBudget(cfg config.LLMConfig) int {
+	if cfg.MaxInputTokens > 0 {
+		return cfg.MaxInputTokens
+	}
+	budget := ContextWindow(cfg.Model) - outputReserve
+	if budget > maxDefaultBudget {
+		budget = maxDefaultBudget
+	}
+	return budget
+}
@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"testing"
+
+	"github-reports/internal/config"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 1},
+		{"abcd", 2},
+		{"hello world!", 4},
+		{"周报", 3},
+		{"fix 登录", 4},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestInputBudget(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.LLMConfig
+		want int
+	}{
+		{"configured budget wins", config.LLMConfig{Model: "deepseek-chat", MaxInputTokens: 50000}, 50000},
+		{"large window is capped", config.LLMConfig{Model: "DeepSeek-Chat"}, maxDefaultBudget},
+		{"small window leaves room for output", config.LLMConfig{Model: "deepseek-coder"}, 16384 - outputReserve},
+		{"unknown model uses the default window", config.LLMConfig{Model: "my-model"}, min(defaultContextWindow-outputReserve, maxDefaultBudget)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InputBudget(tt.cfg); got != tt.want {
+				t.Errorf("InputBudget = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
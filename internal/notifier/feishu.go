@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 )
 
@@ -13,16 +14,35 @@ import (
 type FeishuNotifier struct {
 	webhookURL string
 	client     *http.Client
+
+	// 发送图片需要先用自建应用上传拿到 image_key，自定义机器人本身无法上传
+	appID     string
+	appSecret string
+	apiBase   string
 }
 
+var _ ImageNotifier = (*FeishuNotifier)(nil)
+
 // NewFeishuNotifier 创建一个新的飞书通知器
 func NewFeishuNotifier(webhookURL string) *FeishuNotifier {
 	return &FeishuNotifier{
 		webhookURL: webhookURL,
 		client:     &http.Client{},
+		apiBase:    "https://open.feishu.cn/open-apis",
 	}
 }
 
+// SetApp 设置自建应用凭证，设置后才能发送图片
+func (f *FeishuNotifier) SetApp(appID, appSecret string) {
+	f.appID = appID
+	f.appSecret = appSecret
+}
+
+// SupportsImages 判断是否配置了上传图片所需的应用凭证
+func (f *FeishuNotifier) SupportsImages() bool {
+	return f.appID != "" && f.appSecret != ""
+}
+
 type feishuMessage struct {
 	MsgType string `json:"msg_type"`
 	Content struct {
@@ -37,6 +57,11 @@ func (f *FeishuNotifier) Send(ctx context.Context, content string) error {
 	}
 	msg.Content.Text = content
 
+	return f.post(ctx, msg)
+}
+
+// post 向机器人 webhook 发送一条消息
+func (f *FeishuNotifier) post(ctx context.Context, msg interface{}) error {
 	jsonData, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
@@ -63,3 +88,107 @@ func (f *FeishuNotifier) Send(ctx context.Context, content string) error {
 
 	return nil
 }
+
+// SendImage 上传图片并通过机器人发送
+func (f *FeishuNotifier) SendImage(ctx context.Context, name string, png []byte) error {
+	if !f.SupportsImages() {
+		return fmt.Errorf("feishu app_id and app_secret are required to send images")
+	}
+
+	token, err := f.tenantAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	imageKey, err := f.uploadImage(ctx, token, name, png)
+	if err != nil {
+		return err
+	}
+
+	return f.post(ctx, map[string]interface{}{
+		"msg_type": "image",
+		"content":  map[string]string{"image_key": imageKey},
+	})
+}
+
+// tenantAccessToken 获取自建应用的 tenant_access_token
+func (f *FeishuNotifier) tenantAccessToken(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string]string{"app_id": f.appID, "app_secret": f.appSecret})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", f.apiBase+"/auth/v3/tenant_access_token/internal", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		Code              int    `json:"code"`
+		Msg               string `json:"msg"`
+		TenantAccessToken string `json:"tenant_access_token"`
+	}
+	if err := f.doOpenAPI(req, &result); err != nil {
+		return "", fmt.Errorf("failed to get tenant access token: %w", err)
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("failed to get tenant access token: %d %s", result.Code, result.Msg)
+	}
+	return result.TenantAccessToken, nil
+}
+
+// uploadImage 上传消息图片，返回 image_key
+func (f *FeishuNotifier) uploadImage(ctx context.Context, token, name string, png []byte) (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("image_type", "message"); err != nil {
+		return "", err
+	}
+	part, err := w.CreateFormFile("image", name+".png")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(png); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", f.apiBase+"/im/v1/images", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			ImageKey string `json:"image_key"`
+		} `json:"data"`
+	}
+	if err := f.doOpenAPI(req, &result); err != nil {
+		return "", fmt.Errorf("failed to upload image: %w", err)
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("failed to upload image: %d %s", result.Code, result.Msg)
+	}
+	return result.Data.ImageKey, nil
+}
+
+func (f *FeishuNotifier) doOpenAPI(req *http.Request, result interface{}) error {
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Feishu API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, result)
+}
@@ -8,3 +8,12 @@ import (
 type Notifier interface {
 	Send(ctx context.Context, content string) error
 }
+
+// ImageNotifier 是可以发送图片的通知渠道
+type ImageNotifier interface {
+	Notifier
+	// SupportsImages 判断当前配置下能否发送图片
+	SupportsImages() bool
+	// SendImage 发送一张 PNG 图片，name 用作上传时的文件名
+	SendImage(ctx context.Context, name string, png []byte) error
+}
@@ -0,0 +1,53 @@
+package period
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// detectPatterns 在自由文本中查找可以直接交给 Resolve 的周期表达式，按优先级排列
+var detectPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\d{4}-\d{2}-\d{2}\s*(?:\.\.|~|至|到)\s*\d{4}-\d{2}-\d{2}`),
+	regexp.MustCompile(`(?i)\b(?:this|last)-(?:week|month|quarter|sprint)\b`),
+	regexp.MustCompile(`(?i)\blast-\d+-days?\b`),
+	regexp.MustCompile(`最近\s*\d+\s*天`),
+	regexp.MustCompile(`(?i)\b\d{4}-w\d{1,2}\b`),
+	regexp.MustCompile(`(?i)\b(?:\d{4}-)?q[1-4]\b`),
+	regexp.MustCompile(`(?i)\bsprint[\s-]*\d+\b`),
+	regexp.MustCompile(`第\s*\d+\s*个?迭代`),
+}
+
+// monthDetectPattern 同时匹配完整日期，以便跳过「2026-10-01」这样的单个日期，不把它的前半段当成月份
+var monthDetectPattern = regexp.MustCompile(`\b\d{4}-\d{2}(?:-\d{2})?\b`)
+
+// Detect 从消息中找出第一个周期表达式（如「上周」「Q3」「sprint 42」），找不到时返回空字符串。
+// 返回值可以直接传给 Resolve。
+func Detect(text string) string {
+	for _, pattern := range detectPatterns {
+		if m := pattern.FindString(text); m != "" {
+			return m
+		}
+	}
+	for _, m := range monthDetectPattern.FindAllString(text, -1) {
+		if len(m) == len("2006-01") {
+			return m
+		}
+	}
+
+	// 中文别名，长的优先，避免「上一个迭代」被「上个迭代」之外的短词抢先匹配
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		if alias != "" {
+			names = append(names, alias)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	for _, alias := range names {
+		if strings.Contains(text, alias) {
+			return alias
+		}
+	}
+
+	return ""
+}
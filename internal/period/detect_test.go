@@ -0,0 +1,27 @@
+package period
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"给我 2026-09 的月报", "2026-09"},
+		{"2026-10-01 的周报", ""},
+		{"2026-10-01 之后，看看 2026-09", "2026-09"},
+		{"2026-10-01..2026-10-15 的报告", "2026-10-01..2026-10-15"},
+		{"2026-W41 周报", "2026-W41"},
+		{"sprint 42 总结", "sprint 42"},
+		{"上一个迭代做了什么", "上一个迭代"},
+		{"随便聊聊", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+// Package period 将「上周」「本月」「Q3」「sprint 42」等命名周期解析为具体的起止时间
+package period
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default 是未指定周期时使用的表达式
+const Default = "last-7-days"
+
+// 周期类型
+const (
+	KindRolling = "rolling" // 截至当前的最近 N 天
+	KindWeek    = "week"    // ISO 周，周一至周日
+	KindMonth   = "month"
+	KindQuarter = "quarter"
+	KindSprint  = "sprint"
+	KindCustom  = "custom" // 显式指定的日期范围
+)
+
+// Period 是解析后的统计周期，Since 和 Until 都包含在内
+type Period struct {
+	Kind  string
+	Label string // 用于报告标题，如「2026 年第 41 周」
+	Since time.Time
+	Until time.Time
+
+	number   int  // sprint 编号
+	complete bool // 周期已完整结束（而非截至当前的部分周期）
+}
+
+// Complete 判断周期是否已经完整结束，进行中的周期截至当前
+func (p Period) Complete() bool {
+	return p.complete
+}
+
+// Sprint 描述固定节奏的迭代：第 FirstNumber 个迭代从 Start 开始，每个迭代 Length 天
+type Sprint struct {
+	Start       time.Time
+	Length      int
+	FirstNumber int
+}
+
+// Resolver 在指定时区内解析周期表达式
+type Resolver struct {
+	loc    *time.Location
+	sprint *Sprint
+	now    func() time.Time
+}
+
+// NewResolver 创建一个解析器；sprint 为 nil 时不支持迭代相关的表达式
+func NewResolver(loc *time.Location, sprint *Sprint) *Resolver {
+	return &Resolver{loc: loc, sprint: sprint, now: time.Now}
+}
+
+var (
+	lastNDaysPattern = regexp.MustCompile(`^(?:last-(\d+)-days?|最近\s*(\d+)\s*天)$`)
+	isoWeekPattern   = regexp.MustCompile(`^(\d{4})-w(\d{1,2})$`)
+	monthPattern     = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+	quarterPattern   = regexp.MustCompile(`^(?:(\d{4})-)?q([1-4])$`)
+	sprintPattern    = regexp.MustCompile(`^(?:sprint[\s-]*|第\s*)(\d+)(?:\s*个?迭代)?$`)
+	rangePattern     = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\s*(?:\.\.|~|至|到)\s*(\d{4}-\d{2}-\d{2})$`)
+)
+
+// aliases 将常见的中文说法映射为规范表达式
+var aliases = map[string]string{
+	"":      Default,
+	"本周":    "this-week",
+	"这周":    "this-week",
+	"上周":    "last-week",
+	"上一周":   "last-week",
+	"本月":    "this-month",
+	"这个月":   "this-month",
+	"上月":    "last-month",
+	"上个月":   "last-month",
+	"本季度":   "this-quarter",
+	"这个季度":  "this-quarter",
+	"上季度":   "last-quarter",
+	"上个季度":  "last-quarter",
+	"本迭代":   "this-sprint",
+	"当前迭代":  "this-sprint",
+	"上个迭代":  "last-sprint",
+	"上一个迭代": "last-sprint",
+}
+
+// Resolve 将表达式解析为周期。支持：
+//
+//	last-7-days / 最近7天            截至当前的最近 N 天（默认）
+//	this-week / last-week / 2026-W41 ISO 周
+//	this-month / last-month / 2026-09
+//	this-quarter / last-quarter / Q3 / 2026-Q3
+//	this-sprint / last-sprint / sprint 42
+//	2026-10-01..2026-10-15           自定义日期范围
+func (r *Resolver) Resolve(expr string) (Period, error) {
+	p, err := r.resolve(expr)
+	if err != nil {
+		return Period{}, err
+	}
+	if p.Since.After(r.now()) {
+		return Period{}, fmt.Errorf("period %s has not started yet", p.Label)
+	}
+	return p, nil
+}
+
+// Check 判断表达式能否解析，不要求周期已经开始，用于启动时校验配置
+func (r *Resolver) Check(expr string) error {
+	_, err := r.resolve(expr)
+	return err
+}
+
+func (r *Resolver) resolve(expr string) (Period, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	if alias, ok := aliases[expr]; ok {
+		expr = alias
+	}
+
+	now := r.now().In(r.loc)
+	today := startOfDay(now)
+
+	switch expr {
+	case "this-week":
+		return r.week(startOfISOWeek(today), now), nil
+	case "last-week":
+		return r.week(startOfISOWeek(today).AddDate(0, 0, -7), now), nil
+	case "this-month":
+		return r.month(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, r.loc), now), nil
+	case "last-month":
+		return r.month(time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, r.loc), now), nil
+	case "this-quarter":
+		return r.quarter(startOfQuarter(now), now), nil
+	case "last-quarter":
+		return r.quarter(startOfQuarter(now).AddDate(0, -3, 0), now), nil
+	case "this-sprint", "last-sprint":
+		if r.sprint == nil {
+			return Period{}, fmt.Errorf("sprint cadence is not configured")
+		}
+		n := r.sprintNumberAt(now)
+		if expr == "last-sprint" {
+			n--
+		}
+		return r.sprintPeriod(n, now)
+	}
+
+	if m := lastNDaysPattern.FindStringSubmatch(expr); m != nil {
+		days, _ := strconv.Atoi(m[1] + m[2])
+		if days <= 0 {
+			return Period{}, fmt.Errorf("invalid period: %s", expr)
+		}
+		return Period{
+			Kind:  KindRolling,
+			Label: fmt.Sprintf("最近 %d 天", days),
+			Since: now.AddDate(0, 0, -days),
+			Until: now,
+		}, nil
+	}
+
+	if m := isoWeekPattern.FindStringSubmatch(expr); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		if week < 1 || week > 53 {
+			return Period{}, fmt.Errorf("invalid ISO week: %s", expr)
+		}
+		// 1 月 4 日总在第 1 周内
+		start := startOfISOWeek(time.Date(year, 1, 4, 0, 0, 0, 0, r.loc)).AddDate(0, 0, (week-1)*7)
+		// 只有部分年份有第 53 周，其余年份的 W53 会落到下一年的第 1 周
+		if _, w := start.ISOWeek(); w != week {
+			return Period{}, fmt.Errorf("%d has no ISO week %d", year, week)
+		}
+		return r.week(start, now), nil
+	}
+
+	if m := monthPattern.FindStringSubmatch(expr); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return Period{}, fmt.Errorf("invalid month: %s", expr)
+		}
+		return r.month(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, r.loc), now), nil
+	}
+
+	if m := quarterPattern.FindStringSubmatch(expr); m != nil {
+		year := now.Year()
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		q, _ := strconv.Atoi(m[2])
+		return r.quarter(time.Date(year, time.Month((q-1)*3+1), 1, 0, 0, 0, 0, r.loc), now), nil
+	}
+
+	if m := sprintPattern.FindStringSubmatch(expr); m != nil {
+		if r.sprint == nil {
+			return Period{}, fmt.Errorf("sprint cadence is not configured")
+		}
+		n, _ := strconv.Atoi(m[1])
+		return r.sprintPeriod(n, now)
+	}
+
+	if m := rangePattern.FindStringSubmatch(expr); m != nil {
+		since, err1 := time.ParseInLocation("2006-01-02", m[1], r.loc)
+		until, err2 := time.ParseInLocation("2006-01-02", m[2], r.loc)
+		if err1 != nil || err2 != nil || until.Before(since) {
+			return Period{}, fmt.Errorf("invalid date range: %s", expr)
+		}
+		return Period{
+			Kind:     KindCustom,
+			Label:    fmt.Sprintf("%s ~ %s", m[1], m[2]),
+			Since:    since,
+			Until:    endOf(until.AddDate(0, 0, 1)),
+			complete: true,
+		}, nil
+	}
+
+	return Period{}, fmt.Errorf("unrecognized period: %s", expr)
+}
+
+// Previous 返回紧邻的上一个同类周期。
+// 对于截至当前的部分周期（如本周到今天为止），返回上一周期中相同长度的部分，便于做环比。
+// 没有上一周期（如第一个迭代）时返回 false。
+func (r *Resolver) Previous(p Period) (Period, bool) {
+	var prev Period
+	switch p.Kind {
+	case KindWeek:
+		prev = r.week(p.Since.AddDate(0, 0, -7), p.Since)
+	case KindMonth:
+		prev = r.month(p.Since.AddDate(0, -1, 0), p.Since)
+	case KindQuarter:
+		prev = r.quarter(p.Since.AddDate(0, -3, 0), p.Since)
+	case KindSprint:
+		var err error
+		if prev, err = r.sprintPeriod(p.number-1, p.Since); err != nil {
+			return Period{}, false
+		}
+	default:
+		span := p.Until.Sub(p.Since)
+		prev = Period{
+			Kind:     p.Kind,
+			Since:    p.Since.Add(-span),
+			Until:    p.Since.Add(-time.Nanosecond),
+			complete: true,
+		}
+		prev.Label = fmt.Sprintf("%s ~ %s", prev.Since.Format("2006-01-02"), prev.Until.Format("2006-01-02"))
+		return prev, true
+	}
+
+	if !p.complete {
+		if until := prev.Since.Add(p.Until.Sub(p.Since)); until.Before(prev.Until) {
+			prev.Until = until
+			prev.complete = false
+		}
+	}
+	return prev, true
+}
+
+// week 返回从 start 开始的 ISO 周，截止时间不超过 now
+func (r *Resolver) week(start, now time.Time) Period {
+	year, week := start.ISOWeek()
+	return r.bounded(Period{
+		Kind:  KindWeek,
+		Label: fmt.Sprintf("%d 年第 %d 周", year, week),
+		Since: start,
+	}, start.AddDate(0, 0, 7), now)
+}
+
+func (r *Resolver) month(start, now time.Time) Period {
+	return r.bounded(Period{
+		Kind:  KindMonth,
+		Label: fmt.Sprintf("%d 年 %d 月", start.Year(), start.Month()),
+		Since: start,
+	}, start.AddDate(0, 1, 0), now)
+}
+
+func (r *Resolver) quarter(start, now time.Time) Period {
+	return r.bounded(Period{
+		Kind:  KindQuarter,
+		Label: fmt.Sprintf("%d 年 Q%d", start.Year(), (int(start.Month())-1)/3+1),
+		Since: start,
+	}, start.AddDate(0, 3, 0), now)
+}
+
+func (r *Resolver) sprintPeriod(n int, now time.Time) (Period, error) {
+	if r.sprint.Length <= 0 {
+		return Period{}, fmt.Errorf("sprint length must be positive")
+	}
+	if n < r.sprint.FirstNumber {
+		return Period{}, fmt.Errorf("sprint %d is before the first configured sprint %d", n, r.sprint.FirstNumber)
+	}
+
+	start := startOfDay(r.sprint.Start.In(r.loc)).AddDate(0, 0, (n-r.sprint.FirstNumber)*r.sprint.Length)
+	p := r.bounded(Period{
+		Kind:   KindSprint,
+		Label:  fmt.Sprintf("Sprint %d", n),
+		Since:  start,
+		number: n,
+	}, start.AddDate(0, 0, r.sprint.Length), now)
+	return p, nil
+}
+
+// sprintNumberAt 返回 t 所在迭代的编号
+func (r *Resolver) sprintNumberAt(t time.Time) int {
+	days := calendarDays(r.sprint.Start.In(r.loc), t.In(r.loc))
+	if days < 0 {
+		return r.sprint.FirstNumber
+	}
+	return r.sprint.FirstNumber + days/r.sprint.Length
+}
+
+// calendarDays 返回从 from 所在日期到 to 所在日期相隔的天数。
+// 按 UTC 的日期计算，不受夏令时切换当天只有 23 或 25 小时的影响
+func calendarDays(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	a := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// bounded 设置周期的结束时间：周期已结束时为 end 前的最后一刻，否则截至 now
+func (r *Resolver) bounded(p Period, end, now time.Time) Period {
+	if now.Before(end) {
+		p.Until = now
+		return p
+	}
+	p.Until = endOf(end)
+	p.complete = true
+	return p
+}
+
+// endOf 返回 next 之前的最后一刻，使 Until 落在周期的最后一天
+func endOf(next time.Time) time.Time {
+	return next.Add(-time.Nanosecond)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfISOWeek 返回 t 所在 ISO 周的周一零点
+func startOfISOWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // 周一为 0
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func startOfQuarter(t time.Time) time.Time {
+	month := time.Month((int(t.Month())-1)/3*3 + 1)
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,85 @@
+package period
+
+import (
+	"testing"
+	"time"
+)
+
+// newSprintResolver 返回美东时区、从 2026-03-02 开始每周一个迭代的解析器，当前时间为当地的 now
+func newSprintResolver(t *testing.T, now time.Time) *Resolver {
+	t.Helper()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	r := NewResolver(loc, &Sprint{
+		Start:       time.Date(2026, 3, 2, 0, 0, 0, 0, loc),
+		Length:      7,
+		FirstNumber: 1,
+	})
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, loc)
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func TestThisSprintAcrossDST(t *testing.T) {
+	// 2026-03-08 美东进入夏令时，当天只有 23 小时；03-16 是第 3 个迭代的第一天
+	r := newSprintResolver(t, time.Date(2026, 3, 16, 0, 30, 0, 0, time.UTC))
+
+	p, err := r.Resolve("this-sprint")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if p.Label != "Sprint 3" {
+		t.Errorf("this-sprint = %s, want Sprint 3", p.Label)
+	}
+}
+
+func TestPreviousOfFirstSprint(t *testing.T) {
+	r := newSprintResolver(t, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+
+	p, err := r.Resolve("sprint 1")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if prev, ok := r.Previous(p); ok {
+		t.Errorf("Previous(Sprint 1) = %+v, want none", prev)
+	}
+
+	p, err = r.Resolve("last-week")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if _, ok := r.Previous(p); !ok {
+		t.Errorf("Previous(%s) reported no previous period", p.Label)
+	}
+}
+
+func TestISOWeek53(t *testing.T) {
+	r := NewResolver(time.UTC, nil)
+	r.now = func() time.Time { return time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{"2026-W53", "2026 年第 53 周", false}, // 2026-01-01 是周四，有第 53 周
+		{"2025-W53", "", true},
+		{"2026-W01", "2026 年第 1 周", false},
+		{"2026-W54", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := r.Resolve(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve(%s) error = %v, want error %v", tt.expr, err, tt.wantErr)
+			}
+			if err == nil && p.Label != tt.want {
+				t.Errorf("Resolve(%s) = %s, want %s", tt.expr, p.Label, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package period
+
+import (
+	"fmt"
+	"time"
+)
+
+// Walk 按 kind（week、month、quarter、sprint）切分 [from, to]，返回依次相连的周期。
+// 第一个周期是包含 from 的那一个，最后一个是包含 to 的那一个；尚未开始的周期不会返回，
+// 进行中的周期截至当前。
+func (r *Resolver) Walk(kind string, from, to time.Time) ([]Period, error) {
+	now := r.now().In(r.loc)
+	from, to = from.In(r.loc), to.In(r.loc)
+	if to.After(now) {
+		to = now
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s is after %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	var next func(i int) (Period, error)
+	switch kind {
+	case KindWeek:
+		start := startOfISOWeek(from)
+		next = func(i int) (Period, error) { return r.week(start.AddDate(0, 0, 7*i), now), nil }
+	case KindMonth:
+		start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, r.loc)
+		next = func(i int) (Period, error) { return r.month(start.AddDate(0, i, 0), now), nil }
+	case KindQuarter:
+		start := startOfQuarter(from)
+		next = func(i int) (Period, error) { return r.quarter(start.AddDate(0, 3*i, 0), now), nil }
+	case KindSprint:
+		if r.sprint == nil {
+			return nil, fmt.Errorf("sprint cadence is not configured")
+		}
+		first := r.sprintNumberAt(from)
+		next = func(i int) (Period, error) { return r.sprintPeriod(first+i, now) }
+	default:
+		return nil, fmt.Errorf("unsupported period kind: %s", kind)
+	}
+
+	var periods []Period
+	for i := 0; ; i++ {
+		p, err := next(i)
+		if err != nil {
+			return nil, err
+		}
+		if p.Since.After(to) {
+			break
+		}
+		periods = append(periods, p)
+		if !p.complete {
+			break
+		}
+	}
+	return periods, nil
+}
@@ -0,0 +1,103 @@
+package period
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWalk(t *testing.T) {
+	// 当前时间为美东 2026-04-15 12:00，第 7 个迭代（04-13 ~ 04-19）进行中
+	r := newSprintResolver(t, time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC))
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 0, 0, 0, 0, r.loc)
+	}
+
+	tests := []struct {
+		name     string
+		kind     string
+		from, to time.Time
+		want     []string // Label 和起始日期
+		lastOpen bool     // 最后一个周期是否进行中
+	}{
+		{
+			name: "weeks",
+			kind: KindWeek,
+			from: day(3, 4), to: day(3, 17),
+			want: []string{"2026 年第 10 周 2026-03-02", "2026 年第 11 周 2026-03-09", "2026 年第 12 周 2026-03-16"},
+		},
+		{
+			name: "months up to now",
+			kind: KindMonth,
+			from: day(2, 20), to: day(12, 31),
+			want:     []string{"2026 年 2 月 2026-02-01", "2026 年 3 月 2026-03-01", "2026 年 4 月 2026-04-01"},
+			lastOpen: true,
+		},
+		{
+			name: "quarters",
+			kind: KindQuarter,
+			from: time.Date(2025, 11, 5, 0, 0, 0, 0, r.loc), to: day(4, 1),
+			want:     []string{"2025 年 Q4 2025-10-01", "2026 年 Q1 2026-01-01", "2026 年 Q2 2026-04-01"},
+			lastOpen: true,
+		},
+		{
+			name: "sprints",
+			kind: KindSprint,
+			from: day(3, 31), to: day(4, 15),
+			want:     []string{"Sprint 5 2026-03-30", "Sprint 6 2026-04-06", "Sprint 7 2026-04-13"},
+			lastOpen: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods, err := r.Walk(tt.kind, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Walk: %v", err)
+			}
+			var got []string
+			for i, p := range periods {
+				got = append(got, p.Label+" "+p.Since.Format("2006-01-02"))
+				if i > 0 && !p.Since.Equal(periods[i-1].Until.Add(time.Nanosecond)) {
+					t.Errorf("%s does not start right after %s", p.Label, periods[i-1].Label)
+				}
+				open := i == len(periods)-1 && tt.lastOpen
+				if p.Complete() == open {
+					t.Errorf("%s complete = %v, want %v", p.Label, p.Complete(), !open)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("periods = %v, want %v", got, tt.want)
+			}
+			if last := periods[len(periods)-1]; tt.lastOpen && !last.Until.Equal(r.now()) {
+				t.Errorf("in-progress period ends at %s, want now", last.Until)
+			}
+		})
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	r := newSprintResolver(t, time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC))
+	noSprint := NewResolver(r.loc, nil)
+	noSprint.now = r.now
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, r.loc)
+
+	tests := []struct {
+		name     string
+		r        *Resolver
+		kind     string
+		from, to time.Time
+	}{
+		{"to before from", r, KindWeek, from, from.AddDate(0, 0, -1)},
+		{"from in the future", r, KindWeek, from.AddDate(1, 0, 0), from.AddDate(1, 1, 0)},
+		{"unsupported kind", r, "year", from, from.AddDate(0, 1, 0)},
+		{"sprint without cadence", noSprint, KindSprint, from, from.AddDate(0, 1, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if periods, err := tt.r.Walk(tt.kind, tt.from, tt.to); err == nil {
+				t.Errorf("Walk = %v, want an error", periods)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package render 把活动数据绘制为图表，同时输出 SVG（嵌入 HTML）和 PNG（发送到聊天工具）。
+// 只依赖纯 Go 的绘图库，PNG 中的文字使用内置的等宽点阵字体，因此图表标签只使用 ASCII。
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// 文字尺寸与 basicfont.Face7x13 一致，SVG 使用同样的度量，保证两种格式布局相同
+const (
+	charWidth  = 7
+	lineHeight = 13
+	fontAscent = 11
+)
+
+// 文字对齐方式
+const (
+	alignStart = iota
+	alignEnd
+)
+
+// canvas 是图表绘制的最小接口，SVG 和 PNG 各有一个实现
+type canvas interface {
+	rect(x, y, w, h int, fill color.RGBA)
+	// text 在 (x, y) 处绘制单行文字，y 为文字顶部
+	text(x, y int, s string, fill color.RGBA, align int)
+}
+
+func textWidth(s string) int {
+	return len([]rune(s)) * charWidth
+}
+
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func newSVGCanvas(width, height int, background color.RGBA) *svgCanvas {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="12">`, width, height, width, height)
+	c.rect(0, 0, width, height, background)
+	return c
+}
+
+func (c *svgCanvas) rect(x, y, w, h int, fill color.RGBA) {
+	fmt.Fprintf(&c.buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, x, y, w, h, hexColor(fill))
+}
+
+func (c *svgCanvas) text(x, y int, s string, fill color.RGBA, align int) {
+	anchor := "start"
+	if align == alignEnd {
+		anchor = "end"
+	}
+	fmt.Fprintf(&c.buf, `<text x="%d" y="%d" fill="%s" text-anchor="%s">%s</text>`, x, y+fontAscent, hexColor(fill), anchor, html.EscapeString(s))
+}
+
+func (c *svgCanvas) bytes() []byte {
+	return append(c.buf.Bytes(), "</svg>"...)
+}
+
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func newPNGCanvas(width, height int, background color.RGBA) *pngCanvas {
+	c := &pngCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+	c.rect(0, 0, width, height, background)
+	return c
+}
+
+func (c *pngCanvas) rect(x, y, w, h int, fill color.RGBA) {
+	draw.Draw(c.img, image.Rect(x, y, x+w, y+h), &image.Uniform{C: fill}, image.Point{}, draw.Src)
+}
+
+func (c *pngCanvas) text(x, y int, s string, fill color.RGBA, align int) {
+	s = asciiOnly(s)
+	if align == alignEnd {
+		x -= textWidth(s)
+	}
+	d := &font.Drawer{
+		Dst:  c.img,
+		Src:  &image.Uniform{C: fill},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y+fontAscent),
+	}
+	d.DrawString(s)
+}
+
+func (c *pngCanvas) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// asciiOnly 把点阵字体无法显示的字符替换为 ?
+func asciiOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// truncate 把过长的标签截断到 max 个字符
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "~"
+}
@@ -0,0 +1,276 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+var (
+	colorBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorText       = color.RGBA{0x24, 0x29, 0x2f, 0xff}
+	colorMuted      = color.RGBA{0x65, 0x6d, 0x76, 0xff}
+	colorBar        = color.RGBA{0x09, 0x69, 0xda, 0xff}
+	colorAdditions  = color.RGBA{0x2d, 0xa4, 0x4e, 0xff}
+	colorDeletions  = color.RGBA{0xcf, 0x22, 0x2e, 0xff}
+
+	// heatmapLevels 与 GitHub 贡献图的配色相同，从无活动到最活跃
+	heatmapLevels = []color.RGBA{
+		{0xeb, 0xed, 0xf0, 0xff},
+		{0x9b, 0xe9, 0xa8, 0xff},
+		{0x40, 0xc4, 0x63, 0xff},
+		{0x30, 0xa1, 0x4e, 0xff},
+		{0x21, 0x6e, 0x39, 0xff},
+	}
+)
+
+// maxBars 是柱状图最多显示的仓库数
+const maxBars = 10
+
+// Image 是一张渲染好的图表
+type Image struct {
+	Name  string // 文件名（不含扩展名），如 heatmap
+	Title string
+	SVG   []byte
+	PNG   []byte
+}
+
+// chart 描述一张图：尺寸和绘制过程，同一个 draw 分别画到 SVG 和 PNG 上
+type chart struct {
+	name, title   string
+	width, height int
+	draw          func(c canvas)
+}
+
+// Render 为活动数据生成所有图表，日期按 loc 归到自然日。没有数据的图表会被跳过。
+func Render(activity *github.UserActivity, loc *time.Location) ([]Image, error) {
+	stats := activity.Statistics(loc)
+	var charts []chart
+	charts = append(charts, heatmap(activity, stats, loc))
+	if c, ok := repoCommits(stats); ok {
+		charts = append(charts, c)
+	}
+	if c, ok := lineChanges(stats); ok {
+		charts = append(charts, c)
+	}
+
+	images := make([]Image, 0, len(charts))
+	for _, ch := range charts {
+		svg := newSVGCanvas(ch.width, ch.height, colorBackground)
+		ch.draw(svg)
+
+		p := newPNGCanvas(ch.width, ch.height, colorBackground)
+		ch.draw(p)
+		pngData, err := p.bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, Image{
+			Name:  ch.name,
+			Title: ch.title,
+			SVG:   svg.bytes(),
+			PNG:   pngData,
+		})
+	}
+	return images, nil
+}
+
+// heatmap 绘制 GitHub 风格的每日贡献热力图：每列一周，每行一个星期几
+func heatmap(activity *github.UserActivity, stats *github.Stats, loc *time.Location) chart {
+	days := stats.ByDay
+
+	start := startOfWeek(activity.Since.In(loc))
+	end := activity.Until.In(loc)
+	weeks := int(end.Sub(start).Hours()/24/7) + 1
+
+	const (
+		cell   = 11
+		gap    = 3
+		left   = 36
+		top    = 36
+		bottom = 28
+	)
+	count := func(day time.Time) int {
+		if d := days[day.Format("2006-01-02")]; d != nil {
+			return d.Total()
+		}
+		return 0
+	}
+	max := 0
+	for _, d := range days {
+		if d.Total() > max {
+			max = d.Total()
+		}
+	}
+
+	width := left + weeks*(cell+gap) + 16
+	if width < 260 {
+		width = 260
+	}
+	height := top + 7*(cell+gap) + bottom
+
+	return chart{
+		name:   "heatmap",
+		title:  "Contributions",
+		width:  width,
+		height: height,
+		draw: func(c canvas) {
+			c.text(8, 8, "Contributions per day", colorText, alignStart)
+			for i, label := range []string{"Mon", "", "Wed", "", "Fri", "", "Sun"} {
+				if label != "" {
+					c.text(4, top+i*(cell+gap)-1, label, colorMuted, alignStart)
+				}
+			}
+
+			for w := 0; w < weeks; w++ {
+				for d := 0; d < 7; d++ {
+					day := start.AddDate(0, 0, w*7+d)
+					if day.After(end) {
+						continue
+					}
+					fill := heatmapLevels[0]
+					if !day.Before(startOfDay(activity.Since.In(loc))) {
+						fill = heatmapLevels[level(count(day), max)]
+					}
+					c.rect(left+w*(cell+gap), top+d*(cell+gap), cell, cell, fill)
+				}
+			}
+
+			// 图例
+			y := top + 7*(cell+gap) + 8
+			x := left
+			c.text(x, y-1, "Less", colorMuted, alignStart)
+			x += textWidth("Less") + 6
+			for _, fill := range heatmapLevels {
+				c.rect(x, y, cell, cell, fill)
+				x += cell + gap
+			}
+			c.text(x+3, y-1, fmt.Sprintf("More (max %d)", max), colorMuted, alignStart)
+		},
+	}
+}
+
+// level 把数量映射到 0-4 级颜色，0 只用于没有活动的日子
+func level(n, max int) int {
+	if n == 0 || max == 0 {
+		return 0
+	}
+	l := (n*4 + max - 1) / max
+	if l < 1 {
+		l = 1
+	}
+	return l
+}
+
+type repoValue struct {
+	repo      string
+	value     int
+	additions int
+	deletions int
+}
+
+// sortedRepos 按 key 从大到小排序并只保留前 maxBars 个
+func sortedRepos(stats map[string]*github.RepoStats, key func(*github.RepoStats) int) []repoValue {
+	var repos []repoValue
+	for repo, s := range stats {
+		if key(s) == 0 {
+			continue
+		}
+		repos = append(repos, repoValue{repo: repo, value: key(s), additions: s.Additions, deletions: s.Deletions})
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].value != repos[j].value {
+			return repos[i].value > repos[j].value
+		}
+		return repos[i].repo < repos[j].repo
+	})
+	if len(repos) > maxBars {
+		repos = repos[:maxBars]
+	}
+	return repos
+}
+
+// 横向柱状图的布局
+const (
+	barLabelChars = 28
+	barLeft       = 8 + barLabelChars*charWidth + 8
+	barWidth      = 320
+	barHeight     = 14
+	barGap        = 8
+	barTop        = 32
+	barValueSpace = 120
+)
+
+// repoCommits 绘制每个仓库的 commit 数
+func repoCommits(stats *github.Stats) (chart, bool) {
+	repos := sortedRepos(stats.ByRepo, func(s *github.RepoStats) int { return s.Commits })
+	if len(repos) == 0 {
+		return chart{}, false
+	}
+	max := repos[0].value
+
+	return chart{
+		name:   "commits",
+		title:  "Commits per repository",
+		width:  barLeft + barWidth + barValueSpace,
+		height: barTop + len(repos)*(barHeight+barGap) + 8,
+		draw: func(c canvas) {
+			c.text(8, 8, "Commits per repository", colorText, alignStart)
+			for i, r := range repos {
+				y := barTop + i*(barHeight+barGap)
+				c.text(barLeft-8, y, truncate(r.repo, barLabelChars), colorText, alignEnd)
+				w := r.value * barWidth / max
+				c.rect(barLeft, y, w, barHeight, colorBar)
+				c.text(barLeft+w+6, y, fmt.Sprintf("%d", r.value), colorMuted, alignStart)
+			}
+		},
+	}, true
+}
+
+// lineChanges 绘制每个仓库新增（绿）与删除（红）行数的堆叠柱状图
+func lineChanges(stats *github.Stats) (chart, bool) {
+	repos := sortedRepos(stats.ByRepo, func(s *github.RepoStats) int { return s.Additions + s.Deletions })
+	if len(repos) == 0 {
+		return chart{}, false
+	}
+	max := repos[0].value
+	legendTop := barTop + len(repos)*(barHeight+barGap) + 4
+
+	return chart{
+		name:   "lines",
+		title:  "Lines added / deleted per repository",
+		width:  barLeft + barWidth + barValueSpace,
+		height: legendTop + lineHeight + 12,
+		draw: func(c canvas) {
+			c.text(8, 8, "Lines added / deleted per repository", colorText, alignStart)
+			for i, r := range repos {
+				y := barTop + i*(barHeight+barGap)
+				c.text(barLeft-8, y, truncate(r.repo, barLabelChars), colorText, alignEnd)
+				add := r.additions * barWidth / max
+				del := r.deletions * barWidth / max
+				c.rect(barLeft, y, add, barHeight, colorAdditions)
+				c.rect(barLeft+add, y, del, barHeight, colorDeletions)
+				c.text(barLeft+add+del+6, y, fmt.Sprintf("+%d -%d", r.additions, r.deletions), colorMuted, alignStart)
+			}
+
+			c.rect(barLeft, legendTop+2, 10, 10, colorAdditions)
+			c.text(barLeft+14, legendTop, "added", colorMuted, alignStart)
+			c.rect(barLeft+70, legendTop+2, 10, 10, colorDeletions)
+			c.text(barLeft+84, legendTop, "deleted", colorMuted, alignStart)
+		},
+	}, true
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek 返回 t 所在周的周一零点
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+func TestRender(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	since := time.Date(2026, 10, 12, 0, 0, 0, 0, loc)
+
+	commit := func(repo string, day, additions int) github.CommitInfo {
+		return github.CommitInfo{
+			SHA:       repo + string(rune('a'+day)),
+			Repo:      repo,
+			Date:      since.AddDate(0, 0, day).Add(10 * time.Hour),
+			Additions: additions,
+			Deletions: additions / 2,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		until     time.Time
+		commits   []github.CommitInfo
+		wantNames []string
+	}{
+		{"empty", since.AddDate(0, 0, 7), nil, []string{"heatmap"}},
+		{"single repo", since.AddDate(0, 0, 7), []github.CommitInfo{commit("octo/app", 1, 10)}, []string{"heatmap", "commits", "lines"}},
+		{
+			name:  "multi-week, several repos",
+			until: since.AddDate(0, 0, 30),
+			commits: []github.CommitInfo{
+				commit("octo/app", 0, 10),
+				commit("octo/app", 9, 0),
+				commit("octo/<lib>&co", 20, 5),
+				commit("a-very-long-organization-name/with-a-long-repository", 29, 3),
+			},
+			wantNames: []string{"heatmap", "commits", "lines"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := &github.UserActivity{Username: "octocat", Since: since, Until: tt.until, Commits: tt.commits}
+
+			images, err := Render(activity, loc)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+
+			var names []string
+			for _, img := range images {
+				names = append(names, img.Name)
+
+				cfg, err := png.DecodeConfig(bytes.NewReader(img.PNG))
+				if err != nil {
+					t.Errorf("%s: invalid png: %v", img.Name, err)
+				} else if cfg.Width == 0 || cfg.Height == 0 {
+					t.Errorf("%s: png is %dx%d", img.Name, cfg.Width, cfg.Height)
+				}
+
+				checkSVG(t, img.Name, img.SVG)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("charts = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+// checkSVG 确认 SVG 是完整的 XML 文档，根元素为 svg
+func checkSVG(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Errorf("%s: malformed svg: %v", name, err)
+			return
+		}
+		if start, ok := tok.(xml.StartElement); ok && root == "" {
+			root = start.Name.Local
+		}
+	}
+	if root != "svg" {
+		t.Errorf("%s: root element = %q, want svg", name, root)
+	}
+}
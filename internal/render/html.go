@@ -0,0 +1,62 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var htmlPage = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 880px; margin: 40px auto; padding: 0 16px; font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; line-height: 1.6; color: #24292f; }
+table { border-collapse: collapse; margin: 12px 0; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; }
+blockquote { margin: 0; padding: 0 12px; color: #656d76; border-left: 4px solid #d0d7de; }
+figure { margin: 24px 0; overflow-x: auto; }
+figcaption { color: #656d76; font-size: 14px; }
+</style>
+</head>
+<body>
+{{.Body}}
+{{range .Charts}}<figure>{{.SVG}}<figcaption>{{.Title}}</figcaption></figure>
+{{end}}</body>
+</html>
+`))
+
+// HTML 把 Markdown 报告转换为独立的 HTML 页面，并在正文后内嵌 SVG 图表
+func HTML(title, markdown, lang string, images []Image) ([]byte, error) {
+	var body bytes.Buffer
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	if err := md.Convert([]byte(markdown), &body); err != nil {
+		return nil, fmt.Errorf("failed to convert markdown: %w", err)
+	}
+
+	type htmlChart struct {
+		Title string
+		SVG   template.HTML
+	}
+	charts := make([]htmlChart, 0, len(images))
+	for _, img := range images {
+		charts = append(charts, htmlChart{Title: img.Title, SVG: template.HTML(img.SVG)})
+	}
+
+	var page bytes.Buffer
+	err := htmlPage.Execute(&page, map[string]interface{}{
+		"Title":  title,
+		"Lang":   lang,
+		"Body":   template.HTML(body.String()),
+		"Charts": charts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render html: %w", err)
+	}
+	return page.Bytes(), nil
+}
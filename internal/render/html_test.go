@@ -0,0 +1,36 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLEscapesMarkdown(t *testing.T) {
+	markdown := "## 本周总结\n\n" +
+		"<script>alert('x')</script>\n\n" +
+		"修复了 <img src=x onerror=alert(1)> 的问题，见 [链接](javascript:alert(1))。\n\n" +
+		"| 仓库 | Commits |\n| --- | ---: |\n| octo/app | 3 |\n"
+	images := []Image{{Title: "Commits <per> repo", SVG: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)}}
+
+	page, err := HTML(`周报 <octocat> & "team"`, markdown, "zh", images)
+	if err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	out := string(page)
+
+	for _, bad := range []string{"<script>", "<img", "javascript:", "<octocat>", "Commits <per> repo"} {
+		if strings.Contains(out, bad) {
+			t.Errorf("page contains unescaped %q:\n%s", bad, out)
+		}
+	}
+	for _, want := range []string{
+		"<title>周报 &lt;octocat&gt; &amp; &#34;team&#34;</title>",
+		"<h2>本周总结</h2>",
+		"<td>octo/app</td>",
+		`<figure><svg xmlns="http://www.w3.org/2000/svg"></svg><figcaption>Commits &lt;per&gt; repo</figcaption></figure>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("page missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package reporter
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+)
+
+// 条目类型，对应 LLM 输入中的字段
+const (
+	itemStream = "work_streams"
+	itemIssue  = "issues"
+	itemReview = "reviews"
+)
+
+// rankedItem 是一个参与预算分配的条目
+type rankedItem struct {
+	kind   string
+	repo   string
+	score  float64
+	tokens int
+	data   map[string]interface{}
+	size   int // 工作流的 commit 数，用于汇总被省略的部分
+}
+
+// streamSignificance 评估工作流的重要性：已合并的 PR、改动规模、收到的评论、关联的 issue、
+// 同一仓库在期间发布的 release 都会提高分数
+func streamSignificance(s *workStream, releases []github.ReleaseInfo) float64 {
+	score := 1.0
+	for _, item := range s.items {
+		pr := item.PullRequest
+		if pr.MergedAt != nil || pr.State == "merged" {
+			score += 5
+		} else {
+			score += 2
+		}
+		score += 2 * float64(len(item.ClosedIssues))
+		score += 0.5 * math.Min(float64(pr.Comments), 20)
+		if pr.Change.Type == github.ChangeFeat {
+			score++
+		}
+	}
+
+	score += math.Log2(1 + float64(s.size()))
+	score += 0.3 * math.Min(float64(len(s.commits)), 10)
+	if s.status() == "landed" {
+		score += 2
+	}
+	if s.breaking() {
+		score += 2
+	}
+
+	// release 通常意味着本期的工作已经交付
+	_, last := s.timeRange()
+	for _, rel := range releases {
+		if strings.EqualFold(rel.Repo, s.repo) && !rel.PublishedAt.Before(last) {
+			score += 3
+			break
+		}
+	}
+	return score
+}
+
+// issueSignificance 评估 issue 的重要性：已关闭的和讨论较多的排在前面
+func issueSignificance(issue github.IssueInfo) float64 {
+	score := 1.0
+	if issue.ClosedAt != nil {
+		score += 2
+	}
+	return score + 0.3*math.Min(float64(issue.Comments), 20)
+}
+
+// reviewSignificance 评估 review 的重要性：要求修改和批准比单纯评论更有信息量
+func reviewSignificance(review github.ReviewInfo) float64 {
+	switch strings.ToUpper(review.State) {
+	case "CHANGES_REQUESTED":
+		return 2.5
+	case "APPROVED":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// rankItems 为工作流、issue 和 review 打分并估算各自的 token 数，按重要性从高到低排序
+func (r *Reporter) rankItems(activity *github.UserActivity, streams []*workStream) []rankedItem {
+	var items []rankedItem
+	add := func(kind, repo string, score float64, data map[string]interface{}, size int) {
+		items = append(items, rankedItem{kind: kind, repo: repo, score: score, tokens: estimateTokens(data), data: data, size: size})
+	}
+
+	formatted := r.formatWorkStreams(streams, activity.Patches)
+	for i, s := range streams {
+		add(itemStream, s.repo, streamSignificance(s, activity.Releases), formatted[i], len(s.commits))
+	}
+	for i, data := range r.formatIssues(activity.Issues) {
+		add(itemIssue, activity.Issues[i].Repo, issueSignificance(activity.Issues[i]), data, 0)
+	}
+	for i, data := range r.formatReviews(activity.Reviews) {
+		add(itemReview, activity.Reviews[i].Repo, reviewSignificance(activity.Reviews[i]), data, 0)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].score > items[j].score
+	})
+	return items
+}
+
+// packItems 按重要性把条目放入 budget 个 token 的预算，放不下的条目汇总为数量。
+// budget <= 0 表示不限制。返回按类型分组的条目（组内保持重要性顺序）和被省略部分的汇总，没有省略时汇总为 nil。
+func packItems(items []rankedItem, budget int) (map[string][]map[string]interface{}, map[string]interface{}) {
+	packed := map[string][]map[string]interface{}{
+		itemStream: {},
+		itemIssue:  {},
+		itemReview: {},
+	}
+
+	omitted := map[string]int{}
+	omittedCommits := 0
+	omittedRepos := map[string]int{}
+	used := 0
+	for _, item := range items {
+		if budget <= 0 || used+item.tokens <= budget {
+			used += item.tokens
+			packed[item.kind] = append(packed[item.kind], item.data)
+			continue
+		}
+		omitted[item.kind]++
+		omittedCommits += item.size
+		omittedRepos[item.repo]++
+	}
+
+	if len(omitted) == 0 {
+		return packed, nil
+	}
+	summary := map[string]interface{}{
+		"by_repo": omittedRepos,
+	}
+	for kind, n := range omitted {
+		summary[kind] = n
+	}
+	if omittedCommits > 0 {
+		summary["commits"] = omittedCommits
+	}
+	return packed, summary
+}
+
+// estimateTokens 估算一个条目在 LLM 输入中占用的 token 数
+func estimateTokens(v interface{}) int {
+	data, err := json.MarshalIndent(v, "    ", "  ")
+	if err != nil {
+		return 0
+	}
+	return llm.EstimateTokens(string(data))
+}
@@ -0,0 +1,144 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+func TestRankItems(t *testing.T) {
+	base := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	merged := base.Add(48 * time.Hour)
+	closed := base.Add(24 * time.Hour)
+	activity := &github.UserActivity{
+		PullRequests: []github.PullRequestInfo{
+			{Repo: "octo/app", Number: 7, Title: "feat: add export", State: "closed", MergedAt: &merged, Comments: 6,
+				Additions: 300, Deletions: 20, CreatedAt: base, ClosingIssues: []github.IssueRef{{Repo: "octo/app", Number: 3}}},
+		},
+		Commits: []github.CommitInfo{
+			{SHA: "a1", Repo: "octo/app", Message: "feat: add export", PRNumber: 7, Date: base},
+			{SHA: "c1", Repo: "octo/lib", Message: "chore: tidy", Additions: 2, Date: base},
+		},
+		Issues: []github.IssueInfo{
+			{Repo: "octo/app", Number: 3, Title: "Export data", ClosedAt: &closed, CreatedAt: base},
+		},
+		Reviews: []github.ReviewInfo{
+			{Repo: "octo/web", PRNumber: 1, State: "COMMENTED", CreatedAt: base},
+			{Repo: "octo/web", PRNumber: 2, State: "CHANGES_REQUESTED", CreatedAt: base},
+		},
+	}
+	activity.LinkWorkItems()
+
+	r := &Reporter{location: time.UTC}
+	items := r.rankItems(activity, clusterWorkStreams(activity))
+
+	var got []string
+	for i, item := range items {
+		got = append(got, item.kind+":"+item.repo)
+		if i > 0 && item.score > items[i-1].score {
+			t.Errorf("item %d (%s) scores %.2f, more than the previous %.2f", i, got[i], item.score, items[i-1].score)
+		}
+		if item.tokens <= 0 {
+			t.Errorf("item %s has %d tokens", got[i], item.tokens)
+		}
+	}
+	want := []string{"work_streams:octo/app", "issues:octo/app", "work_streams:octo/lib", "reviews:octo/web", "reviews:octo/web"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("ranked = %v, want %v", got, want)
+	}
+	if items[3].data["state"] != "CHANGES_REQUESTED" {
+		t.Errorf("first review = %v, want the one requesting changes", items[3].data)
+	}
+}
+
+func TestPackItems(t *testing.T) {
+	item := func(kind, repo string, tokens, size int) rankedItem {
+		return rankedItem{kind: kind, repo: repo, tokens: tokens, size: size, data: map[string]interface{}{"repo": repo}}
+	}
+	items := []rankedItem{
+		item(itemStream, "o/a", 10, 3),
+		item(itemStream, "o/b", 50, 8), // 放不下，跳过后继续尝试后面的条目
+		item(itemIssue, "o/a", 10, 0),
+		item(itemReview, "o/c", 10, 0),
+		item(itemReview, "o/c", 10, 0),
+	}
+
+	t.Run("no budget keeps everything", func(t *testing.T) {
+		packed, omitted := packItems(items, 0)
+		if len(packed[itemStream]) != 2 || len(packed[itemIssue]) != 1 || len(packed[itemReview]) != 2 {
+			t.Errorf("packed = %v, want every item", packed)
+		}
+		if omitted != nil {
+			t.Errorf("omitted = %v, want nil", omitted)
+		}
+	})
+
+	t.Run("skip and continue", func(t *testing.T) {
+		packed, omitted := packItems(items, 30)
+		if len(packed[itemStream]) != 1 || packed[itemStream][0]["repo"] != "o/a" || len(packed[itemIssue]) != 1 || len(packed[itemReview]) != 1 {
+			t.Errorf("packed = %v, want o/a stream, the issue and one review", packed)
+		}
+		want := map[string]interface{}{
+			"by_repo":  map[string]int{"o/b": 1, "o/c": 1},
+			itemStream: 1,
+			itemReview: 1,
+			"commits":  8,
+		}
+		if fmt.Sprint(omitted) != fmt.Sprint(want) {
+			t.Errorf("omitted = %v, want %v", omitted, want)
+		}
+	})
+
+	t.Run("empty kinds are still present", func(t *testing.T) {
+		packed, _ := packItems(nil, 10)
+		for _, kind := range []string{itemStream, itemIssue, itemReview} {
+			if items, ok := packed[kind]; !ok || items == nil {
+				t.Errorf("packed[%s] = %v, want an empty list", kind, items)
+			}
+		}
+	})
+}
+
+// TestFormatActivityDataTrimsStatistics 验证按仓库和按天的统计挤占预算时被去掉，而不是把所有条目都省略
+func TestFormatActivityDataTrimsStatistics(t *testing.T) {
+	base := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+	activity := &github.UserActivity{Username: "octocat", Since: base, Until: base.AddDate(0, 3, 0)}
+	for i := 0; i < 90; i++ {
+		activity.Commits = append(activity.Commits, github.CommitInfo{
+			SHA:     fmt.Sprintf("%040d", i),
+			Repo:    fmt.Sprintf("octo/repo-%02d", i%30),
+			Message: fmt.Sprintf("fix: issue %d", i),
+			Date:    base.AddDate(0, 0, i),
+		})
+	}
+	activity.LinkWorkItems()
+
+	r := &Reporter{location: time.UTC}
+	// 预算恰好等于完整汇总的大小：不去掉分组统计就没有任何条目能放下
+	r.tokenBudget = estimateTokens(r.summaryData(activity, nil, Request{}))
+
+	out, truncated, err := r.formatActivityData(activity, nil, Request{})
+	if err != nil {
+		t.Fatalf("formatActivityData: %v", err)
+	}
+	var data struct {
+		Statistics  map[string]json.RawMessage `json:"statistics"`
+		WorkStreams []json.RawMessage          `json:"work_streams"`
+	}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if _, ok := data.Statistics["by_day"]; ok {
+		t.Error("statistics still contain by_day")
+	}
+	if _, ok := data.Statistics["by_repo"]; ok {
+		t.Error("statistics still contain by_repo")
+	}
+	if len(data.WorkStreams) != 30 || truncated || strings.Contains(out, `"omitted"`) {
+		t.Errorf("got %d work streams (truncated %v), want all 30", len(data.WorkStreams), truncated)
+	}
+}
@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+	"github-reports/internal/period"
+)
+
+// metric 是对比表中的一个指标
+type metric struct {
+	name  string
+	label [2]string // 中文、英文显示名
+	value func(*github.Stats) int
+}
+
+// metrics 是参与环比的统计指标，按对比表中的顺序排列
+var metrics = []metric{
+	{"total_commits", [2]string{"Commits", "Commits"}, func(s *github.Stats) int { return s.TotalCommits }},
+	{"total_prs", [2]string{"PR", "PRs"}, func(s *github.Stats) int { return s.TotalPRs }},
+	{"merged_prs", [2]string{"已合并 PR", "Merged PRs"}, func(s *github.Stats) int { return s.MergedPRs }},
+	{"total_issues", [2]string{"Issues", "Issues"}, func(s *github.Stats) int { return s.TotalIssues }},
+	{"closed_issues", [2]string{"已关闭 Issues", "Closed issues"}, func(s *github.Stats) int { return s.ClosedIssues }},
+	{"total_reviews", [2]string{"Reviews", "Reviews"}, func(s *github.Stats) int { return s.TotalReviews }},
+	{"code_additions", [2]string{"新增行数", "Lines added"}, func(s *github.Stats) int { return s.CodeAdditions }},
+	{"code_deletions", [2]string{"删除行数", "Lines deleted"}, func(s *github.Stats) int { return s.CodeDeletions }},
+	{"net_code_changes", [2]string{"净增行数", "Net lines"}, func(s *github.Stats) int { return s.NetCodeChanges }},
+	{"active_days", [2]string{"活跃天数", "Active days"}, func(s *github.Stats) int { return s.ActiveDays }},
+	{"median_pr_size", [2]string{"PR 规模中位数", "Median PR size"}, func(s *github.Stats) int { return s.MedianPRSize }},
+}
+
+// MetricDelta 是一个指标在两个周期之间的变化
+type MetricDelta struct {
+	Metric   string   `json:"metric"`
+	Current  int      `json:"current"`
+	Previous int      `json:"previous"`
+	Delta    int      `json:"delta"`
+	Percent  *float64 `json:"percent,omitempty"` // 上期为 0 时没有百分比
+}
+
+// RepoDelta 是单个仓库在两个周期之间的变化
+type RepoDelta struct {
+	Repo      string      `json:"repo"`
+	Status    string      `json:"status"` // new（本期新出现）、gone（本期没有活动）、active
+	Commits   MetricDelta `json:"commits"`
+	PRs       MetricDelta `json:"prs"`
+	Additions MetricDelta `json:"additions"`
+	Deletions MetricDelta `json:"deletions"`
+}
+
+// Comparison 是本期与上一个同类周期的对比
+type Comparison struct {
+	PreviousPeriod string        `json:"previous_period"`
+	Metrics        []MetricDelta `json:"metrics"`
+	Repos          []RepoDelta   `json:"repos"`
+}
+
+// compareActivities 计算两期活动在每个统计指标和每个仓库上的差值
+func compareActivities(current, previous *github.Stats, prev period.Period) *Comparison {
+	cmp := &Comparison{PreviousPeriod: prev.Label}
+
+	for _, m := range metrics {
+		cmp.Metrics = append(cmp.Metrics, newDelta(m.name, m.value(current), m.value(previous)))
+	}
+
+	curRepos, oldRepos := current.ByRepo, previous.ByRepo
+	names := make(map[string]bool)
+	for repo := range curRepos {
+		names[repo] = true
+	}
+	for repo := range oldRepos {
+		names[repo] = true
+	}
+
+	for repo := range names {
+		c, p := curRepos[repo], oldRepos[repo]
+		status := "active"
+		switch {
+		case p == nil:
+			status, p = "new", &github.RepoStats{}
+		case c == nil:
+			status, c = "gone", &github.RepoStats{}
+		}
+		cmp.Repos = append(cmp.Repos, RepoDelta{
+			Repo:      repo,
+			Status:    status,
+			Commits:   newDelta("commits", c.Commits, p.Commits),
+			PRs:       newDelta("prs", c.PRs, p.PRs),
+			Additions: newDelta("additions", c.Additions, p.Additions),
+			Deletions: newDelta("deletions", c.Deletions, p.Deletions),
+		})
+	}
+
+	// 变化最大的仓库排在前面
+	sort.Slice(cmp.Repos, func(i, j int) bool {
+		di, dj := abs(cmp.Repos[i].Commits.Delta), abs(cmp.Repos[j].Commits.Delta)
+		if di != dj {
+			return di > dj
+		}
+		return cmp.Repos[i].Repo < cmp.Repos[j].Repo
+	})
+
+	return cmp
+}
+
+func newDelta(metric string, current, previous int) MetricDelta {
+	d := MetricDelta{
+		Metric:   metric,
+		Current:  current,
+		Previous: previous,
+		Delta:    current - previous,
+	}
+	if previous != 0 {
+		pct := float64(current-previous) / float64(abs(previous)) * 100
+		d.Percent = &pct
+	}
+	return d
+}
+
+// formatComparisonTable 生成报告的 comparison 段落（环比表格），不经过 LLM，保证数字准确
+func formatComparisonTable(cmp *Comparison, language string) string {
+	en := language == llm.LanguageEn
+	idx := 0
+	if en {
+		idx = 1
+	}
+
+	var sb strings.Builder
+	if en {
+		sb.WriteString(fmt.Sprintf("\n\n## Compared with %s\n\n", cmp.PreviousPeriod))
+		sb.WriteString("| Metric | This period | Previous | Change |\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("\n\n## 环比（对比 %s）\n\n", cmp.PreviousPeriod))
+		sb.WriteString("| 指标 | 本期 | 上期 | 变化 |\n")
+	}
+	sb.WriteString("| --- | ---: | ---: | ---: |\n")
+	for i, m := range cmp.Metrics {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %s |\n", metrics[i].label[idx], m.Current, m.Previous, formatChange(m)))
+	}
+
+	if len(cmp.Repos) > 0 {
+		if en {
+			sb.WriteString("\n| Repository | Commits | PRs | Lines added | Lines deleted |\n")
+		} else {
+			sb.WriteString("\n| 仓库 | Commits | PR | 新增行数 | 删除行数 |\n")
+		}
+		sb.WriteString("| --- | ---: | ---: | ---: | ---: |\n")
+		for _, r := range cmp.Repos {
+			name := r.Repo
+			switch {
+			case r.Status == "new" && en:
+				name += " (new)"
+			case r.Status == "new":
+				name += "（新）"
+			case r.Status == "gone" && en:
+				name += " (inactive)"
+			case r.Status == "gone":
+				name += "（本期无活动）"
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+				name, formatCell(r.Commits), formatCell(r.PRs), formatCell(r.Additions), formatCell(r.Deletions)))
+		}
+	}
+
+	return sb.String()
+}
+
+// formatChange 格式化为 +3 (+50%)；上期为 0 时只显示差值
+func formatChange(d MetricDelta) string {
+	if d.Delta == 0 {
+		return "0"
+	}
+	s := fmt.Sprintf("%+d", d.Delta)
+	if d.Percent != nil {
+		s += fmt.Sprintf(" (%+.0f%%)", *d.Percent)
+	}
+	return s
+}
+
+// formatCell 格式化为「本期值 (差值)」，没有变化时只显示本期值
+func formatCell(d MetricDelta) string {
+	if d.Delta == 0 {
+		return fmt.Sprintf("%d", d.Current)
+	}
+	return fmt.Sprintf("%d (%+d)", d.Current, d.Delta)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
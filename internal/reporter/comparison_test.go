@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+	"github-reports/internal/period"
+)
+
+func TestNewDelta(t *testing.T) {
+	tests := []struct {
+		name              string
+		current, previous int
+		wantDelta         int
+		wantPercent       string // 空串表示没有百分比
+		wantChange        string
+	}{
+		{"increase", 15, 10, 5, "50", "+5 (+50%)"},
+		{"decrease", 5, 10, -5, "-50", "-5 (-50%)"},
+		{"unchanged", 7, 7, 0, "0", "0"},
+		{"zero previous", 4, 0, 4, "", "+4"},
+		{"both zero", 0, 0, 0, "", "0"},
+		{"negative previous", -5, -10, 5, "50", "+5 (+50%)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDelta("m", tt.current, tt.previous)
+			if d.Delta != tt.wantDelta {
+				t.Errorf("Delta = %d, want %d", d.Delta, tt.wantDelta)
+			}
+			var pct string
+			if d.Percent != nil {
+				pct = fmt.Sprintf("%.0f", *d.Percent)
+			}
+			if pct != tt.wantPercent {
+				t.Errorf("Percent = %q, want %q", pct, tt.wantPercent)
+			}
+			if got := formatChange(d); got != tt.wantChange {
+				t.Errorf("formatChange = %q, want %q", got, tt.wantChange)
+			}
+		})
+	}
+}
+
+func comparisonStats() (current, previous *github.Stats) {
+	current = &github.Stats{
+		TotalCommits: 12,
+		TotalPRs:     3,
+		ByRepo: map[string]*github.RepoStats{
+			"octo/app": {Commits: 10, PRs: 2, Additions: 300, Deletions: 20},
+			"octo/new": {Commits: 2, PRs: 1, Additions: 50},
+		},
+	}
+	previous = &github.Stats{
+		TotalCommits: 8,
+		ByRepo: map[string]*github.RepoStats{
+			"octo/app": {Commits: 4, PRs: 2, Additions: 100, Deletions: 20},
+			"octo/old": {Commits: 4, Additions: 80, Deletions: 5},
+		},
+	}
+	return current, previous
+}
+
+func TestCompareActivities(t *testing.T) {
+	current, previous := comparisonStats()
+	cmp := compareActivities(current, previous, period.Period{Label: "2026 年第 40 周"})
+
+	if cmp.PreviousPeriod != "2026 年第 40 周" || len(cmp.Metrics) != len(metrics) {
+		t.Fatalf("comparison = %+v", cmp)
+	}
+	if m := cmp.Metrics[0]; m.Metric != "total_commits" || m.Delta != 4 || m.Percent == nil || *m.Percent != 50 {
+		t.Errorf("total_commits = %+v, want +4 (+50%%)", m)
+	}
+	if m := cmp.Metrics[1]; m.Metric != "total_prs" || m.Delta != 3 || m.Percent != nil {
+		t.Errorf("total_prs = %+v, want +3 without percent", m)
+	}
+
+	// 按 commit 差值的绝对值排序，相同时按仓库名
+	var got []string
+	for _, r := range cmp.Repos {
+		got = append(got, fmt.Sprintf("%s:%s:%+d", r.Repo, r.Status, r.Commits.Delta))
+	}
+	want := []string{"octo/app:active:+6", "octo/old:gone:-4", "octo/new:new:+2"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("repos = %v, want %v", got, want)
+	}
+	if gone := cmp.Repos[1]; gone.Additions.Current != 0 || gone.Additions.Previous != 80 {
+		t.Errorf("gone repo additions = %+v, want 0 vs 80", gone.Additions)
+	}
+	if added := cmp.Repos[2]; added.PRs.Percent != nil || added.PRs.Delta != 1 {
+		t.Errorf("new repo PRs = %+v, want +1 without percent", added.PRs)
+	}
+}
+
+func TestFormatComparisonTable(t *testing.T) {
+	current, previous := comparisonStats()
+	cmp := compareActivities(current, previous, period.Period{Label: "last week"})
+
+	tests := []struct {
+		language string
+		want     []string
+	}{
+		{llm.LanguageZh, []string{
+			"## 环比（对比 last week）",
+			"| Commits | 12 | 8 | +4 (+50%) |",
+			"| PR | 3 | 0 | +3 |",
+			"| octo/app | 10 (+6) | 2 | 300 (+200) | 20 |",
+			"| octo/old（本期无活动） | 0 (-4) | 0 | 0 (-80) | 0 (-5) |",
+			"| octo/new（新） | 2 (+2) | 1 (+1) | 50 (+50) | 0 |",
+		}},
+		{llm.LanguageEn, []string{
+			"## Compared with last week",
+			"| Metric | This period | Previous | Change |",
+			"| Lines added | 0 | 0 | 0 |",
+			"| octo/old (inactive) |",
+			"| octo/new (new) |",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			table := formatComparisonTable(cmp, tt.language)
+			for _, want := range tt.want {
+				if !strings.Contains(table, want) {
+					t.Errorf("table missing %q:\n%s", want, table)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+)
+
+// 报告段落。header、statistics、merged_prs、releases 和 comparison 由程序根据数据生成，
+// projects、roadmap 和 analysis 由 LLM 撰写
+const (
+	SectionHeader     = "header"
+	SectionProjects   = "projects"
+	SectionRoadmap    = "roadmap"
+	SectionAnalysis   = "analysis"
+	SectionStatistics = "statistics"
+	SectionMergedPRs  = "merged_prs"
+	SectionReleases   = "releases"
+	SectionComparison = "comparison"
+)
+
+// DefaultLayout 是未配置 report.layout 时的段落顺序
+var DefaultLayout = []string{
+	SectionHeader,
+	SectionProjects,
+	SectionRoadmap,
+	SectionAnalysis,
+	SectionStatistics,
+	SectionMergedPRs,
+	SectionReleases,
+	SectionComparison,
+}
+
+// SetLayout 设置报告的段落顺序，未列出的段落不会出现在报告中；为空时使用 DefaultLayout
+func (r *Reporter) SetLayout(layout []string) {
+	r.layout = layout
+}
+
+// sectionMarker 匹配 LLM 输出中的段落标记，如 <!-- section: analysis -->
+var sectionMarker = regexp.MustCompile(`(?m)^[ \t]*<!--\s*section:\s*([a-z_]+)\s*-->[ \t]*$`)
+
+// splitNarrative 按段落标记拆分 LLM 的输出。没有任何标记时（模型没有遵守格式），
+// 整段输出作为 projects，保证内容不会丢失
+func splitNarrative(text string) map[string]string {
+	sections := make(map[string]string)
+	matches := sectionMarker.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		sections[SectionProjects] = strings.TrimSpace(text)
+		return sections
+	}
+	for i, m := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		name := text[m[2]:m[3]]
+		body := strings.TrimSpace(text[m[1]:end])
+		if body == "" {
+			continue
+		}
+		if sections[name] != "" {
+			body = sections[name] + "\n\n" + body
+		}
+		sections[name] = body
+	}
+	return sections
+}
+
+var sectionFuncs = template.FuncMap{
+	// line 去掉换行，避免标题破坏 Markdown 列表和表格
+	"line": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+// sectionTemplates 是程序生成的段落模板，每种语言一套
+var sectionTemplates = map[string]*template.Template{
+	llm.LanguageZh: template.Must(template.New(llm.LanguageZh).Funcs(sectionFuncs).Parse(`
+{{- define "header" -}}
+# [{{.Username}}]({{.ProfileURL}}) 的 GitHub 动态分析{{if .Period}} · {{.Period}}{{end}}
+
+> 统计周期：{{.TimeRange}}（{{.Timezone}}）
+{{- end}}
+
+{{- define "statistics" -}}
+## 数据统计
+
+| 指标 | 数值 |
+| --- | ---: |
+| Commits | {{.Stats.TotalCommits}} |
+| PR（已合并） | {{.Stats.TotalPRs}}（{{.Stats.MergedPRs}}） |
+| Issues（已关闭） | {{.Stats.TotalIssues}}（{{.Stats.ClosedIssues}}） |
+| Reviews | {{.Stats.TotalReviews}} |
+| Releases | {{.Stats.TotalReleases}} |
+| 新增 / 删除行数 | +{{.Stats.CodeAdditions}} / -{{.Stats.CodeDeletions}} |
+| 活跃天数 | {{.Stats.ActiveDays}} |
+{{- if gt (len .Repos) 1}}
+
+| 仓库 | Commits | PR | Issues | Reviews | 新增行数 | 删除行数 |
+| --- | ---: | ---: | ---: | ---: | ---: | ---: |
+{{- range .Repos}}
+| {{.Name}} | {{.Commits}} | {{.PRs}} | {{.Issues}} | {{.Reviews}} | +{{.Additions}} | -{{.Deletions}} |
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- define "merged_prs" -}}
+## 已合并的 PR
+
+{{range .MergedPRs -}}
+- [{{.Repo}}#{{.Number}}]({{.URL}}) {{line .Title}}（+{{.Additions}} -{{.Deletions}}，{{.Merged}}）
+{{end -}}
+{{- end}}
+
+{{- define "releases" -}}
+## 发布的版本
+
+{{range .Releases -}}
+- [{{.Repo}} {{.Tag}}]({{.URL}}){{if .Name}} {{line .Name}}{{end}}{{if .Prerelease}}（预发布）{{end}}，{{.Published}}
+{{end -}}
+{{- end}}
+`)),
+	llm.LanguageEn: template.Must(template.New(llm.LanguageEn).Funcs(sectionFuncs).Parse(`
+{{- define "header" -}}
+# GitHub activity of [{{.Username}}]({{.ProfileURL}}){{if .Period}} · {{.Period}}{{end}}
+
+> Period: {{.TimeRange}} ({{.Timezone}})
+{{- end}}
+
+{{- define "statistics" -}}
+## Statistics
+
+| Metric | Value |
+| --- | ---: |
+| Commits | {{.Stats.TotalCommits}} |
+| PRs (merged) | {{.Stats.TotalPRs}} ({{.Stats.MergedPRs}}) |
+| Issues (closed) | {{.Stats.TotalIssues}} ({{.Stats.ClosedIssues}}) |
+| Reviews | {{.Stats.TotalReviews}} |
+| Releases | {{.Stats.TotalReleases}} |
+| Lines added / deleted | +{{.Stats.CodeAdditions}} / -{{.Stats.CodeDeletions}} |
+| Active days | {{.Stats.ActiveDays}} |
+{{- if gt (len .Repos) 1}}
+
+| Repository | Commits | PRs | Issues | Reviews | Lines added | Lines deleted |
+| --- | ---: | ---: | ---: | ---: | ---: | ---: |
+{{- range .Repos}}
+| {{.Name}} | {{.Commits}} | {{.PRs}} | {{.Issues}} | {{.Reviews}} | +{{.Additions}} | -{{.Deletions}} |
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- define "merged_prs" -}}
+## Merged pull requests
+
+{{range .MergedPRs -}}
+- [{{.Repo}}#{{.Number}}]({{.URL}}) {{line .Title}} (+{{.Additions}} -{{.Deletions}}, {{.Merged}})
+{{end -}}
+{{- end}}
+
+{{- define "releases" -}}
+## Releases
+
+{{range .Releases -}}
+- [{{.Repo}} {{.Tag}}]({{.URL}}){{if .Name}} {{line .Name}}{{end}}{{if .Prerelease}} (pre-release){{end}}, {{.Published}}
+{{end -}}
+{{- end}}
+`)),
+}
+
+// sectionRepo 是统计表中的一行
+type sectionRepo struct {
+	Name string
+	*github.RepoStats
+}
+
+// sectionPR 是已合并 PR 列表中的一项
+type sectionPR struct {
+	github.PullRequestInfo
+	Merged string
+}
+
+// sectionRelease 是 release 列表中的一项
+type sectionRelease struct {
+	github.ReleaseInfo
+	Published string
+}
+
+// sectionData 是程序生成段落的模板数据
+type sectionData struct {
+	Username   string
+	ProfileURL string // 用户主页，主机与 GitHub 客户端一致（github.com 或 GitHub Enterprise）
+	Period     string
+	TimeRange  string
+	Timezone   string
+	Stats      *github.Stats
+	Repos      []sectionRepo
+	MergedPRs  []sectionPR
+	Releases   []sectionRelease
+}
+
+// sectionData 收集模板需要的数据：仓库按活动量排序，已合并的 PR 按仓库和合并时间排序，release 按发布时间排序
+func (r *Reporter) sectionData(activity *github.UserActivity, req Request) *sectionData {
+	stats := activity.Statistics(r.location)
+	host := "github.com"
+	if r.repoInfo != nil {
+		host = r.repoInfo.Host()
+	}
+	data := &sectionData{
+		Username:   activity.Username,
+		ProfileURL: fmt.Sprintf("https://%s/%s", host, activity.Username),
+		Period:     req.Period.Label,
+		TimeRange:  fmt.Sprintf("%s ~ %s", r.formatDate(activity.Since), r.formatDate(activity.Until)),
+		Timezone:   r.location.String(),
+		Stats:      stats,
+	}
+
+	for _, repo := range activityRepos(stats) {
+		data.Repos = append(data.Repos, sectionRepo{Name: repo, RepoStats: stats.ByRepo[repo]})
+	}
+
+	var merged []github.PullRequestInfo
+	for _, pr := range activity.PullRequests {
+		if pr.MergedAt != nil {
+			merged = append(merged, pr)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Repo != merged[j].Repo {
+			return merged[i].Repo < merged[j].Repo
+		}
+		return merged[i].MergedAt.Before(*merged[j].MergedAt)
+	})
+	for _, pr := range merged {
+		data.MergedPRs = append(data.MergedPRs, sectionPR{PullRequestInfo: pr, Merged: r.formatDate(*pr.MergedAt)})
+	}
+
+	releases := append([]github.ReleaseInfo(nil), activity.Releases...)
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].PublishedAt.Before(releases[j].PublishedAt)
+	})
+	for _, rel := range releases {
+		if rel.Name == rel.Tag {
+			rel.Name = ""
+		}
+		data.Releases = append(data.Releases, sectionRelease{ReleaseInfo: rel, Published: r.formatDate(rel.PublishedAt)})
+	}
+	return data
+}
+
+// assembleReport 按布局把程序生成的段落和 LLM 撰写的段落拼成一份 Markdown 报告，没有内容的段落会被跳过
+func (r *Reporter) assembleReport(narrative string, activity *github.UserActivity, comparison *Comparison, req Request) (string, error) {
+	layout := r.layout
+	if len(layout) == 0 {
+		layout = DefaultLayout
+	}
+
+	language := llm.LanguageZh
+	if req.Language == llm.LanguageEn {
+		language = llm.LanguageEn
+	}
+	tmpl := sectionTemplates[language]
+
+	if !sectionMarker.MatchString(narrative) {
+		println("[Reporter]", activity.Username, "- 警告: LLM 输出没有段落标记，整段作为项目进展")
+	}
+	written := splitNarrative(narrative)
+	data := r.sectionData(activity, req)
+
+	var parts []string
+	for _, name := range layout {
+		var part string
+		switch name {
+		case SectionProjects, SectionRoadmap, SectionAnalysis:
+			part = written[name]
+		case SectionStatistics:
+			if data.Stats.TotalActivities() == 0 {
+				continue
+			}
+		case SectionMergedPRs:
+			if len(data.MergedPRs) == 0 {
+				continue
+			}
+		case SectionReleases:
+			if len(data.Releases) == 0 {
+				continue
+			}
+		case SectionComparison:
+			if comparison != nil {
+				part = formatComparisonTable(comparison, req.Language)
+			}
+		}
+
+		if tmpl.Lookup(name) != nil {
+			var buf bytes.Buffer
+			if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+				return "", fmt.Errorf("failed to render section %s: %w", name, err)
+			}
+			part = buf.String()
+		}
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
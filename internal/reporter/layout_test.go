@@ -0,0 +1,132 @@
+package reporter
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+)
+
+func TestSplitNarrative(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want map[string]string
+	}{
+		{
+			name: "sections",
+			text: "<!-- section: projects -->\n## 项目\nA\n\n<!-- section: analysis -->\n## 分析\nB\n",
+			want: map[string]string{SectionProjects: "## 项目\nA", SectionAnalysis: "## 分析\nB"},
+		},
+		{
+			name: "repeated section is concatenated and empty section skipped",
+			text: "<!-- section: projects -->\nA\n<!-- section: roadmap -->\n\n  <!-- section: projects -->  \nB",
+			want: map[string]string{SectionProjects: "A\n\nB"},
+		},
+		{
+			name: "no marker falls back to projects",
+			text: "\n## 项目进展\n整段输出\n",
+			want: map[string]string{SectionProjects: "## 项目进展\n整段输出"},
+		},
+		{
+			name: "inline marker is not a section boundary",
+			text: "说明 <!-- section: analysis --> 不在行首",
+			want: map[string]string{SectionProjects: "说明 <!-- section: analysis --> 不在行首"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitNarrative(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitNarrative = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type hostRepoInfo string
+
+func (h hostRepoInfo) Host() string { return string(h) }
+
+func (hostRepoInfo) FetchRepositories(context.Context, []string) map[string]*github.RepoInfo {
+	return nil
+}
+
+func layoutActivity() *github.UserActivity {
+	merged := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	activity := &github.UserActivity{
+		Username: "octocat",
+		Since:    time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		PullRequests: []github.PullRequestInfo{
+			{Repo: "octo/app", Number: 7, Title: "Add export", URL: "https://ghe.example.com/octo/app/pull/7",
+				Additions: 100, Deletions: 20, CreatedAt: merged.Add(-time.Hour), MergedAt: &merged},
+		},
+		Commits: []github.CommitInfo{
+			{SHA: "a1", Repo: "octo/app", PRNumber: 7, Additions: 100, Deletions: 20, Date: merged.Add(-2 * time.Hour)},
+			{SHA: "c1", Repo: "octo/lib", Additions: 3, Date: merged},
+		},
+	}
+	activity.LinkWorkItems()
+	return activity
+}
+
+func TestAssembleReport(t *testing.T) {
+	narrative := "<!-- section: projects -->\n## 项目进展\nP\n\n<!-- section: analysis -->\n## 工作分析\nA\n"
+
+	t.Run("default layout", func(t *testing.T) {
+		r := &Reporter{location: time.UTC, repoInfo: hostRepoInfo("ghe.example.com")}
+		report, err := r.assembleReport(narrative, layoutActivity(), nil, Request{})
+		if err != nil {
+			t.Fatalf("assembleReport: %v", err)
+		}
+
+		order := []string{"# [octocat](https://ghe.example.com/octocat)", "## 项目进展", "## 工作分析", "## 数据统计", "## 已合并的 PR"}
+		last := -1
+		for _, heading := range order {
+			i := strings.Index(report, heading)
+			if i < 0 || i < last {
+				t.Fatalf("%q missing or out of order in report:\n%s", heading, report)
+			}
+			last = i
+		}
+		// PR 与其 commit 的行数只计一次
+		if !strings.Contains(report, "| 新增 / 删除行数 | +103 / -20 |") {
+			t.Errorf("statistics do not count each change once:\n%s", report)
+		}
+		for _, skipped := range []string{"## 发布的版本", "<!-- section"} {
+			if strings.Contains(report, skipped) {
+				t.Errorf("report contains %q:\n%s", skipped, report)
+			}
+		}
+	})
+
+	t.Run("without marker the narrative becomes projects", func(t *testing.T) {
+		r := &Reporter{location: time.UTC, layout: []string{SectionProjects, SectionAnalysis}}
+		report, err := r.assembleReport("未按格式输出的全文", layoutActivity(), nil, Request{})
+		if err != nil {
+			t.Fatalf("assembleReport: %v", err)
+		}
+		if report != "未按格式输出的全文\n" {
+			t.Errorf("report = %q, want the whole narrative", report)
+		}
+	})
+
+	t.Run("english layout and default host", func(t *testing.T) {
+		r := &Reporter{location: time.UTC, layout: []string{SectionHeader, SectionStatistics}}
+		report, err := r.assembleReport(narrative, layoutActivity(), nil, Request{Language: llm.LanguageEn})
+		if err != nil {
+			t.Fatalf("assembleReport: %v", err)
+		}
+		if !strings.HasPrefix(report, "# GitHub activity of [octocat](https://github.com/octocat)") {
+			t.Errorf("unexpected header:\n%s", report)
+		}
+		if strings.Contains(report, "## 项目进展") || !strings.Contains(report, "| Lines added / deleted | +103 / -20 |") {
+			t.Errorf("unexpected sections:\n%s", report)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github-reports/internal/github"
+)
+
+// map-reduce 模式
+const (
+	MapReduceAuto   = "auto"   // 活动数据超出 token 预算且涉及多个仓库时启用
+	MapReduceAlways = "always" // 涉及多个仓库时总是启用
+	MapReduceOff    = "off"
+)
+
+// defaultMapConcurrency 是未设置并发数时同时进行的仓库摘要请求数
+const defaultMapConcurrency = 4
+
+// SetMapReduce 设置分仓库摘要的模式（MapReduceAuto、MapReduceAlways 或 MapReduceOff）和并发数，
+// 未设置时不启用
+func (r *Reporter) SetMapReduce(mode string, concurrency int) {
+	r.mapReduceMode = mode
+	r.mapConcurrency = concurrency
+}
+
+// useMapReduce 判断是否分仓库摘要：只有一个仓库时没有意义
+func (r *Reporter) useMapReduce(truncated bool, repos int) bool {
+	if repos < 2 {
+		return false
+	}
+	switch r.mapReduceMode {
+	case MapReduceAlways:
+		return true
+	case MapReduceAuto:
+		return truncated
+	default:
+		return false
+	}
+}
+
+// activityRepos 返回有活动的仓库，活动多的排在前面
+func activityRepos(stats *github.Stats) []string {
+	total := func(s *github.RepoStats) int {
+		return s.Commits + s.PRs + s.Issues + s.Reviews
+	}
+
+	repos := make([]string, 0, len(stats.ByRepo))
+	for repo := range stats.ByRepo {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		ti, tj := total(stats.ByRepo[repos[i]]), total(stats.ByRepo[repos[j]])
+		if ti != tj {
+			return ti > tj
+		}
+		return repos[i] < repos[j]
+	})
+	return repos
+}
+
+// repoSummary 是 map 阶段一个仓库的结果
+type repoSummary struct {
+	repo    string
+	summary string
+	err     error
+}
+
+// mapReduce 先为每个仓库单独调用 LLM 生成摘要（并发数受限），再把摘要合成最终报告。
+// 个别仓库失败时继续合成，并在报告中说明；全部失败时返回错误。
+func (r *Reporter) mapReduce(ctx context.Context, activity *github.UserActivity, comparison *Comparison, req Request, repos []string) (string, error) {
+	username := activity.Username
+	concurrency := r.mapConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultMapConcurrency
+	}
+	println("[Reporter]", username, "- 活动较多，分", len(repos), "个仓库生成摘要，并发数:", concurrency)
+
+	results := make([]repoSummary, len(repos))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	for i, repo := range repos {
+		// 在启动 goroutine 之前拆分，FilterRepos 会重建工作项
+		ra := repoActivity(activity, repo)
+
+		wg.Add(1)
+		go func(i int, repo string, ra *github.UserActivity) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			summary, err := r.summarizeRepo(ctx, ra, req)
+			results[i] = repoSummary{repo: repo, summary: summary, err: err}
+
+			mu.Lock()
+			done++
+			if err != nil {
+				println("[Reporter]", username, "- 仓库摘要进度", done, "/", len(repos), ":", repo, "失败:", err.Error())
+			} else {
+				println("[Reporter]", username, "- 仓库摘要进度", done, "/", len(repos), ":", repo)
+			}
+			mu.Unlock()
+		}(i, repo, ra)
+	}
+	wg.Wait()
+
+	stats := activity.Statistics(r.location)
+	var summaries []map[string]interface{}
+	var failed []string
+	for _, res := range results {
+		if res.err != nil {
+			failed = append(failed, res.repo)
+			continue
+		}
+		summaries = append(summaries, map[string]interface{}{
+			"repo":       res.repo,
+			"summary":    res.summary,
+			"statistics": stats.ByRepo[res.repo],
+		})
+	}
+	if len(summaries) == 0 {
+		return "", fmt.Errorf("failed to summarize any repository: %w", results[0].err)
+	}
+
+	data := r.summaryData(activity, comparison, req)
+	// 各仓库的统计已随摘要给出，按天的分布对合成报告帮助不大，都不再重复发送
+	trimStatistics(data)
+	if len(failed) > 0 {
+		data["failed_repos"] = failed
+	}
+
+	budget := 0
+	if r.tokenBudget > 0 {
+		budget = r.tokenBudget - estimateTokens(data)
+		if budget < 1 {
+			budget = 1
+		}
+	}
+	kept, omitted := packRepoSummaries(summaries, budget)
+	data["repo_summaries"] = kept
+	if omitted != nil {
+		data["omitted"] = omitted
+		println("[Reporter]", username, "- 仓库摘要超出 token 预算:", r.tokenBudget, "，", len(summaries)-len(kept), "个次要仓库只保留活动数")
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to format repo summaries: %w", err)
+	}
+
+	println("[Reporter]", username, "- 正在合成", len(kept), "个仓库摘要，失败", len(failed), "个...")
+	report, err := r.llmClient.ReduceReport(ctx, string(jsonData))
+	if err != nil {
+		println("[Reporter]", username, "- 合成报告失败:", err.Error())
+		return "", fmt.Errorf("failed to reduce repo summaries: %w", err)
+	}
+	return report, nil
+}
+
+// packRepoSummaries 按仓库的活动量顺序放入摘要，直到超出 budget（<= 0 表示不限制）。
+// 活动最多的仓库总会保留；放不下的仓库只汇总为各自的活动数，与 packItems 的 omitted 格式一致
+func packRepoSummaries(summaries []map[string]interface{}, budget int) ([]map[string]interface{}, map[string]interface{}) {
+	if budget <= 0 {
+		return summaries, nil
+	}
+
+	var kept []map[string]interface{}
+	omittedRepos := map[string]int{}
+	used := 0
+	for i, s := range summaries {
+		tokens := estimateTokens(s)
+		if i == 0 || (len(omittedRepos) == 0 && used+tokens <= budget) {
+			used += tokens
+			kept = append(kept, s)
+			continue
+		}
+		rs := s["statistics"].(*github.RepoStats)
+		omittedRepos[s["repo"].(string)] = rs.Commits + rs.PRs + rs.Issues + rs.Reviews
+	}
+
+	if len(omittedRepos) == 0 {
+		return kept, nil
+	}
+	return kept, map[string]interface{}{
+		"repo_summaries": len(omittedRepos),
+		"by_repo":        omittedRepos,
+	}
+}
+
+// summarizeRepo 为单个仓库格式化活动数据并生成摘要，token 预算同样适用于每个仓库
+func (r *Reporter) summarizeRepo(ctx context.Context, activity *github.UserActivity, req Request) (string, error) {
+	data, _, err := r.formatActivityData(activity, nil, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to format activity data: %w", err)
+	}
+	return r.llmClient.SummarizeRepo(ctx, data)
+}
+
+// repoActivity 返回只包含某个仓库活动的副本，不修改原活动
+func repoActivity(activity *github.UserActivity, repo string) *github.UserActivity {
+	ra := *activity
+	ra.Roadmap = nil
+	ra.FilterRepos(func(name string) bool {
+		return strings.EqualFold(name, repo)
+	})
+	return &ra
+}
@@ -0,0 +1,55 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"github-reports/internal/github"
+)
+
+func TestPackRepoSummaries(t *testing.T) {
+	summary := func(repo string, commits int) map[string]interface{} {
+		return map[string]interface{}{
+			"repo":       repo,
+			"summary":    strings.Repeat("完成了一些工作。", 40),
+			"statistics": &github.RepoStats{Commits: commits},
+		}
+	}
+	summaries := []map[string]interface{}{summary("o/a", 30), summary("o/b", 20), summary("o/c", 5)}
+	one := estimateTokens(summaries[0])
+
+	tests := []struct {
+		name        string
+		budget      int
+		wantKept    int
+		wantOmitted map[string]int
+	}{
+		{"no budget keeps everything", 0, 3, nil},
+		{"budget for two", 2*one + 1, 2, map[string]int{"o/c": 5}},
+		{"tiny budget still keeps the top repository", 1, 1, map[string]int{"o/b": 20, "o/c": 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, omitted := packRepoSummaries(summaries, tt.budget)
+			if len(kept) != tt.wantKept {
+				t.Errorf("kept %d summaries, want %d", len(kept), tt.wantKept)
+			}
+			if tt.wantOmitted == nil {
+				if omitted != nil {
+					t.Errorf("omitted = %v, want nil", omitted)
+				}
+				return
+			}
+			byRepo, _ := omitted["by_repo"].(map[string]int)
+			if len(byRepo) != len(tt.wantOmitted) || omitted["repo_summaries"] != len(tt.wantOmitted) {
+				t.Fatalf("omitted = %v, want %v", omitted, tt.wantOmitted)
+			}
+			for repo, n := range tt.wantOmitted {
+				if byRepo[repo] != n {
+					t.Errorf("omitted[%s] = %d, want %d", repo, byRepo[repo], n)
+				}
+			}
+		})
+	}
+}
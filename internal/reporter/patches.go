@@ -0,0 +1,200 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github-reports/internal/github"
+	"github-reports/internal/llm"
+)
+
+// PatchSource 获取 PR 和 commit 的逐文件 diff，目前只有 GitHub 支持
+type PatchSource interface {
+	CommitPatch(ctx context.Context, repo, sha string) ([]github.FilePatch, error)
+	PullRequestPatch(ctx context.Context, repo string, number int) ([]github.FilePatch, error)
+}
+
+// PatchOptions 控制哪些工作流附带 diff
+type PatchOptions struct {
+	TopN             int      // 附带 diff 的工作流数量
+	MaxTokensPerItem int      // 每个 diff 截断后的 token 上限
+	IncludePrivate   bool     // 是否发送私有仓库的 diff
+	ExcludeRepos     []string // 永远不发送 diff 的仓库，支持 path.Match 通配符
+}
+
+// SetPatches 设置 diff 来源。设置后，最重要的几个工作流会附带精简后的 diff，
+// 让 LLM 能描述 "fix"、"update" 之类的 commit 实际改了什么
+func (r *Reporter) SetPatches(src PatchSource, opts PatchOptions) {
+	r.patchSource = src
+	r.patchOptions = opts
+}
+
+// patchAllowed 判断仓库的代码能否发给 LLM：需要有仓库元数据且明确了可见性，
+// private / internal 仓库需要 IncludePrivate，可见性未知（如早期缓存的元数据）时一律不发送，
+// 匹配 ExcludeRepos 的仓库也不发送
+func (r *Reporter) patchAllowed(activity *github.UserActivity, repo string) bool {
+	info := activity.Repositories[repo]
+	if info == nil {
+		return false
+	}
+	switch info.Visibility {
+	case "public":
+	case "private", "internal":
+		if !r.patchOptions.IncludePrivate {
+			return false
+		}
+	default:
+		return false
+	}
+	lower := strings.ToLower(repo)
+	for _, pattern := range r.patchOptions.ExcludeRepos {
+		if ok, _ := path.Match(strings.ToLower(pattern), lower); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchPatches 为最重要的 TopN 个允许发送 diff 的工作流拉取 diff：有 PR 的取改动最大的 PR，
+// 否则取改动最大的非 merge commit。单个失败时只记录日志。
+func (r *Reporter) fetchPatches(ctx context.Context, activity *github.UserActivity) map[string]string {
+	streams := clusterWorkStreams(activity)
+	sort.SliceStable(streams, func(i, j int) bool {
+		return streamSignificance(streams[i], activity.Releases) > streamSignificance(streams[j], activity.Releases)
+	})
+
+	patches := make(map[string]string)
+	for _, s := range streams {
+		if len(patches) >= r.patchOptions.TopN {
+			break
+		}
+		if !r.patchAllowed(activity, s.repo) {
+			continue
+		}
+
+		var files []github.FilePatch
+		var key string
+		var err error
+		if pr := largestPullRequest(s); pr != nil {
+			key = github.PatchKey(pr.Repo, pr.Number, "")
+			files, err = r.patchSource.PullRequestPatch(ctx, pr.Repo, pr.Number)
+		} else if c := largestCommit(s); c != nil {
+			key = github.PatchKey(c.Repo, 0, c.SHA)
+			files, err = r.patchSource.CommitPatch(ctx, c.Repo, c.SHA)
+		} else {
+			continue
+		}
+		if err != nil {
+			println("[Reporter] 获取 diff 失败:", err.Error())
+			continue
+		}
+		if patch := formatPatch(files, r.patchOptions.MaxTokensPerItem); patch != "" {
+			patches[key] = patch
+		}
+	}
+	return patches
+}
+
+// largestPullRequest 返回工作流中改动最大的 PR，没有 PR 时返回 nil
+func largestPullRequest(s *workStream) *github.PullRequestInfo {
+	var largest *github.PullRequestInfo
+	for i := range s.items {
+		pr := &s.items[i].PullRequest
+		if largest == nil || pr.Additions+pr.Deletions > largest.Additions+largest.Deletions {
+			largest = pr
+		}
+	}
+	return largest
+}
+
+// largestCommit 返回工作流中改动最大的非 merge commit
+func largestCommit(s *workStream) *github.CommitInfo {
+	var largest *github.CommitInfo
+	for i := range s.commits {
+		c := &s.commits[i]
+		if c.IsMerge {
+			continue
+		}
+		if largest == nil || c.Additions+c.Deletions > largest.Additions+largest.Deletions {
+			largest = c
+		}
+	}
+	return largest
+}
+
+// shortSHA 返回 commit 的短 SHA
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// formatPatch 把逐文件 diff 拼成一段文本：去掉锁文件、生成文件和没有 diff 的二进制文件，
+// 生产代码在前、测试在后，各自按改动大小排序，超出 maxTokens（<= 0 表示不限制）时按行截断，
+// 放不下的文件只列出文件名。没有可展示的内容时返回空字符串。
+func formatPatch(files []github.FilePatch, maxTokens int) string {
+	var kept []github.FilePatch
+	noise := 0
+	for _, f := range files {
+		if github.IsNoiseFile(f.Path) {
+			noise++
+			continue
+		}
+		if f.Patch != "" {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		ti, tj := github.IsTestFile(kept[i].Path), github.IsTestFile(kept[j].Path)
+		if ti != tj {
+			return !ti
+		}
+		return kept[i].Additions+kept[i].Deletions > kept[j].Additions+kept[j].Deletions
+	})
+
+	var b strings.Builder
+	used := 0
+	var skipped []string
+	for i, f := range kept {
+		header := fmt.Sprintf("--- %s (+%d -%d)\n", f.Path, f.Additions, f.Deletions)
+		headerTokens := llm.EstimateTokens(header)
+		if maxTokens > 0 && used+headerTokens >= maxTokens {
+			skipped = append(skipped, f.Path)
+			continue
+		}
+		b.WriteString(header)
+		used += headerTokens
+
+		// 后面还有文件时单个文件最多占剩余预算的 2/3，避免一个大文件挤掉其他文件
+		limit := maxTokens
+		if maxTokens > 0 && i < len(kept)-1 {
+			limit = used + (maxTokens-used)*2/3
+		}
+		lines := strings.Split(strings.TrimRight(f.Patch, "\n"), "\n")
+		for j, line := range lines {
+			tokens := llm.EstimateTokens(line)
+			if maxTokens > 0 && used+tokens > limit {
+				fmt.Fprintf(&b, "... (%d more lines truncated)\n", len(lines)-j)
+				break
+			}
+			b.WriteString(line)
+			b.WriteByte('\n')
+			used += tokens
+		}
+	}
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "... (diff omitted for: %s)\n", strings.Join(skipped, ", "))
+	}
+	if noise > 0 {
+		fmt.Fprintf(&b, "... (%d lockfile / generated / binary files skipped)\n", noise)
+	}
+	return b.String()
+}
@@ -4,128 +4,396 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github-reports/internal/github"
 	"github-reports/internal/llm"
+	"github-reports/internal/period"
+	"github-reports/internal/source"
 )
 
-// Reporter 从 GitHub 活动生成报告
+// RoadmapSource 获取本期工作在路线图上的映射，目前只有 GitHub 支持
+type RoadmapSource interface {
+	FetchRoadmap(ctx context.Context, activity *github.UserActivity, targets github.RoadmapTargets) *github.Roadmap
+}
+
+// History 保存每次拉取到的活动，并在历史完整覆盖某个周期时直接提供数据
+type History interface {
+	SaveActivity(activity *github.UserActivity) error
+	CoveredActivity(username string, since, until time.Time) (*github.UserActivity, bool, error)
+}
+
+// RepoInfoSource 获取仓库元数据（描述、topics、主要语言、star 数、README 首段），目前只有 GitHub 支持
+type RepoInfoSource interface {
+	// Host 返回仓库所在的 GitHub 主机名，只有链接指向该主机的仓库才会查询元数据
+	Host() string
+	FetchRepositories(ctx context.Context, repos []string) map[string]*github.RepoInfo
+}
+
+// Reporter 从活动数据生成报告
 type Reporter struct {
-	githubClient *github.Client
-	fetcher      *github.Fetcher
-	llmClient    llm.Client
+	source         source.ActivitySource
+	llmClient      llm.Client
+	roadmapSource  RoadmapSource
+	roadmap        github.RoadmapTargets
+	repoInfo       RepoInfoSource
+	location       *time.Location
+	history        History
+	tokenBudget    int
+	mapReduceMode  string
+	mapConcurrency int
+	patchSource    PatchSource
+	patchOptions   PatchOptions
+	layout         []string
 }
 
 // NewReporter 创建一个新的 Reporter
-func NewReporter(githubClient *github.Client, llmClient llm.Client) *Reporter {
+func NewReporter(src source.ActivitySource, llmClient llm.Client) *Reporter {
 	return &Reporter{
-		githubClient: githubClient,
-		fetcher:      github.NewFetcher(githubClient),
-		llmClient:    llmClient,
+		source:    src,
+		llmClient: llmClient,
+		location:  time.Local,
+	}
+}
+
+// SetLocation 设置报告使用的时区，所有日期都按该时区格式化
+func (r *Reporter) SetLocation(loc *time.Location) {
+	r.location = loc
+}
+
+// SetRoadmap 设置需要映射到报告中的看板和里程碑，未设置时不拉取路线图
+func (r *Reporter) SetRoadmap(src RoadmapSource, targets github.RoadmapTargets) {
+	r.roadmapSource = src
+	r.roadmap = targets
+}
+
+// SetRepoInfo 设置仓库元数据来源，未设置时 LLM 只能看到仓库名
+func (r *Reporter) SetRepoInfo(src RepoInfoSource) {
+	r.repoInfo = src
+}
+
+// SetHistory 设置历史存储：拉取到的活动会写入其中，环比时优先从历史读取上一周期
+func (r *Reporter) SetHistory(h History) {
+	r.history = h
+}
+
+// SetTokenBudget 设置活动数据的 token 预算（通常由 llm.InputBudget 按模型计算），
+// 超出时只保留最重要的工作流、issue 和 review，其余汇总为数量；未设置时不限制
+func (r *Reporter) SetTokenBudget(tokens int) {
+	r.tokenBudget = tokens
+}
+
+// Request 描述一次报告生成
+type Request struct {
+	Username string
+	Period   period.Period
+	Org      string // 可选：只保留该组织下仓库的活动
+	Repo     string // 可选：只保留该仓库（owner/name）的活动
+	Style    string // brief 或 detailed，为空时由提示词决定
+	Language string // zh 或 en，为空时使用中文
+
+	Previous *period.Period // 可选：用于环比的上一个同类周期
+}
+
+// Result 是一次报告生成的结果
+type Result struct {
+	Report   string
+	Activity *github.UserActivity // 过滤后的活动数据，用于绘制图表
+}
+
+// GenerateReport 为用户生成指定周期的报告
+func (r *Reporter) GenerateReport(ctx context.Context, req Request) (string, error) {
+	result, err := r.Generate(ctx, req)
+	if err != nil {
+		return "", err
 	}
+	return result.Report, nil
 }
 
-// GenerateReport 为用户生成周报
-func (r *Reporter) GenerateReport(ctx context.Context, username string, since, until time.Time) (string, error) {
-	// Fetch GitHub activities
-	println("[Reporter]", username, "- 正在拉取 GitHub 活动数据...")
-	activity, err := r.fetcher.FetchActivities(ctx, username, since, until)
+// Generate 为用户生成指定周期的报告，并返回生成报告所用的活动数据
+func (r *Reporter) Generate(ctx context.Context, req Request) (*Result, error) {
+	username := req.Username
+	since, until := req.Period.Since, req.Period.Until
+
+	// Fetch activities from all configured sources
+	println("[Reporter]", username, "- 正在拉取活动数据...")
+	activity, err := r.source.FetchActivities(ctx, username, since, until)
 	if err != nil {
 		println("[Reporter]", username, "- 拉取活动数据失败:", err.Error())
-		return "", fmt.Errorf("failed to fetch activities: %w", err)
+		return nil, fmt.Errorf("failed to fetch activities: %w", err)
+	}
+	r.saveHistory(activity)
+
+	if req.Org != "" || req.Repo != "" {
+		filterActivity(activity, req)
+		println("[Reporter]", username, "- 按组织 / 仓库过滤:", req.Org, req.Repo)
 	}
 
-	stats := activity.Statistics()
-	println("[Reporter]", username, "- 数据统计: Commits:", stats["total_commits"], "PRs:", stats["total_prs"], "Issues:", stats["total_issues"], "Reviews:", stats["total_reviews"])
+	stats := activity.Statistics(r.location)
+	println("[Reporter]", username, "- 数据统计: Commits:", stats.TotalCommits, "PRs:", stats.TotalPRs, "Issues:", stats.TotalIssues, "Reviews:", stats.TotalReviews)
 
 	// Check if we have any data
-	totalActivities := stats["total_commits"].(int) + stats["total_prs"].(int) + stats["total_issues"].(int) + stats["total_reviews"].(int)
-	if totalActivities == 0 {
+	if stats.TotalActivities() == 0 {
 		println("[Reporter]", username, "- 警告: 该用户在指定时间范围内没有任何活动")
-		return "", fmt.Errorf("用户 %s 在 %s ~ %s 期间没有任何 GitHub 活动",
+		return nil, fmt.Errorf("用户 %s 在 %s ~ %s 期间没有任何 GitHub 活动",
 			username,
-			since.Format("2006-01-02"),
-			until.Format("2006-01-02"))
+			r.formatDate(since),
+			r.formatDate(until))
+	}
+
+	if r.roadmapSource != nil && !r.roadmap.Empty() {
+		println("[Reporter]", username, "- 正在拉取路线图进展...")
+		activity.Roadmap = r.roadmapSource.FetchRoadmap(ctx, activity, r.roadmap)
+	}
+
+	if r.repoInfo != nil {
+		if repos := githubRepos(activity, r.repoInfo.Host()); len(repos) > 0 {
+			println("[Reporter]", username, "- 正在获取", len(repos), "个仓库的元数据...")
+			activity.Repositories = r.repoInfo.FetchRepositories(ctx, repos)
+		}
+	}
+
+	// diff 需要仓库元数据判断可见性，因此在其后拉取
+	if r.patchSource != nil && r.patchOptions.TopN > 0 && len(activity.Repositories) > 0 {
+		println("[Reporter]", username, "- 正在获取重点工作的 diff...")
+		activity.Patches = r.fetchPatches(ctx, activity)
+		println("[Reporter]", username, "- 已获取", len(activity.Patches), "个 diff")
+	}
+
+	var comparison *Comparison
+	if req.Previous != nil {
+		comparison = r.compare(ctx, activity, req)
 	}
 
 	// Format activity data for LLM
 	println("[Reporter]", username, "- 正在格式化活动数据...")
-	activityData, err := r.formatActivityData(activity)
+	activityData, truncated, err := r.formatActivityData(activity, comparison, req)
 	if err != nil {
 		println("[Reporter]", username, "- 格式化数据失败:", err.Error())
-		return "", fmt.Errorf("failed to format activity data: %w", err)
+		return nil, fmt.Errorf("failed to format activity data: %w", err)
 	}
 
 	println("[Reporter]", username, "- 格式化后的数据长度:", len(activityData), "字符")
 
-	// Generate report using LLM
-	println("[Reporter]", username, "- 正在调用 LLM 生成周报...")
-	report, err := r.llmClient.GenerateReport(ctx, activityData)
-	if err != nil {
-		println("[Reporter]", username, "- LLM 生成失败:", err.Error())
-		return "", fmt.Errorf("failed to generate report: %w", err)
+	var report string
+	if repos := activityRepos(stats); r.useMapReduce(truncated, len(repos)) {
+		// 数据过多时分仓库摘要再合成，保留更多细节
+		report, err = r.mapReduce(ctx, activity, comparison, req, repos)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Generate report using LLM
+		println("[Reporter]", username, "- 正在调用 LLM 生成周报...")
+		report, err = r.llmClient.GenerateReport(ctx, activityData)
+		if err != nil {
+			println("[Reporter]", username, "- LLM 生成失败:", err.Error())
+			return nil, fmt.Errorf("failed to generate report: %w", err)
+		}
 	}
 
 	println("[Reporter]", username, "- LLM 生成完成，报告长度:", len(report), "字符")
 
-	return report, nil
+	// 数字由程序生成，LLM 只负责叙述
+	report, err = r.assembleReport(report, activity, comparison, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble report: %w", err)
+	}
+
+	return &Result{Report: report, Activity: activity}, nil
 }
 
-// formatActivityData 将活动数据格式化为 LLM 可用的结构化字符串
-func (r *Reporter) formatActivityData(activity *github.UserActivity) (string, error) {
-	data := map[string]interface{}{
-		"username":      activity.Username,
-		"time_range":    fmt.Sprintf("%s ~ %s", activity.Since.Format("2006-01-02"), activity.Until.Format("2006-01-02")),
-		"statistics":    activity.Statistics(),
-		"commits":       r.formatCommits(activity.Commits),
-		"pull_requests": r.formatPullRequests(activity.PullRequests),
-		"issues":        r.formatIssues(activity.Issues),
-		"reviews":       r.formatReviews(activity.Reviews),
+// compare 拉取上一个同类周期的活动并计算环比，失败时只记录日志，不影响本期报告
+func (r *Reporter) compare(ctx context.Context, activity *github.UserActivity, req Request) *Comparison {
+	prev := *req.Previous
+
+	var previous *github.UserActivity
+	if r.history != nil {
+		stored, ok, err := r.history.CoveredActivity(req.Username, prev.Since, prev.Until)
+		if err != nil {
+			println("[Reporter]", req.Username, "- 读取历史数据失败:", err.Error())
+		}
+		if ok {
+			println("[Reporter]", req.Username, "- 使用历史数据作为上一周期:", prev.Label)
+			previous = stored
+		}
+	}
+
+	if previous == nil {
+		println("[Reporter]", req.Username, "- 正在拉取上一周期的活动数据:", prev.Label)
+		fetched, err := r.source.FetchActivities(ctx, req.Username, prev.Since, prev.Until)
+		if err != nil {
+			println("[Reporter]", req.Username, "- 拉取上一周期数据失败，跳过环比:", err.Error())
+			return nil
+		}
+		r.saveHistory(fetched)
+		previous = fetched
+	}
+	if req.Org != "" || req.Repo != "" {
+		filterActivity(previous, req)
+	}
+
+	return compareActivities(activity.Statistics(r.location), previous.Statistics(r.location), prev)
+}
+
+// saveHistory 在过滤之前保存完整的活动数据，失败不影响报告生成
+func (r *Reporter) saveHistory(activity *github.UserActivity) {
+	if r.history == nil {
+		return
+	}
+	if err := r.history.SaveActivity(activity); err != nil {
+		println("[Reporter]", activity.Username, "- 保存历史数据失败:", err.Error())
+	}
+}
+
+// filterActivity 只保留请求限定的组织或仓库中的活动
+func filterActivity(activity *github.UserActivity, req Request) {
+	activity.FilterRepos(func(repo string) bool {
+		if req.Repo != "" {
+			return strings.EqualFold(repo, req.Repo)
+		}
+		return strings.HasPrefix(strings.ToLower(repo), strings.ToLower(req.Org)+"/")
+	})
+}
+
+// formatActivityData 将活动数据格式化为 LLM 可用的结构化字符串，truncated 表示有条目因超出 token 预算被省略
+func (r *Reporter) formatActivityData(activity *github.UserActivity, comparison *Comparison, req Request) (string, bool, error) {
+	streams := clusterWorkStreams(activity)
+	println("[Reporter]", activity.Username, "- 聚类为", len(streams), "个工作流")
+
+	data := r.summaryData(activity, comparison, req)
+
+	// 汇总数据总是发送，工作流、issue 和 review 按重要性放入剩余的预算。
+	// 按仓库和按天的统计随活动量增长，挤占一半以上的预算时不再发送，条目本身已带有仓库和日期
+	budget := 0
+	if r.tokenBudget > 0 {
+		budget = r.tokenBudget - estimateTokens(data)
+		if budget < r.tokenBudget/2 {
+			trimStatistics(data)
+			budget = r.tokenBudget - estimateTokens(data)
+		}
+		if budget < 1 {
+			println("[Reporter]", activity.Username, "- 警告: 汇总数据已超出 token 预算:", r.tokenBudget, "，工作流、issue 和 review 只能汇总为数量")
+			budget = 1
+		}
+	}
+	packed, omitted := packItems(r.rankItems(activity, streams), budget)
+	for kind, items := range packed {
+		data[kind] = items
+	}
+	if omitted != nil {
+		data["omitted"] = omitted
+		println("[Reporter]", activity.Username, "- 活动数据超出 token 预算:", r.tokenBudget, "，次要条目已汇总为数量")
 	}
 
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
-	return string(jsonData), nil
+	return string(jsonData), omitted != nil, nil
 }
 
-func (r *Reporter) formatCommits(commits []github.CommitInfo) []map[string]interface{} {
-	result := make([]map[string]interface{}, 0, len(commits))
-	for _, c := range commits {
-		result = append(result, map[string]interface{}{
-			"sha":       c.SHA[:7], // Short SHA
-			"message":   c.Message,
-			"repo":      c.Repo,
-			"author":    c.Author,
-			"date":      c.Date.Format("2006-01-02 15:04"),
-			"additions": c.Additions,
-			"deletions": c.Deletions,
-		})
+// trimStatistics 从 summaryData 的统计中去掉按仓库和按天的分组
+func trimStatistics(data map[string]interface{}) {
+	if full, ok := data["statistics"].(*github.Stats); ok {
+		trimmed := *full
+		trimmed.ByRepo = nil
+		trimmed.ByDay = nil
+		data["statistics"] = &trimmed
 	}
-	return result
 }
 
-func (r *Reporter) formatPullRequests(prs []github.PullRequestInfo) []map[string]interface{} {
-	result := make([]map[string]interface{}, 0, len(prs))
-	for _, pr := range prs {
-		prData := map[string]interface{}{
-			"number":    pr.Number,
-			"title":     pr.Title,
-			"repo":      pr.Repo,
-			"state":     pr.State,
-			"url":       pr.URL,
-			"created":   pr.CreatedAt.Format("2006-01-02"),
-			"additions": pr.Additions,
-			"deletions": pr.Deletions,
-			"comments":  pr.Comments,
+// summaryData 返回 LLM 输入中与具体条目无关的部分：周期、统计、仓库元数据、release、路线图、环比和输出要求
+func (r *Reporter) summaryData(activity *github.UserActivity, comparison *Comparison, req Request) map[string]interface{} {
+	stats := activity.Statistics(r.location)
+	data := map[string]interface{}{
+		"username":   activity.Username,
+		"period":     req.Period.Label,
+		"time_range": fmt.Sprintf("%s ~ %s", r.formatDate(activity.Since), r.formatDate(activity.Until)),
+		"timezone":   r.location.String(),
+		"statistics": stats,
+	}
+
+	if repos := formatRepositories(activity.Repositories, stats); len(repos) > 0 {
+		data["repositories"] = repos
+	}
+
+	if len(activity.Releases) > 0 {
+		data["releases"] = r.formatReleases(activity.Releases)
+	}
+
+	if req.Style != "" {
+		data["report_style"] = req.Style
+	}
+	if req.Language != "" {
+		data["output_language"] = req.Language
+	}
+
+	if activity.Roadmap != nil && (len(activity.Roadmap.Projects) > 0 || len(activity.Roadmap.Milestones) > 0) {
+		data["roadmap"] = r.formatRoadmap(activity.Roadmap)
+	}
+
+	if comparison != nil {
+		data["comparison"] = comparison
+	}
+	return data
+}
+
+func (r *Reporter) formatPullRequest(pr github.PullRequestInfo) map[string]interface{} {
+	prData := map[string]interface{}{
+		"number":    pr.Number,
+		"title":     pr.Title,
+		"repo":      pr.Repo,
+		"state":     pr.State,
+		"url":       pr.URL,
+		"additions": pr.Additions,
+		"deletions": pr.Deletions,
+		"comments":  pr.Comments,
+	}
+	addChange(prData, pr.Change)
+	if !pr.CreatedAt.IsZero() {
+		prData["created"] = r.formatDate(pr.CreatedAt)
+	}
+	if pr.MergedAt != nil {
+		prData["merged"] = r.formatDate(*pr.MergedAt)
+	}
+	return prData
+}
+
+// addChange 附加变更类型，无法判断的类型不输出，避免干扰 LLM
+func addChange(data map[string]interface{}, c github.Change) {
+	if c.Type != "" && c.Type != github.ChangeOther {
+		data["change_type"] = c.Type
+	}
+	if c.Scope != "" {
+		data["scope"] = c.Scope
+	}
+	if c.Breaking {
+		data["breaking"] = true
+	}
+}
+
+func (r *Reporter) formatIssueRefs(refs []github.IssueRef) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(refs))
+	for _, ref := range refs {
+		refData := map[string]interface{}{
+			"repo":   ref.Repo,
+			"number": ref.Number,
+		}
+		if ref.Title != "" {
+			refData["title"] = ref.Title
 		}
-		if pr.MergedAt != nil {
-			prData["merged"] = pr.MergedAt.Format("2006-01-02")
+		if ref.State != "" {
+			refData["state"] = ref.State
 		}
-		result = append(result, prData)
+		if ref.URL != "" {
+			refData["url"] = ref.URL
+		}
+		result = append(result, refData)
 	}
 	return result
 }
@@ -139,11 +407,11 @@ func (r *Reporter) formatIssues(issues []github.IssueInfo) []map[string]interfac
 			"repo":     issue.Repo,
 			"state":    issue.State,
 			"url":      issue.URL,
-			"created":  issue.CreatedAt.Format("2006-01-02"),
+			"created":  r.formatDate(issue.CreatedAt),
 			"comments": issue.Comments,
 		}
 		if issue.ClosedAt != nil {
-			issueData["closed"] = issue.ClosedAt.Format("2006-01-02")
+			issueData["closed"] = r.formatDate(*issue.ClosedAt)
 		}
 		result = append(result, issueData)
 	}
@@ -159,8 +427,168 @@ func (r *Reporter) formatReviews(reviews []github.ReviewInfo) []map[string]inter
 			"repo":      review.Repo,
 			"state":     review.State,
 			"url":       review.URL,
-			"created":   review.CreatedAt.Format("2006-01-02"),
+			"created":   r.formatDate(review.CreatedAt),
 		})
 	}
 	return result
 }
+
+// formatRepositories 格式化本期有活动的仓库的元数据，按仓库名排序
+func formatRepositories(infos map[string]*github.RepoInfo, stats *github.Stats) []map[string]interface{} {
+	var names []string
+	for name := range infos {
+		if stats.ByRepo[name] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		info := infos[name]
+		repoData := map[string]interface{}{
+			"repo":  name,
+			"stars": info.Stars,
+		}
+		if info.Description != "" {
+			repoData["description"] = info.Description
+		}
+		if len(info.Topics) > 0 {
+			repoData["topics"] = info.Topics
+		}
+		if info.Language != "" {
+			repoData["language"] = info.Language
+		}
+		if info.Readme != "" {
+			repoData["readme"] = info.Readme
+		}
+		if info.Fork {
+			repoData["fork"] = true
+		}
+		if info.Archived {
+			repoData["archived"] = true
+		}
+		result = append(result, repoData)
+	}
+	return result
+}
+
+// githubRepos 返回活动中托管在 host（github.com 或 GitHub Enterprise 的域名）上的仓库：
+// 以条目链接的域名判断，网页和 API 链接（api. 子域名）都算，避免把 GitLab / Gitea 上同名的仓库拿去查询 GitHub
+func githubRepos(activity *github.UserActivity, host string) []string {
+	seen := make(map[string]bool)
+	var repos []string
+	add := func(repo, link string) {
+		if repo == "" || seen[repo] || !strings.Contains(repo, "/") {
+			return
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			return
+		}
+		if h := strings.ToLower(u.Hostname()); h != host && h != "api."+host {
+			return
+		}
+		seen[repo] = true
+		repos = append(repos, repo)
+	}
+
+	for _, c := range activity.Commits {
+		add(c.Repo, c.URL)
+	}
+	for _, pr := range activity.PullRequests {
+		add(pr.Repo, pr.URL)
+	}
+	for _, issue := range activity.Issues {
+		add(issue.Repo, issue.URL)
+	}
+	for _, review := range activity.Reviews {
+		add(review.Repo, review.URL)
+	}
+	for _, rel := range activity.Releases {
+		add(rel.Repo, rel.URL)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+func (r *Reporter) formatReleases(releases []github.ReleaseInfo) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(releases))
+	for _, rel := range releases {
+		relData := map[string]interface{}{
+			"repo":      rel.Repo,
+			"tag":       rel.Tag,
+			"url":       rel.URL,
+			"published": r.formatDate(rel.PublishedAt),
+		}
+		if rel.Name != "" && rel.Name != rel.Tag {
+			relData["name"] = rel.Name
+		}
+		if rel.Prerelease {
+			relData["prerelease"] = true
+		}
+		result = append(result, relData)
+	}
+	return result
+}
+
+// formatRoadmap 格式化看板条目和里程碑完成度
+func (r *Reporter) formatRoadmap(roadmap *github.Roadmap) map[string]interface{} {
+	projects := make([]map[string]interface{}, 0, len(roadmap.Projects))
+	for _, p := range roadmap.Projects {
+		items := make([]map[string]interface{}, 0, len(p.Items))
+		for _, item := range p.Items {
+			items = append(items, map[string]interface{}{
+				"title":          item.Title,
+				"url":            item.URL,
+				"repo":           item.Repo,
+				"type":           item.Type,
+				"status":         item.Status,
+				"status_updated": r.formatDate(item.StatusUpdatedAt),
+				"done":           item.Done,
+			})
+		}
+		projectData := map[string]interface{}{
+			"title": p.Title,
+			"url":   p.URL,
+			"items": items,
+		}
+		if p.Partial {
+			projectData["partial"] = true
+		}
+		projects = append(projects, projectData)
+	}
+
+	milestones := make([]map[string]interface{}, 0, len(roadmap.Milestones))
+	for _, m := range roadmap.Milestones {
+		milestoneData := map[string]interface{}{
+			"repo":          m.Repo,
+			"title":         m.Title,
+			"url":           m.URL,
+			"state":         m.State,
+			"open_issues":   m.OpenIssues,
+			"closed_issues": m.ClosedIssues,
+			"percent":       fmt.Sprintf("%.0f%%", m.Percent),
+			"user_items":    m.UserItems,
+		}
+		if m.DueOn != nil {
+			milestoneData["due_on"] = r.formatDate(*m.DueOn)
+		}
+		milestones = append(milestones, milestoneData)
+	}
+
+	return map[string]interface{}{
+		"projects":   projects,
+		"milestones": milestones,
+	}
+}
+
+// formatDate 按报告时区格式化日期
+func (r *Reporter) formatDate(t time.Time) string {
+	return t.In(r.location).Format("2006-01-02")
+}
+
+// formatDateTime 按报告时区格式化日期和时间
+func (r *Reporter) formatDateTime(t time.Time) string {
+	return t.In(r.location).Format("2006-01-02 15:04")
+}
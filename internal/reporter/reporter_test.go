@@ -0,0 +1,141 @@
+package reporter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+func TestGithubRepos(t *testing.T) {
+	activity := &github.UserActivity{
+		Commits: []github.CommitInfo{
+			{Repo: "octo/app", URL: "https://api.github.com/repos/octo/app/commits/a1"},
+			{Repo: "team/svc", URL: "https://gitlab.example.com/team/svc/-/commit/b1"},
+			{Repo: "corp/tool", URL: "https://ghe.example.com/api/v3/repos/corp/tool/commits/c1"},
+		},
+		PullRequests: []github.PullRequestInfo{
+			{Repo: "octo/lib", URL: "https://github.com/octo/lib/pull/1"},
+			{Repo: "corp/web", URL: "https://ghe.example.com/corp/web/pull/2"},
+		},
+	}
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"github.com", []string{"octo/app", "octo/lib"}},
+		{"ghe.example.com", []string{"corp/tool", "corp/web"}},
+	}
+	for _, tt := range tests {
+		if got := githubRepos(activity, tt.host); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("githubRepos(%s) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestPatchAllowed(t *testing.T) {
+	activity := &github.UserActivity{
+		Repositories: map[string]*github.RepoInfo{
+			"octo/public":   {Visibility: "public"},
+			"octo/private":  {Visibility: "private"},
+			"octo/internal": {Visibility: "internal"},
+			"octo/legacy":   {}, // 早期缓存的条目没有可见性
+			"octo/secret":   {Visibility: "public"},
+		},
+	}
+
+	tests := []struct {
+		repo           string
+		includePrivate bool
+		want           bool
+	}{
+		{"octo/public", false, true},
+		{"octo/private", false, false},
+		{"octo/private", true, true},
+		{"octo/internal", false, false},
+		{"octo/legacy", true, false},
+		{"octo/unknown", true, false},
+		{"octo/secret", true, false},
+	}
+	for _, tt := range tests {
+		r := &Reporter{patchOptions: PatchOptions{IncludePrivate: tt.includePrivate, ExcludeRepos: []string{"octo/secret*"}}}
+		if got := r.patchAllowed(activity, tt.repo); got != tt.want {
+			t.Errorf("patchAllowed(%s, includePrivate=%v) = %v, want %v", tt.repo, tt.includePrivate, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPatch(t *testing.T) {
+	lines := func(n int) string {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "+line %02d\n", i)
+		}
+		return b.String()
+	}
+	files := []github.FilePatch{
+		{Path: "src/big_test.go", Additions: 2, Patch: lines(2)},
+		{Path: "go.sum", Additions: 40, Patch: lines(40)},
+		{Path: "src/small.go", Additions: 2, Patch: lines(2)},
+		{Path: "assets/logo.png", Additions: 1},
+		{Path: "src/big.go", Additions: 100, Patch: lines(100)},
+		{Path: "src/empty.go", Additions: 1}, // 没有 patch 的文件不输出
+	}
+
+	t.Run("only noise", func(t *testing.T) {
+		if got := formatPatch(files[1:2], 100); got != "" {
+			t.Errorf("formatPatch = %q, want empty", got)
+		}
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		got := formatPatch(files, 0)
+		big, small, test := strings.Index(got, "--- src/big.go (+100 -0)"), strings.Index(got, "--- src/small.go"), strings.Index(got, "--- src/big_test.go")
+		if big < 0 || small < big || test < small {
+			t.Errorf("want big.go, small.go, then the test file:\n%s", got)
+		}
+		if strings.Count(got, "+line") != 104 || strings.Contains(got, "truncated") || strings.Contains(got, "src/empty.go") {
+			t.Errorf("want every line of every patch:\n%s", got)
+		}
+		if !strings.HasSuffix(got, "... (2 lockfile / generated / binary files skipped)\n") {
+			t.Errorf("want the noise summary last:\n%s", got)
+		}
+	})
+
+	t.Run("token budget", func(t *testing.T) {
+		// 每行 3 个 token：big.go 最多占 2/3 的预算，small.go 仍能放下，测试文件的文件头超出预算
+		got := formatPatch(files, 60)
+		for _, want := range []string{
+			"--- src/big.go (+100 -0)\n+line 00\n",
+			"+line 10\n... (89 more lines truncated)\n--- src/small.go (+2 -0)\n+line 00\n+line 01\n",
+			"... (diff omitted for: src/big_test.go)\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("formatPatch missing %q:\n%s", want, got)
+			}
+		}
+	})
+}
+
+func TestFormatDateInLocation(t *testing.T) {
+	r := NewReporter(nil, nil)
+	r.SetLocation(time.FixedZone("UTC+8", 8*3600))
+
+	// UTC 10-11 16:30 在东八区已经是 10-12
+	at := time.Date(2026, 10, 11, 16, 30, 0, 0, time.UTC)
+	if got := r.formatDate(at); got != "2026-10-12" {
+		t.Errorf("formatDate = %s, want 2026-10-12", got)
+	}
+	if got := r.formatDateTime(at); got != "2026-10-12 00:30" {
+		t.Errorf("formatDateTime = %s, want 2026-10-12 00:30", got)
+	}
+
+	r.SetLocation(time.FixedZone("UTC-7", -7*3600))
+	if got := r.formatDateTime(at); got != "2026-10-11 09:30" {
+		t.Errorf("formatDateTime = %s, want 2026-10-11 09:30", got)
+	}
+}
@@ -0,0 +1,460 @@
+package reporter
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github-reports/internal/github"
+)
+
+// 工作流的聚类依据
+const (
+	streamPullRequest = "pull_request" // 同一个 PR
+	streamBranch      = "branch"       // 同一个功能分支
+	streamScope       = "scope"        // 同一个 Conventional Commits scope
+	streamPath        = "path"         // 主要改动在同一目录下
+	streamCommit      = "commit"       // 无法归类的单个 commit
+)
+
+const (
+	// similarityThreshold 是两组 commit message 被视为同一项工作的最低相似度（词集合的 Jaccard 系数）
+	similarityThreshold = 0.5
+	// maxStreamMessages 是每个工作流附带的代表性 message 数量
+	maxStreamMessages = 5
+	// maxStreamPaths 是每个工作流列出的主要目录数量
+	maxStreamPaths = 3
+)
+
+// workStream 是属于同一项工作的一组 PR 和 commit
+type workStream struct {
+	kind    string
+	repo    string
+	name    string
+	items   []github.WorkItem   // PR 工作项
+	commits []github.CommitInfo // 全部 commit，包括 PR 内的
+	tokens  map[string]bool     // commit message 和 PR 标题中的词，用于相似度比较
+}
+
+// clusterWorkStreams 把活动聚类为工作流：先按 PR、功能分支、scope 和主要改动目录分组，
+// 再把同一仓库中零散的分组按目录和 message 相似度并入已有的工作流
+func clusterWorkStreams(activity *github.UserActivity) []*workStream {
+	var streams []*workStream
+	byKey := make(map[string]*workStream)
+	get := func(key, kind, repo, name string) *workStream {
+		key = strings.ToLower(key)
+		if s, ok := byKey[key]; ok {
+			return s
+		}
+		s := &workStream{kind: kind, repo: repo, name: name, tokens: make(map[string]bool)}
+		byKey[key] = s
+		streams = append(streams, s)
+		return s
+	}
+
+	for _, item := range activity.WorkItems {
+		pr := item.PullRequest
+		s := get(fmt.Sprintf("pr:%s#%d", pr.Repo, pr.Number), streamPullRequest, pr.Repo, pr.Title)
+		s.items = append(s.items, item)
+		s.commits = append(s.commits, item.Commits...)
+		addTokens(s.tokens, pr.Title)
+	}
+
+	for _, c := range activity.UnlinkedCommits() {
+		var s *workStream
+		branch := strings.TrimPrefix(c.Branch, "refs/heads/")
+		switch {
+		case branch != "" && !c.IsDefaultBranch:
+			s = get("branch:"+c.Repo+":"+branch, streamBranch, c.Repo, branch)
+		case c.Change.Scope != "":
+			s = get("scope:"+c.Repo+":"+c.Change.Scope, streamScope, c.Repo, c.Change.Scope)
+		case mainPath(c) != "":
+			s = get("path:"+c.Repo+":"+mainPath(c), streamPath, c.Repo, mainPath(c))
+		default:
+			s = get("commit:"+c.Repo+":"+c.SHA, streamCommit, c.Repo, subject(c.Message))
+		}
+		s.commits = append(s.commits, c)
+		addTokens(s.tokens, subject(c.Message))
+	}
+
+	streams = mergeLooseStreams(streams)
+
+	sort.SliceStable(streams, func(i, j int) bool {
+		si, sj := streams[i].size(), streams[j].size()
+		if si != sj {
+			return si > sj
+		}
+		return len(streams[i].commits) > len(streams[j].commits)
+	})
+	return streams
+}
+
+// mergeLooseStreams 把按 scope、目录或单个 commit 形成的分组并入同一仓库中最相近的工作流：
+// 主要目录相同，或 message 相似度达到 similarityThreshold
+func mergeLooseStreams(streams []*workStream) []*workStream {
+	merged := make(map[*workStream]bool)
+	for _, s := range streams {
+		if s.kind == streamPullRequest || s.kind == streamBranch {
+			continue
+		}
+
+		var best *workStream
+		bestScore := 0.0
+		for _, t := range streams {
+			if t == s || merged[t] || !strings.EqualFold(t.repo, s.repo) {
+				continue
+			}
+			score := jaccard(s.tokens, t.tokens)
+			if s.kind == streamPath && t.hasPath(s.name) {
+				score = 1
+			}
+			if score >= similarityThreshold && score > bestScore {
+				best, bestScore = t, score
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		best.commits = append(best.commits, s.commits...)
+		best.items = append(best.items, s.items...)
+		for tok := range s.tokens {
+			best.tokens[tok] = true
+		}
+		merged[s] = true
+	}
+
+	result := make([]*workStream, 0, len(streams))
+	for _, s := range streams {
+		if !merged[s] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// size 返回工作流的改动行数，用于排序
+func (s *workStream) size() int {
+	additions, deletions := s.lines()
+	return additions + deletions
+}
+
+// lines 返回工作流的新增和删除行数：PR 有行数时以 PR 为准，其余按 commit 累加
+func (s *workStream) lines() (additions, deletions int) {
+	inPR := make(map[string]bool)
+	for _, item := range s.items {
+		if pr := item.PullRequest; pr.Additions+pr.Deletions > 0 {
+			additions += pr.Additions
+			deletions += pr.Deletions
+			for _, c := range item.Commits {
+				inPR[c.SHA] = true
+			}
+		}
+	}
+	for _, c := range s.commits {
+		if !inPR[c.SHA] && !c.IsMerge {
+			additions += c.Additions
+			deletions += c.Deletions
+		}
+	}
+	return additions, deletions
+}
+
+// status 判断工作流是否已进入主线：landed、in_progress 或部分完成的 mixed
+func (s *workStream) status() string {
+	landed, pending := 0, 0
+	for _, item := range s.items {
+		if item.PullRequest.MergedAt != nil || item.PullRequest.State == "merged" {
+			landed++
+		} else {
+			pending++
+		}
+	}
+	for _, c := range s.commits {
+		if c.Landed() {
+			landed++
+		} else {
+			pending++
+		}
+	}
+	switch {
+	case pending == 0:
+		return "landed"
+	case landed == 0:
+		return "in_progress"
+	default:
+		return "mixed"
+	}
+}
+
+// paths 返回改动行数最多的几个目录
+func (s *workStream) paths() []string {
+	lines := make(map[string]int)
+	for _, c := range s.commits {
+		for _, f := range c.Files {
+			lines[dirPrefix(f.Path)] += f.Additions + f.Deletions
+		}
+	}
+	delete(lines, "")
+
+	dirs := make([]string, 0, len(lines))
+	for d := range lines {
+		dirs = append(dirs, d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if lines[dirs[i]] != lines[dirs[j]] {
+			return lines[dirs[i]] > lines[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	if len(dirs) > maxStreamPaths {
+		dirs = dirs[:maxStreamPaths]
+	}
+	return dirs
+}
+
+func (s *workStream) hasPath(dir string) bool {
+	for _, d := range s.paths() {
+		if d == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// messages 返回代表性的 commit 标题：按改动行数从大到小，去重后最多 maxStreamMessages 条
+func (s *workStream) messages() []string {
+	commits := append([]github.CommitInfo(nil), s.commits...)
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Additions+commits[i].Deletions > commits[j].Additions+commits[j].Deletions
+	})
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, c := range commits {
+		msg := subject(c.Message)
+		if msg == "" || seen[msg] || c.IsMerge {
+			continue
+		}
+		seen[msg] = true
+		result = append(result, msg)
+		if len(result) == maxStreamMessages {
+			break
+		}
+	}
+	return result
+}
+
+// changeTypes 按变更类型统计工作流中的 PR 和未关联 PR 的 commit
+func (s *workStream) changeTypes() map[string]int {
+	counts := make(map[string]int)
+	for _, item := range s.items {
+		counts[item.PullRequest.Change.Type]++
+	}
+	for _, c := range s.commits {
+		if c.PRNumber == 0 {
+			counts[c.Change.Type]++
+		}
+	}
+	delete(counts, "")
+	delete(counts, github.ChangeOther)
+	return counts
+}
+
+// timeRange 返回工作流中最早和最晚的活动时间
+func (s *workStream) timeRange() (first, last time.Time) {
+	var times []time.Time
+	for _, item := range s.items {
+		if !item.PullRequest.CreatedAt.IsZero() {
+			times = append(times, item.PullRequest.CreatedAt)
+		}
+		if item.PullRequest.MergedAt != nil {
+			times = append(times, *item.PullRequest.MergedAt)
+		}
+	}
+	for _, c := range s.commits {
+		times = append(times, c.Date)
+	}
+	for _, t := range times {
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return first, last
+}
+
+// breaking 判断工作流中是否有破坏性变更
+func (s *workStream) breaking() bool {
+	for _, item := range s.items {
+		if item.PullRequest.Change.Breaking {
+			return true
+		}
+	}
+	for _, c := range s.commits {
+		if c.Change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// mainPath 返回 commit 改动行数最多的目录，没有逐文件数据或只改动根目录文件时为空
+func mainPath(c github.CommitInfo) string {
+	lines := make(map[string]int)
+	for _, f := range c.Files {
+		lines[dirPrefix(f.Path)] += f.Additions + f.Deletions
+	}
+	best := ""
+	for d, n := range lines {
+		if d != "" && (best == "" || n > lines[best] || n == lines[best] && d < best) {
+			best = d
+		}
+	}
+	return best
+}
+
+// dirPrefix 返回文件所在目录的前两级，例如 internal/store/activity.go -> internal/store
+func dirPrefix(file string) string {
+	dir := path.Dir(file)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	parts := strings.SplitN(dir, "/", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, "/")
+}
+
+// subject 返回 message 的第一行
+func subject(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(line)
+}
+
+// streamStopWords 是比较 message 相似度时忽略的词
+var streamStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"feat": true, "fix": true, "chore": true, "refactor": true, "docs": true, "test": true,
+	"add": true, "update": true, "remove": true, "use": true, "when": true, "not": true,
+}
+
+// addTokens 把文本中的词加入集合：英文按单词（至少 3 个字符），中文按相邻两字
+func addTokens(tokens map[string]bool, text string) {
+	var word []rune
+	var han []rune
+	flush := func() {
+		if len(word) >= 3 {
+			if w := string(word); !streamStopWords[w] {
+				tokens[w] = true
+			}
+		}
+		word = word[:0]
+		for i := 0; i+1 < len(han); i++ {
+			tokens[string(han[i:i+2])] = true
+		}
+		han = han[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if len(word) > 0 {
+				flush()
+			}
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(han) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+}
+
+// jaccard 计算两个词集合的 Jaccard 系数
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for tok := range a {
+		if b[tok] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// formatWorkStreams 把工作流格式化为 LLM 输入：每个工作流给出名称、状态、汇总数据、
+// 相关 PR 和代表性的 commit 标题，而不是逐条列出所有 commit。patches 中有对应 diff 的 PR / commit 会附带 diff
+func (r *Reporter) formatWorkStreams(streams []*workStream, patches map[string]string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(streams))
+	for _, s := range streams {
+		additions, deletions := s.lines()
+		data := map[string]interface{}{
+			"name":      s.name,
+			"repo":      s.repo,
+			"grouped":   s.kind,
+			"status":    s.status(),
+			"commits":   len(s.commits),
+			"additions": additions,
+			"deletions": deletions,
+		}
+
+		if first, last := s.timeRange(); !first.IsZero() {
+			data["first"] = r.formatDate(first)
+			data["last"] = r.formatDate(last)
+		}
+		if types := s.changeTypes(); len(types) > 0 {
+			data["change_types"] = types
+		}
+		if paths := s.paths(); len(paths) > 0 {
+			data["paths"] = paths
+		}
+		if len(s.items) > 0 {
+			prs := make([]map[string]interface{}, 0, len(s.items))
+			for _, item := range s.items {
+				prData := r.formatPullRequest(item.PullRequest)
+				if len(item.ClosedIssues) > 0 {
+					prData["closes"] = r.formatIssueRefs(item.ClosedIssues)
+				}
+				if t := item.Testing(); t != nil {
+					prData["testing"] = t
+				}
+				if patch, ok := patches[github.PatchKey(item.PullRequest.Repo, item.PullRequest.Number, "")]; ok {
+					prData["diff"] = patch
+				}
+				prs = append(prs, prData)
+			}
+			data["pull_requests"] = prs
+		}
+		if msgs := s.messages(); len(msgs) > 0 {
+			data["messages"] = msgs
+		}
+		if t := github.CommitTesting(s.commits); t != nil {
+			data["testing"] = t
+		}
+		if s.breaking() {
+			data["breaking"] = true
+		}
+		for _, c := range s.commits {
+			if patch, ok := patches[github.PatchKey(c.Repo, 0, c.SHA)]; ok {
+				data["diff"] = map[string]string{"commit": shortSHA(c.SHA), "patch": patch}
+				break
+			}
+		}
+
+		result = append(result, data)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+// streamSummary 把工作流描述为 "kind:name[sha...]"，按名称排序便于比较
+func streamSummary(streams []*workStream) []string {
+	var result []string
+	for _, s := range streams {
+		var shas []string
+		for _, c := range s.commits {
+			shas = append(shas, c.SHA)
+		}
+		sort.Strings(shas)
+		result = append(result, fmt.Sprintf("%s:%s%v", s.kind, s.name, shas))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestClusterWorkStreams(t *testing.T) {
+	base := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	commit := func(sha, message string, files ...github.FileChange) github.CommitInfo {
+		return github.CommitInfo{SHA: sha, Repo: "octo/app", Message: message, Branch: "main", IsDefaultBranch: true, Date: base, Files: files}
+	}
+	file := func(path string, lines int) github.FileChange {
+		return github.FileChange{Path: path, Additions: lines}
+	}
+
+	tests := []struct {
+		name    string
+		prs     []github.PullRequestInfo
+		commits []github.CommitInfo
+		want    []string
+	}{
+		{
+			name: "branch stream absorbs similar loose commits",
+			commits: []github.CommitInfo{
+				{SHA: "b1", Repo: "octo/app", Message: "parser tokenizer lexer", Branch: "feature/parser", Date: base},
+				commit("l1", "parser tokenizer lexer cleanup"),
+				commit("l2", "unrelated billing work"),
+			},
+			want: []string{"branch:feature/parser[b1 l1]", "commit:unrelated billing work[l2]"},
+		},
+		{
+			name: "path stream merges into a stream that touches its directory",
+			commits: []github.CommitInfo{
+				commit("p1", "storage layer", file("internal/store/a.go", 10), file("internal/api/x.go", 5)),
+				commit("p2", "handler", file("internal/api/y.go", 3)),
+			},
+			want: []string{"path:internal/store[p1 p2]"},
+		},
+		{
+			name: "similarity of exactly 0.5 merges",
+			commits: []github.CommitInfo{
+				commit("s1", "alpha beta gamma"),
+				commit("s2", "alpha beta delta"),
+			},
+			want: []string{"commit:alpha beta delta[s1 s2]"},
+		},
+		{
+			name: "similarity below 0.5 does not merge",
+			commits: []github.CommitInfo{
+				commit("s1", "alpha beta gamma"),
+				commit("s2", "alpha delta epsilon"),
+			},
+			want: []string{"commit:alpha beta gamma[s1]", "commit:alpha delta epsilon[s2]"},
+		},
+		{
+			name: "pull request streams are never merged away",
+			prs: []github.PullRequestInfo{
+				{Repo: "octo/app", Number: 1, Title: "export csv report"},
+				{Repo: "octo/app", Number: 2, Title: "export csv report"},
+			},
+			commits: []github.CommitInfo{
+				{SHA: "a1", Repo: "octo/app", Message: "export csv report", PRNumber: 1, Date: base},
+				{SHA: "a2", Repo: "octo/app", Message: "export csv report", PRNumber: 2, Date: base},
+				commit("l1", "export csv report"),
+			},
+			want: []string{"pull_request:export csv report[a1 l1]", "pull_request:export csv report[a2]"},
+		},
+		{
+			name: "streams in different repositories stay apart",
+			commits: []github.CommitInfo{
+				commit("s1", "alpha beta gamma"),
+				{SHA: "s2", Repo: "octo/lib", Message: "alpha beta gamma", IsDefaultBranch: true, Date: base},
+			},
+			want: []string{"commit:alpha beta gamma[s1]", "commit:alpha beta gamma[s2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := &github.UserActivity{PullRequests: tt.prs, Commits: tt.commits}
+			activity.LinkWorkItems()
+
+			got := streamSummary(clusterWorkStreams(activity))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("streams = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
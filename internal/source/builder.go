@@ -0,0 +1,99 @@
+package source
+
+import (
+	"context"
+	"time"
+
+	"github-reports/internal/config"
+	"github-reports/internal/github"
+)
+
+// 身份配置中支持的来源
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderGitea  = "gitea"
+	ProviderLocal  = "local"
+)
+
+// Renamed 用另一个用户名查询来源，再把结果归到报告对象名下。
+// 同一个人在不同平台上的用户名往往不同。
+type Renamed struct {
+	source   ActivitySource
+	username string
+}
+
+// FetchActivities 使用平台上的用户名拉取活动，返回的 Username 保持为报告对象
+func (r *Renamed) FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error) {
+	activity, err := r.source.FetchActivities(ctx, r.username, since, until)
+	if err != nil {
+		return nil, err
+	}
+	activity.Username = username
+	return activity, nil
+}
+
+// ForUser 根据配置为报告对象组合活动来源。
+//
+// 用户配置了 identities 时只使用列出的身份；否则使用 GitHub API，
+// 并在配置了本地仓库时一并读取（离线模式下只读本地仓库）。
+// githubClient 为 nil 时跳过 GitHub 身份。
+func ForUser(cfg *config.Config, login string, githubClient *github.Client) ActivitySource {
+	user := cfg.User(login)
+
+	var emails []string
+	var identities []config.IdentityConfig
+	if user != nil {
+		emails = user.Emails
+		identities = user.Identities
+	}
+
+	if len(identities) == 0 {
+		if !cfg.Sources.LocalGit.Offline {
+			identities = append(identities, config.IdentityConfig{Provider: ProviderGitHub})
+		}
+		if len(cfg.Sources.LocalGit.Repos) > 0 {
+			identities = append(identities, config.IdentityConfig{Provider: ProviderLocal})
+		}
+	}
+
+	var sources []ActivitySource
+	for _, id := range identities {
+		username := id.Username
+		if username == "" {
+			username = login
+		}
+
+		var src ActivitySource
+		switch id.Provider {
+		case ProviderGitHub:
+			if githubClient == nil {
+				continue
+			}
+			src = github.NewFetcher(githubClient)
+		case ProviderGitLab:
+			src = NewGitLabSource(cfg.Sources.GitLab.BaseURL, cfg.Sources.GitLab.Token, emails)
+		case ProviderGitea:
+			src = NewGiteaSource(cfg.Sources.Gitea.BaseURL, cfg.Sources.Gitea.Token, emails)
+		case ProviderLocal:
+			src = NewLocalGitSource(localRepos(cfg.Sources.LocalGit.Repos), emails)
+		default:
+			continue
+		}
+
+		if username != login {
+			src = &Renamed{source: src, username: username}
+		}
+		sources = append(sources, src)
+	}
+
+	return NewMulti(sources...)
+}
+
+func localRepos(repos []config.LocalRepoConfig) []LocalRepo {
+	result := make([]LocalRepo, 0, len(repos))
+	for _, r := range repos {
+		result = append(result, LocalRepo{Path: r.Path, Name: r.Name})
+	}
+	return result
+}
@@ -0,0 +1,360 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+// GiteaSource 从 Gitea 的 REST API (v1) 获取活动
+type GiteaSource struct {
+	baseURL string
+	token   string
+	emails  []string // 用于在推送中筛选本人 commit，为空时按 commit 关联的 Gitea 账号筛选
+	client  *http.Client
+}
+
+// giteaMaxFeedPages 限制用户动态的翻页次数，避免时间范围过大时无限制地请求
+const giteaMaxFeedPages = 20
+
+// NewGiteaSource 创建一个 Gitea 来源，baseURL 形如 https://gitea.example.com
+func NewGiteaSource(baseURL, token string, emails []string) *GiteaSource {
+	return &GiteaSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		emails:  emails,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaRepo struct {
+	FullName      string `json:"full_name"`
+	HTMLURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type giteaActivity struct {
+	OpType  string     `json:"op_type"`
+	ActUser giteaUser  `json:"act_user"`
+	Repo    *giteaRepo `json:"repo"`
+	RefName string     `json:"ref_name"`
+	Content string     `json:"content"`
+	Created time.Time  `json:"created"`
+}
+
+// giteaPushContent 是 commit_repo 动态中 content 字段的 JSON 内容
+type giteaPushContent struct {
+	Commits []struct {
+		Sha1        string    `json:"Sha1"`
+		Message     string    `json:"Message"`
+		AuthorEmail string    `json:"AuthorEmail"`
+		AuthorName  string    `json:"AuthorName"`
+		Timestamp   time.Time `json:"Timestamp"`
+	} `json:"Commits"`
+}
+
+type giteaCommit struct {
+	SHA     string     `json:"sha"`
+	HTMLURL string     `json:"html_url"`
+	Author  *giteaUser `json:"author"` // 作者邮箱关联的 Gitea 账号，没有关联时为空
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+	Stats *struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	} `json:"stats"`
+}
+
+type giteaPullRequest struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	HTMLURL   string     `json:"html_url"`
+	State     string     `json:"state"`
+	Merged    bool       `json:"merged"`
+	Created   time.Time  `json:"created_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+	Comments  int        `json:"comments"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	User      giteaUser  `json:"user"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+}
+
+type giteaIssue struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	HTMLURL   string     `json:"html_url"`
+	State     string     `json:"state"`
+	Created   time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	Comments  int        `json:"comments"`
+	User      giteaUser  `json:"user"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// giteaReviewStates 将审查相关动态映射为与 GitHub 一致的审查状态
+var giteaReviewStates = map[string]string{
+	"approve_pull_request": "APPROVED",
+	"reject_pull_request":  "CHANGES_REQUESTED",
+	"comment_pull":         "COMMENTED",
+}
+
+// FetchActivities 通过用户动态接口获取推送、合并请求、issue 和审查
+func (s *GiteaSource) FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error) {
+	activity := &github.UserActivity{
+		Username: username,
+		Since:    since,
+		Until:    until,
+	}
+
+	println("[Gitea]", username, "- 正在拉取用户活动...")
+
+	feeds, truncated, err := s.listFeeds(ctx, username, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gitea activities: %w", err)
+	}
+	activity.Truncated = truncated
+
+	seen := make(map[string]bool)
+	for _, feed := range feeds {
+		if feed.Repo == nil || !strings.EqualFold(feed.ActUser.Login, username) {
+			continue
+		}
+		repo := feed.Repo.FullName
+
+		switch feed.OpType {
+		case "commit_repo":
+			commits, err := s.pushCommits(ctx, feed)
+			if err != nil {
+				println("[Gitea] 获取推送的 commit 失败:", repo, err.Error())
+				continue
+			}
+			for _, c := range commits {
+				if seen["commit:"+c.SHA] {
+					continue
+				}
+				seen["commit:"+c.SHA] = true
+				activity.Commits = append(activity.Commits, c)
+			}
+
+		case "create_pull_request", "merge_pull_request":
+			index := contentIndex(feed.Content)
+			key := fmt.Sprintf("pr:%s#%d", repo, index)
+			if index == 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			var pr giteaPullRequest
+			if err := s.get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", repo, index), nil, &pr); err != nil {
+				println("[Gitea] 获取合并请求失败:", key, err.Error())
+				continue
+			}
+			if !strings.EqualFold(pr.User.Login, username) {
+				continue
+			}
+			info := github.PullRequestInfo{
+				Number:    pr.Number,
+				Title:     pr.Title,
+				Repo:      repo,
+				URL:       pr.HTMLURL,
+				State:     pr.State,
+				CreatedAt: pr.Created,
+				MergedAt:  pr.MergedAt,
+				Comments:  pr.Comments,
+				Additions: pr.Additions,
+				Deletions: pr.Deletions,
+			}
+			if pr.Merged {
+				info.State = "merged"
+			}
+			if pr.Milestone != nil {
+				info.Milestone = pr.Milestone.Title
+			}
+			activity.PullRequests = append(activity.PullRequests, info)
+
+		case "create_issue", "close_issue":
+			index := contentIndex(feed.Content)
+			key := fmt.Sprintf("issue:%s#%d", repo, index)
+			if index == 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			var issue giteaIssue
+			if err := s.get(ctx, fmt.Sprintf("/repos/%s/issues/%d", repo, index), nil, &issue); err != nil {
+				println("[Gitea] 获取 issue 失败:", key, err.Error())
+				continue
+			}
+			if issue.PullRequest != nil || !strings.EqualFold(issue.User.Login, username) {
+				continue
+			}
+			info := github.IssueInfo{
+				Number:    issue.Number,
+				Title:     issue.Title,
+				Repo:      repo,
+				URL:       issue.HTMLURL,
+				State:     issue.State,
+				CreatedAt: issue.Created,
+				ClosedAt:  issue.ClosedAt,
+				Comments:  issue.Comments,
+			}
+			if issue.Milestone != nil {
+				info.Milestone = issue.Milestone.Title
+			}
+			activity.Issues = append(activity.Issues, info)
+
+		case "approve_pull_request", "reject_pull_request", "comment_pull":
+			index := contentIndex(feed.Content)
+			state := giteaReviewStates[feed.OpType]
+			key := fmt.Sprintf("review:%s#%d:%s", repo, index, state)
+			if index == 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			activity.Reviews = append(activity.Reviews, github.ReviewInfo{
+				PRNumber:  index,
+				Repo:      repo,
+				URL:       fmt.Sprintf("%s/pulls/%d", feed.Repo.HTMLURL, index),
+				State:     state,
+				CreatedAt: feed.Created,
+			})
+		}
+	}
+
+	activity.LinkWorkItems()
+
+	println("[Gitea]", username, "- 找到", len(activity.Commits), "个 Commits,", len(activity.PullRequests), "个 Pull Requests,", len(activity.Issues), "个 Issues,", len(activity.Reviews), "个 Reviews")
+
+	return activity, nil
+}
+
+// listFeeds 分页获取用户动态；动态按时间倒序排列，遇到早于 since 的条目即可停止。
+// 最多 giteaMaxFeedPages 页，超出时返回 truncated
+func (s *GiteaSource) listFeeds(ctx context.Context, username string, since, until time.Time) ([]giteaActivity, bool, error) {
+	var result []giteaActivity
+	params := url.Values{
+		"only-performed-by": {"true"},
+		"limit":             {"50"},
+	}
+
+	for page := 1; ; page++ {
+		if page > giteaMaxFeedPages {
+			println("[Gitea] 动态超过", giteaMaxFeedPages, "页，后续动态被忽略")
+			return result, true, nil
+		}
+		params.Set("page", strconv.Itoa(page))
+
+		var feeds []giteaActivity
+		if err := s.get(ctx, "/users/"+url.PathEscape(username)+"/activities/feeds", params, &feeds); err != nil {
+			return nil, false, err
+		}
+		if len(feeds) == 0 {
+			return result, false, nil
+		}
+
+		for _, feed := range feeds {
+			if feed.Created.Before(since) {
+				return result, false, nil
+			}
+			if feed.Created.After(until) {
+				continue
+			}
+			result = append(result, feed)
+		}
+	}
+}
+
+// pushCommits 解析推送动态中的 commit 并补充增删行数
+func (s *GiteaSource) pushCommits(ctx context.Context, feed giteaActivity) ([]github.CommitInfo, error) {
+	var content giteaPushContent
+	if err := json.Unmarshal([]byte(feed.Content), &content); err != nil {
+		return nil, fmt.Errorf("failed to parse push content: %w", err)
+	}
+
+	repo := feed.Repo.FullName
+	branch := branchFromGiteaRef(feed.RefName)
+
+	var result []github.CommitInfo
+	for _, c := range content.Commits {
+		if len(s.emails) > 0 && !matchesEmail(s.emails, c.AuthorEmail) {
+			continue
+		}
+
+		var detail giteaCommit
+		if err := s.get(ctx, fmt.Sprintf("/repos/%s/git/commits/%s", repo, c.Sha1), url.Values{"stat": {"true"}}, &detail); err != nil {
+			return nil, err
+		}
+		// 未配置邮箱时只保留作者账号就是推送者本人的 commit
+		if len(s.emails) == 0 && (detail.Author == nil || !strings.EqualFold(detail.Author.Login, feed.ActUser.Login)) {
+			continue
+		}
+
+		info := github.CommitInfo{
+			SHA:             c.Sha1,
+			Message:         strings.TrimSpace(c.Message),
+			Repo:            repo,
+			URL:             detail.HTMLURL,
+			Author:          c.AuthorName,
+			Date:            feed.Created,
+			Branch:          branch,
+			IsDefaultBranch: branch == feed.Repo.DefaultBranch,
+			Refs:            []string{repo + ":" + branch},
+			IsMerge:         len(detail.Parents) > 1,
+		}
+		if detail.Stats != nil && !info.IsMerge {
+			info.Additions = detail.Stats.Additions
+			info.Deletions = detail.Stats.Deletions
+		}
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// get 调用 Gitea API 并解析 JSON 响应
+func (s *GiteaSource) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	u := s.baseURL + "/api/v1" + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "token "+s.token)
+	}
+
+	return doJSON(s.client, req, out)
+}
+
+// contentIndex 解析动态 content 中的编号，格式为 "12|标题"
+func contentIndex(content string) int {
+	index, _, _ := strings.Cut(content, "|")
+	n, _ := strconv.Atoi(index)
+	return n
+}
+
+func branchFromGiteaRef(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
@@ -0,0 +1,124 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newGiteaServer 启动一个假的 Gitea API：用户 alice 推送了一次包含 alice 和 bob 两个 commit 的提交，
+// bob 的 commit 没有关联任何 Gitea 账号
+func newGiteaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	content, err := json.Marshal(map[string]interface{}{
+		"Commits": []map[string]interface{}{
+			{"Sha1": "a1", "Message": "feat: add parser", "AuthorEmail": "alice@users.noreply.example.com", "AuthorName": "alice"},
+			{"Sha1": "b1", "Message": "fix: typo", "AuthorEmail": "bob@example.com", "AuthorName": "Bob"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal push content: %v", err)
+	}
+
+	routes := map[string]interface{}{
+		"/api/v1/repos/octo/app/git/commits/a1": map[string]interface{}{
+			"sha": "a1", "author": map[string]string{"login": "alice"},
+			"parents": []map[string]string{{"sha": "p0"}}, "stats": map[string]int{"additions": 3, "deletions": 1},
+		},
+		"/api/v1/repos/octo/app/git/commits/b1": map[string]interface{}{
+			"sha": "b1", "author": nil,
+			"parents": []map[string]string{{"sha": "a1"}}, "stats": map[string]int{"additions": 1, "deletions": 1},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/users/alice/activities/feeds" {
+			var feeds []map[string]interface{}
+			if r.URL.Query().Get("page") == "1" {
+				feeds = append(feeds, map[string]interface{}{
+					"op_type":  "commit_repo",
+					"act_user": map[string]string{"login": "alice"},
+					"repo":     map[string]string{"full_name": "octo/app", "html_url": "https://gitea.example.com/octo/app", "default_branch": "main"},
+					"ref_name": "refs/heads/main",
+					"content":  string(content),
+					"created":  testBase.Add(time.Hour),
+				})
+			}
+			json.NewEncoder(w).Encode(feeds)
+			return
+		}
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			http.Error(w, fmt.Sprintf("no route for %s", r.URL.Path), http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGiteaSourceAuthorFiltering(t *testing.T) {
+	tests := []struct {
+		name   string
+		emails []string
+		want   []string
+	}{
+		{"configured emails", []string{"bob@example.com"}, []string{"b1"}},
+		{"linked account when no emails are configured", nil, []string{"a1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewGiteaSource(newGiteaServer(t).URL, "token", tt.emails)
+
+			activity, err := src.FetchActivities(context.Background(), "alice", testBase, testBase.Add(24*time.Hour))
+			if err != nil {
+				t.Fatalf("FetchActivities: %v", err)
+			}
+			var got []string
+			for _, c := range activity.Commits {
+				got = append(got, c.SHA)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("commits = %v, want %v", got, tt.want)
+			}
+			for _, c := range activity.Commits {
+				if c.Branch != "main" || !c.IsDefaultBranch {
+					t.Errorf("commit %s branch = %q (default %v), want main", c.SHA, c.Branch, c.IsDefaultBranch)
+				}
+			}
+		})
+	}
+}
+
+func TestGiteaSourceFeedPageLimit(t *testing.T) {
+	var pages atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages.Add(1)
+		// 每一页都是范围内的动态，只能靠翻页上限停止
+		json.NewEncoder(w).Encode([]map[string]interface{}{{
+			"op_type":  "star_repo",
+			"act_user": map[string]string{"login": "alice"},
+			"created":  testBase.Add(time.Hour),
+		}})
+	}))
+	t.Cleanup(srv.Close)
+
+	activity, err := NewGiteaSource(srv.URL, "token", nil).FetchActivities(context.Background(), "alice", testBase, testBase.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+	if n := pages.Load(); n != giteaMaxFeedPages {
+		t.Errorf("requested %d feed pages, want %d", n, giteaMaxFeedPages)
+	}
+	if !activity.Truncated {
+		t.Error("Truncated = false, want true after hitting the page cap")
+	}
+}
@@ -0,0 +1,494 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+// GitLabSource 从自建 GitLab 的 REST API (v4) 获取活动
+type GitLabSource struct {
+	baseURL string
+	token   string
+	emails  []string // 用于在推送中筛选本人 commit，为空时按 GitLab 账号的用户名和公开邮箱筛选
+	client  *http.Client
+
+	projects map[int]*gitlabProject
+	author   *gitlabUser // 未配置邮箱时用于筛选 commit 的账号信息
+}
+
+// gitlabMaxEventPages 限制用户事件的翻页次数，避免时间范围过大时无限制地请求
+const gitlabMaxEventPages = 20
+
+// gitlabMaxDiffPages 限制单个合并请求 diff 的翻页次数，超出部分不计入增删行数
+const gitlabMaxDiffPages = 5
+
+// NewGitLabSource 创建一个 GitLab 来源，baseURL 形如 https://gitlab.example.com
+func NewGitLabSource(baseURL, token string, emails []string) *GitLabSource {
+	return &GitLabSource{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		emails:   emails,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		projects: make(map[int]*gitlabProject),
+	}
+}
+
+type gitlabUser struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	PublicEmail string `json:"public_email"`
+	CommitEmail string `json:"commit_email"` // 只有管理员或本人的 token 才能看到
+}
+
+type gitlabProject struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+type gitlabEvent struct {
+	ActionName  string    `json:"action_name"`
+	TargetType  string    `json:"target_type"`
+	TargetIID   int       `json:"target_iid"`
+	TargetTitle string    `json:"target_title"`
+	ProjectID   int       `json:"project_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	PushData    *struct {
+		CommitCount int    `json:"commit_count"`
+		Ref         string `json:"ref"`
+		RefType     string `json:"ref_type"`
+		CommitFrom  string `json:"commit_from"`
+		CommitTo    string `json:"commit_to"`
+	} `json:"push_data"`
+	Note *struct {
+		NoteableType string `json:"noteable_type"`
+		NoteableIID  int    `json:"noteable_iid"`
+	} `json:"note"`
+}
+
+type gitlabCommit struct {
+	ID          string    `json:"id"`
+	Message     string    `json:"message"`
+	AuthorName  string    `json:"author_name"`
+	AuthorEmail string    `json:"author_email"`
+	CreatedAt   time.Time `json:"created_at"`
+	WebURL      string    `json:"web_url"`
+	ParentIDs   []string  `json:"parent_ids"`
+	Stats       *struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	} `json:"stats"`
+}
+
+type gitlabMergeRequest struct {
+	IID          int        `json:"iid"`
+	Title        string     `json:"title"`
+	WebURL       string     `json:"web_url"`
+	State        string     `json:"state"` // opened, closed, merged, locked
+	CreatedAt    time.Time  `json:"created_at"`
+	MergedAt     *time.Time `json:"merged_at"`
+	UserNotes    int        `json:"user_notes_count"`
+	SourceBranch string     `json:"source_branch"`
+	Author       gitlabUser `json:"author"`
+	Milestone    *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+}
+
+type gitlabDiff struct {
+	Diff string `json:"diff"`
+}
+
+type gitlabIssue struct {
+	IID       int        `json:"iid"`
+	Title     string     `json:"title"`
+	WebURL    string     `json:"web_url"`
+	State     string     `json:"state"` // opened, closed
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	UserNotes int        `json:"user_notes_count"`
+	Author    gitlabUser `json:"author"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+}
+
+// FetchActivities 通过用户事件接口获取推送、合并请求、issue 和审批
+func (s *GitLabSource) FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error) {
+	activity := &github.UserActivity{
+		Username: username,
+		Since:    since,
+		Until:    until,
+	}
+
+	println("[GitLab]", username, "- 正在拉取用户活动...")
+
+	var users []gitlabUser
+	if err := s.get(ctx, "/users", url.Values{"username": {username}}, &users); err != nil {
+		return nil, fmt.Errorf("failed to look up gitlab user: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("gitlab user %s not found", username)
+	}
+
+	if len(s.emails) == 0 {
+		// /users 列表不含邮箱，需要单独获取账号详情
+		var author gitlabUser
+		if err := s.get(ctx, fmt.Sprintf("/users/%d", users[0].ID), nil, &author); err != nil {
+			return nil, fmt.Errorf("failed to get gitlab user: %w", err)
+		}
+		s.author = &author
+	}
+
+	events, truncated, err := s.listEvents(ctx, users[0].ID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gitlab events: %w", err)
+	}
+	activity.Truncated = truncated
+
+	seenCommits := make(map[string]bool)
+	seenMRs := make(map[string]bool)
+	seenIssues := make(map[string]bool)
+	seenReviews := make(map[string]bool)
+
+	for _, e := range events {
+		project, err := s.project(ctx, e.ProjectID)
+		if err != nil {
+			println("[GitLab] 获取项目失败:", e.ProjectID, err.Error())
+			continue
+		}
+		repo := project.PathWithNamespace
+		key := fmt.Sprintf("%s!%d", repo, e.TargetIID)
+
+		switch {
+		case e.PushData != nil && e.PushData.RefType == "branch" && e.PushData.CommitTo != "":
+			commits, err := s.pushCommits(ctx, project, e)
+			if err != nil {
+				println("[GitLab] 获取推送的 commit 失败:", repo, err.Error())
+				continue
+			}
+			for _, c := range commits {
+				if seenCommits[c.SHA] {
+					continue
+				}
+				seenCommits[c.SHA] = true
+				activity.Commits = append(activity.Commits, c)
+			}
+
+		case e.TargetType == "MergeRequest" && (e.ActionName == "opened" || e.ActionName == "accepted"):
+			if seenMRs[key] {
+				continue
+			}
+			seenMRs[key] = true
+
+			var mr gitlabMergeRequest
+			if err := s.get(ctx, fmt.Sprintf("/projects/%d/merge_requests/%d", project.ID, e.TargetIID), nil, &mr); err != nil {
+				println("[GitLab] 获取合并请求失败:", key, err.Error())
+				continue
+			}
+			if !strings.EqualFold(mr.Author.Username, username) {
+				continue
+			}
+			pr := github.PullRequestInfo{
+				Number:    mr.IID,
+				Title:     mr.Title,
+				Repo:      repo,
+				URL:       mr.WebURL,
+				State:     gitlabState(mr.State),
+				CreatedAt: mr.CreatedAt,
+				MergedAt:  mr.MergedAt,
+				Comments:  mr.UserNotes,
+			}
+			if mr.Milestone != nil {
+				pr.Milestone = mr.Milestone.Title
+			}
+			pr.Additions, pr.Deletions, err = s.mergeRequestLines(ctx, project.ID, mr.IID)
+			if err != nil {
+				println("[GitLab] 获取合并请求 diff 失败:", key, err.Error())
+			}
+			activity.PullRequests = append(activity.PullRequests, pr)
+
+		case e.TargetType == "Issue" && (e.ActionName == "opened" || e.ActionName == "closed"):
+			if seenIssues[key] {
+				continue
+			}
+			seenIssues[key] = true
+
+			var issue gitlabIssue
+			if err := s.get(ctx, fmt.Sprintf("/projects/%d/issues/%d", project.ID, e.TargetIID), nil, &issue); err != nil {
+				println("[GitLab] 获取 issue 失败:", key, err.Error())
+				continue
+			}
+			if !strings.EqualFold(issue.Author.Username, username) {
+				continue
+			}
+			info := github.IssueInfo{
+				Number:    issue.IID,
+				Title:     issue.Title,
+				Repo:      repo,
+				URL:       issue.WebURL,
+				State:     gitlabState(issue.State),
+				CreatedAt: issue.CreatedAt,
+				ClosedAt:  issue.ClosedAt,
+				Comments:  issue.UserNotes,
+			}
+			if issue.Milestone != nil {
+				info.Milestone = issue.Milestone.Title
+			}
+			activity.Issues = append(activity.Issues, info)
+
+		case e.TargetType == "MergeRequest" && e.ActionName == "approved":
+			if seenReviews[key+"approved"] {
+				continue
+			}
+			seenReviews[key+"approved"] = true
+			activity.Reviews = append(activity.Reviews, github.ReviewInfo{
+				PRNumber:  e.TargetIID,
+				PRTitle:   e.TargetTitle,
+				Repo:      repo,
+				URL:       fmt.Sprintf("%s/-/merge_requests/%d", project.WebURL, e.TargetIID),
+				State:     "APPROVED",
+				CreatedAt: e.CreatedAt,
+			})
+
+		case e.Note != nil && e.Note.NoteableType == "MergeRequest":
+			key = fmt.Sprintf("%s!%d", repo, e.Note.NoteableIID)
+			if seenReviews[key+"commented"] {
+				continue
+			}
+			seenReviews[key+"commented"] = true
+			activity.Reviews = append(activity.Reviews, github.ReviewInfo{
+				PRNumber:  e.Note.NoteableIID,
+				PRTitle:   e.TargetTitle,
+				Repo:      repo,
+				URL:       fmt.Sprintf("%s/-/merge_requests/%d", project.WebURL, e.Note.NoteableIID),
+				State:     "COMMENTED",
+				CreatedAt: e.CreatedAt,
+			})
+		}
+	}
+
+	activity.LinkWorkItems()
+
+	println("[GitLab]", username, "- 找到", len(activity.Commits), "个 Commits,", len(activity.PullRequests), "个 Merge Requests,", len(activity.Issues), "个 Issues,", len(activity.Reviews), "个 Reviews")
+
+	return activity, nil
+}
+
+// listEvents 分页获取用户事件，最多 gitlabMaxEventPages 页，超出时返回 truncated。
+// GitLab 的 after/before 只精确到天且不含边界，因此多取一天再按时间过滤
+func (s *GitLabSource) listEvents(ctx context.Context, userID int, since, until time.Time) ([]gitlabEvent, bool, error) {
+	var result []gitlabEvent
+	params := url.Values{
+		"after":    {since.AddDate(0, 0, -1).Format("2006-01-02")},
+		"before":   {until.AddDate(0, 0, 1).Format("2006-01-02")},
+		"per_page": {"100"},
+	}
+
+	for page := 1; ; page++ {
+		if page > gitlabMaxEventPages {
+			println("[GitLab] 事件超过", gitlabMaxEventPages, "页，后续事件被忽略")
+			return result, true, nil
+		}
+		params.Set("page", fmt.Sprint(page))
+
+		var events []gitlabEvent
+		if err := s.get(ctx, fmt.Sprintf("/users/%d/events", userID), params, &events); err != nil {
+			return nil, false, err
+		}
+		if len(events) == 0 {
+			return result, false, nil
+		}
+
+		for _, e := range events {
+			if e.CreatedAt.Before(since) || e.CreatedAt.After(until) {
+				continue
+			}
+			result = append(result, e)
+		}
+	}
+}
+
+// pushCommits 获取一次推送包含的 commit 及其增删行数
+func (s *GitLabSource) pushCommits(ctx context.Context, project *gitlabProject, e gitlabEvent) ([]github.CommitInfo, error) {
+	var commits []gitlabCommit
+	if e.PushData.CommitFrom != "" {
+		var compare struct {
+			Commits []gitlabCommit `json:"commits"`
+		}
+		params := url.Values{"from": {e.PushData.CommitFrom}, "to": {e.PushData.CommitTo}}
+		if err := s.get(ctx, fmt.Sprintf("/projects/%d/repository/compare", project.ID), params, &compare); err != nil {
+			return nil, err
+		}
+		commits = compare.Commits
+	} else {
+		// 新建分支的推送没有 commit_from，只统计分支头的 commit
+		commits = []gitlabCommit{{ID: e.PushData.CommitTo}}
+	}
+
+	var result []github.CommitInfo
+	for _, c := range commits {
+		var detail gitlabCommit
+		path := fmt.Sprintf("/projects/%d/repository/commits/%s", project.ID, url.PathEscape(c.ID))
+		if err := s.get(ctx, path, url.Values{"stats": {"true"}}, &detail); err != nil {
+			return nil, err
+		}
+		if !s.isAuthor(detail) {
+			continue
+		}
+
+		info := github.CommitInfo{
+			SHA:             detail.ID,
+			Message:         strings.TrimSpace(detail.Message),
+			Repo:            project.PathWithNamespace,
+			URL:             detail.WebURL,
+			Author:          detail.AuthorName,
+			Date:            e.CreatedAt,
+			Branch:          e.PushData.Ref,
+			IsDefaultBranch: e.PushData.Ref == project.DefaultBranch,
+			Refs:            []string{project.PathWithNamespace + ":" + e.PushData.Ref},
+			IsMerge:         len(detail.ParentIDs) > 1,
+		}
+		if detail.Stats != nil && !info.IsMerge {
+			info.Additions = detail.Stats.Additions
+			info.Deletions = detail.Stats.Deletions
+		}
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// mergeRequestLines 统计合并请求的增删行数。
+// GitLab 的合并请求接口不返回行数，只能从 diffs 接口的 diff 文本中数出来；过大而被折叠的文件没有 diff 文本，不计入
+func (s *GitLabSource) mergeRequestLines(ctx context.Context, projectID, iid int) (additions, deletions int, err error) {
+	params := url.Values{"per_page": {"100"}}
+	for page := 1; page <= gitlabMaxDiffPages; page++ {
+		params.Set("page", fmt.Sprint(page))
+
+		var diffs []gitlabDiff
+		if err := s.get(ctx, fmt.Sprintf("/projects/%d/merge_requests/%d/diffs", projectID, iid), params, &diffs); err != nil {
+			return 0, 0, err
+		}
+		for _, d := range diffs {
+			// GitLab 的 diff 文本不含 ---/+++ 文件头，以 + / - 开头的行都是改动
+			for _, line := range strings.Split(d.Diff, "\n") {
+				switch {
+				case strings.HasPrefix(line, "+"):
+					additions++
+				case strings.HasPrefix(line, "-"):
+					deletions++
+				}
+			}
+		}
+		if len(diffs) < 100 {
+			break
+		}
+	}
+	return additions, deletions, nil
+}
+
+// project 获取并缓存项目信息
+func (s *GitLabSource) project(ctx context.Context, id int) (*gitlabProject, error) {
+	if p, ok := s.projects[id]; ok {
+		return p, nil
+	}
+
+	var p gitlabProject
+	if err := s.get(ctx, fmt.Sprintf("/projects/%d", id), nil, &p); err != nil {
+		return nil, err
+	}
+
+	s.projects[id] = &p
+	return &p, nil
+}
+
+// get 调用 GitLab API 并解析 JSON 响应
+func (s *GitLabSource) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	u := s.baseURL + "/api/v4" + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if s.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", s.token)
+	}
+
+	return doJSON(s.client, req, out)
+}
+
+// gitlabState 将 GitLab 的状态映射为与 GitHub 一致的取值
+func gitlabState(state string) string {
+	if state == "opened" {
+		return "open"
+	}
+	return state
+}
+
+// isAuthor 判断 commit 是否由该用户提交：配置了邮箱时按邮箱匹配，
+// 否则按 GitLab 账号的公开邮箱、commit 邮箱、用户名和显示名匹配
+func (s *GitLabSource) isAuthor(c gitlabCommit) bool {
+	if len(s.emails) > 0 {
+		return matchesEmail(s.emails, c.AuthorEmail)
+	}
+	if s.author == nil {
+		return false
+	}
+	var emails []string
+	for _, e := range []string{s.author.PublicEmail, s.author.CommitEmail} {
+		if e != "" {
+			emails = append(emails, e)
+		}
+	}
+	return matchesEmail(emails, c.AuthorEmail) ||
+		strings.EqualFold(c.AuthorName, s.author.Username) ||
+		(s.author.Name != "" && strings.EqualFold(c.AuthorName, s.author.Name))
+}
+
+// matchesEmail 判断作者邮箱是否在列表中
+func matchesEmail(emails []string, email string) bool {
+	for _, e := range emails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// doJSON 执行请求并将 2xx 响应解析到 out
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d: %s", req.URL.Path, resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
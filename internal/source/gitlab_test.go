@@ -0,0 +1,153 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newGitLabServer 启动一个假的 GitLab API：用户 alice 推送了一次包含 alice 和 bob 两个 commit 的提交，
+// 并创建了合并请求 !3；事件接口对任何页码都返回同样的事件，用于验证翻页上限
+func newGitLabServer(t *testing.T) (*httptest.Server, func() int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	eventPages := 0
+	pushed := testBase.Add(time.Hour)
+
+	routes := map[string]interface{}{
+		"/api/v4/users": []map[string]interface{}{{"id": 1, "username": "alice"}},
+		"/api/v4/users/1": map[string]interface{}{
+			"id": 1, "username": "alice", "name": "Alice Liddell", "public_email": "alice@example.com",
+		},
+		"/api/v4/projects/10": map[string]interface{}{
+			"id": 10, "path_with_namespace": "octo/app", "web_url": "https://gitlab.example.com/octo/app", "default_branch": "main",
+		},
+		"/api/v4/projects/10/repository/compare": map[string]interface{}{
+			"commits": []map[string]interface{}{{"id": "a1"}, {"id": "b1"}},
+		},
+		"/api/v4/projects/10/repository/commits/a1": map[string]interface{}{
+			"id": "a1", "message": "feat: add parser", "author_name": "Alice Liddell", "author_email": "alice@example.com",
+			"parent_ids": []string{"p0"}, "stats": map[string]int{"additions": 3, "deletions": 1},
+		},
+		"/api/v4/projects/10/repository/commits/b1": map[string]interface{}{
+			"id": "b1", "message": "fix: typo", "author_name": "Bob", "author_email": "bob@example.com",
+			"parent_ids": []string{"a1"}, "stats": map[string]int{"additions": 1, "deletions": 1},
+		},
+		"/api/v4/projects/10/merge_requests/3": map[string]interface{}{
+			"iid": 3, "title": "feat: add parser", "state": "merged", "author": map[string]string{"username": "alice"},
+		},
+		"/api/v4/projects/10/merge_requests/3/diffs": []map[string]string{
+			{"diff": "@@ -1,2 +1,3 @@\n package p\n-func Parse() {}\n+func Parse() error {\n+\treturn nil\n+}\n"},
+			{"diff": "@@ -0,0 +1 @@\n+--- a table rule, not a header\n"},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/users/1/events" {
+			mu.Lock()
+			eventPages++
+			mu.Unlock()
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{
+					"action_name": "pushed to",
+					"project_id":  10,
+					"created_at":  pushed.Add(-time.Duration(page) * time.Second),
+					"push_data": map[string]interface{}{
+						"commit_count": 2, "ref": "main", "ref_type": "branch", "commit_from": "p0", "commit_to": "b1",
+					},
+				},
+				{
+					"action_name": "opened",
+					"target_type": "MergeRequest",
+					"target_iid":  3,
+					"project_id":  10,
+					"created_at":  pushed.Add(-time.Duration(page) * time.Second),
+				},
+			})
+			return
+		}
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			http.Error(w, fmt.Sprintf("no route for %s", r.URL.Path), http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return eventPages
+	}
+}
+
+func TestGitLabSourceAuthorFiltering(t *testing.T) {
+	tests := []struct {
+		name   string
+		emails []string
+		want   []string
+	}{
+		{"configured emails", []string{"BOB@example.com"}, []string{"b1"}},
+		{"account email and name when no emails are configured", nil, []string{"a1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newGitLabServer(t)
+			src := NewGitLabSource(srv.URL, "token", tt.emails)
+
+			activity, err := src.FetchActivities(context.Background(), "alice", testBase, testBase.Add(24*time.Hour))
+			if err != nil {
+				t.Fatalf("FetchActivities: %v", err)
+			}
+			var got []string
+			for _, c := range activity.Commits {
+				got = append(got, c.SHA)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("commits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitLabSourceEventPageLimit(t *testing.T) {
+	srv, eventPages := newGitLabServer(t)
+	src := NewGitLabSource(srv.URL, "token", []string{"alice@example.com"})
+
+	activity, err := src.FetchActivities(context.Background(), "alice", testBase, testBase.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+	if n := eventPages(); n != gitlabMaxEventPages {
+		t.Errorf("requested %d event pages, want %d", n, gitlabMaxEventPages)
+	}
+	if len(activity.Commits) != 1 || activity.Commits[0].SHA != "a1" || !activity.Commits[0].IsDefaultBranch {
+		t.Errorf("commits = %+v, want a1 on main", activity.Commits)
+	}
+}
+
+func TestGitLabSourceMergeRequestLines(t *testing.T) {
+	srv, _ := newGitLabServer(t)
+	src := NewGitLabSource(srv.URL, "token", []string{"alice@example.com"})
+
+	activity, err := src.FetchActivities(context.Background(), "alice", testBase, testBase.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+	if len(activity.PullRequests) != 1 {
+		t.Fatalf("pull requests = %+v, want !3 once", activity.PullRequests)
+	}
+	if pr := activity.PullRequests[0]; pr.Additions != 4 || pr.Deletions != 1 {
+		t.Errorf("!3 = +%d -%d, want +4 -1", pr.Additions, pr.Deletions)
+	}
+}
@@ -0,0 +1,197 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github-reports/internal/github"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// LocalRepo 是磁盘上的一个本地克隆
+type LocalRepo struct {
+	Path string
+	Name string // 报告中显示的仓库名，默认为目录名
+}
+
+// LocalGitSource 直接读取本地 git 仓库生成活动数据，不依赖网络
+type LocalGitSource struct {
+	repos      []LocalRepo
+	identities []string // 需要匹配的作者邮箱或姓名（不区分大小写）
+}
+
+// NewLocalGitSource 创建一个本地 git 来源。
+// identities 是该用户在 commit 中使用的邮箱或姓名；用户名本身总会参与匹配。
+func NewLocalGitSource(repos []LocalRepo, identities []string) *LocalGitSource {
+	return &LocalGitSource{repos: repos, identities: identities}
+}
+
+// FetchActivities 遍历所有本地仓库中时间范围内该用户提交的 commit
+func (s *LocalGitSource) FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error) {
+	activity := &github.UserActivity{
+		Username: username,
+		Since:    since,
+		Until:    until,
+	}
+
+	println("[LocalGit]", username, "- 正在读取", len(s.repos), "个本地仓库...")
+
+	for _, repo := range s.repos {
+		commits, err := s.repoCommits(ctx, repo, username, since, until)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", repo.Path, err)
+		}
+		activity.Commits = append(activity.Commits, commits...)
+	}
+
+	activity.LinkWorkItems()
+
+	println("[LocalGit]", username, "- 找到", len(activity.Commits), "个 Commits")
+
+	return activity, nil
+}
+
+func (s *LocalGitSource) repoCommits(ctx context.Context, repo LocalRepo, username string, since, until time.Time) ([]github.CommitInfo, error) {
+	r, err := git.PlainOpen(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := repo.Name
+	if name == "" {
+		name = filepath.Base(filepath.Clean(repo.Path))
+	}
+
+	inRange, branches, defaultBranch, err := rangeCommits(r, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []github.CommitInfo
+	for _, c := range inRange {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !s.matches(c.Author, username) {
+			continue
+		}
+
+		info := github.CommitInfo{
+			SHA:     c.Hash.String(),
+			Message: strings.TrimSpace(c.Message),
+			Repo:    name,
+			Author:  c.Author.Name,
+			Date:    c.Author.When,
+			IsMerge: c.NumParents() > 1,
+		}
+
+		branch := branches[c.Hash]
+		info.Branch = branch
+		info.IsDefaultBranch = branch == defaultBranch
+		info.Refs = []string{name + ":" + branch}
+
+		// merge commit 的 numstat 相对第一个父提交计算，会把整条分支的改动重复统计一次
+		if !info.IsMerge {
+			stats, err := c.StatsContext(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute stats for %s: %w", c.Hash, err)
+			}
+			for _, st := range stats {
+				info.Additions += st.Addition
+				info.Deletions += st.Deletion
+				info.Files = append(info.Files, github.NewFileChange(st.Name, st.Addition, st.Deletion))
+			}
+		}
+
+		commits = append(commits, info)
+	}
+
+	return commits, nil
+}
+
+// matches 判断 commit 作者是否为目标用户
+func (s *LocalGitSource) matches(author object.Signature, username string) bool {
+	if strings.EqualFold(author.Name, username) || strings.EqualFold(author.Email, username) {
+		return true
+	}
+	for _, id := range s.identities {
+		if strings.EqualFold(author.Email, id) || strings.EqualFold(author.Name, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeCommits 返回 HEAD 和所有本地 / 远程分支上作者时间在 [since, until] 内的 commit，以及每个 commit 所在的分支；
+// 默认分支（HEAD 指向的分支）优先。
+// 每个分支按提交时间从新到旧遍历；作者时间通常不晚于提交时间，遇到提交时间早于 since 的 commit 即可停止，不必走完整段历史
+func rangeCommits(r *git.Repository, since, until time.Time) ([]*object.Commit, map[plumbing.Hash]string, string, error) {
+	var commits []*object.Commit
+	index := make(map[plumbing.Hash]string)
+
+	head, err := r.Head()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defaultBranch := head.Name().Short()
+
+	walk := func(branch string, from plumbing.Hash) error {
+		iter, err := r.Log(&git.LogOptions{From: from, Order: git.LogOrderCommitterTime})
+		if err != nil {
+			return err
+		}
+		defer iter.Close()
+		return iter.ForEach(func(c *object.Commit) error {
+			if c.Committer.When.Before(since) {
+				return storer.ErrStop
+			}
+			if c.Author.When.Before(since) || c.Author.When.After(until) {
+				return nil
+			}
+			if _, ok := index[c.Hash]; !ok {
+				index[c.Hash] = branch
+				commits = append(commits, c)
+			}
+			return nil
+		})
+	}
+
+	if err := walk(defaultBranch, head.Hash()); err != nil {
+		return nil, nil, "", err
+	}
+
+	refs, err := r.References()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer refs.Close()
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if !ref.Name().IsBranch() && !ref.Name().IsRemote() {
+			return nil
+		}
+		branch := ref.Name().Short()
+		if ref.Name().IsRemote() {
+			// origin/main -> main，与本地分支同名时视为同一分支
+			if _, b, ok := strings.Cut(branch, "/"); ok {
+				branch = b
+			}
+		}
+		return walk(branch, ref.Hash())
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return commits, index, defaultBranch, nil
+}
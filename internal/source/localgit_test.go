@@ -0,0 +1,239 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github-reports/internal/config"
+	"github-reports/internal/github"
+	"github-reports/internal/github/githubtest"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var testBase = time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+// testRepo 在临时目录中创建仓库：main 上有 alice 和 bob 各一个 commit，
+// feature 分支上有 alice 的一个 commit，HEAD 指向 main
+func testRepo(t *testing.T) (dir string, shas map[string]string) {
+	t.Helper()
+
+	dir = t.TempDir()
+	r, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	shas = make(map[string]string)
+	commit := func(key, file, content, name, email string, when time.Time) {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", file, err)
+		}
+		if _, err := wt.Add(file); err != nil {
+			t.Fatalf("add %s: %v", file, err)
+		}
+		sig := &object.Signature{Name: name, Email: email, When: when}
+		hash, err := wt.Commit(key, &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatalf("commit %s: %v", key, err)
+		}
+		shas[key] = hash.String()
+	}
+
+	commit("feat: add parser", "parser.go", "package p\n\nfunc Parse() {}\n", "Alice", "alice@example.com", testBase)
+	commit("fix: typo", "README.md", "hello\n", "Bob", "bob@example.com", testBase.Add(time.Hour))
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatalf("checkout feature: %v", err)
+	}
+	commit("feat: wip export", "export.go", "package p\n", "Alice", "alice@example.com", testBase.Add(2*time.Hour))
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")}); err != nil {
+		t.Fatalf("checkout main: %v", err)
+	}
+	return dir, shas
+}
+
+func commitsBySHA(activity *github.UserActivity) map[string]github.CommitInfo {
+	result := make(map[string]github.CommitInfo)
+	for _, c := range activity.Commits {
+		result[c.SHA] = c
+	}
+	return result
+}
+
+func TestLocalGitSourceAuthorFiltering(t *testing.T) {
+	dir, shas := testRepo(t)
+	since, until := testBase.Add(-time.Hour), testBase.Add(24*time.Hour)
+
+	tests := []struct {
+		name       string
+		username   string
+		identities []string
+		want       []string
+	}{
+		{"matches by identity email", "alice-gh", []string{"ALICE@example.com"}, []string{"feat: add parser", "feat: wip export"}},
+		{"matches by author name", "bob", nil, []string{"fix: typo"}},
+		{"no match", "carol", []string{"carol@example.com"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewLocalGitSource([]LocalRepo{{Path: dir, Name: "octo/app"}}, tt.identities)
+			activity, err := src.FetchActivities(context.Background(), tt.username, since, until)
+			if err != nil {
+				t.Fatalf("FetchActivities: %v", err)
+			}
+
+			got := commitsBySHA(activity)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d commits, want %d", len(got), len(tt.want))
+			}
+			for _, key := range tt.want {
+				c, ok := got[shas[key]]
+				if !ok {
+					t.Errorf("missing commit %q", key)
+					continue
+				}
+				if c.Repo != "octo/app" {
+					t.Errorf("commit %q repo = %q, want octo/app", key, c.Repo)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalGitSourceBranches(t *testing.T) {
+	dir, shas := testRepo(t)
+	src := NewLocalGitSource([]LocalRepo{{Path: dir}}, []string{"alice@example.com"})
+
+	activity, err := src.FetchActivities(context.Background(), "alice", testBase.Add(-time.Hour), testBase.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+	got := commitsBySHA(activity)
+	name := filepath.Base(dir)
+
+	tests := []struct {
+		key       string
+		branch    string
+		onDefault bool
+	}{
+		{"feat: add parser", "main", true},
+		{"feat: wip export", "feature", false},
+	}
+	for _, tt := range tests {
+		c := got[shas[tt.key]]
+		if c.Branch != tt.branch || c.IsDefaultBranch != tt.onDefault {
+			t.Errorf("%q: branch = %q (default %v), want %q (default %v)", tt.key, c.Branch, c.IsDefaultBranch, tt.branch, tt.onDefault)
+		}
+		if len(c.Refs) != 1 || c.Refs[0] != name+":"+tt.branch {
+			t.Errorf("%q: refs = %v, want [%s:%s]", tt.key, c.Refs, name, tt.branch)
+		}
+	}
+
+	parser := got[shas["feat: add parser"]]
+	if parser.Additions != 3 || len(parser.Files) != 1 || parser.Files[0].Path != "parser.go" {
+		t.Errorf("parser commit stats = +%d files %+v, want +3 in parser.go", parser.Additions, parser.Files)
+	}
+}
+
+func TestLocalGitSourceTimeRange(t *testing.T) {
+	dir, _ := testRepo(t)
+	src := NewLocalGitSource([]LocalRepo{{Path: dir}}, []string{"alice@example.com"})
+
+	activity, err := src.FetchActivities(context.Background(), "alice", testBase.Add(90*time.Minute), testBase.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+	if len(activity.Commits) != 1 || activity.Commits[0].Message != "feat: wip export" {
+		t.Errorf("commits = %+v, want only the feature commit", activity.Commits)
+	}
+}
+
+// TestLocalGitSourceAuthorTime 验证时间范围按作者时间判断，与 CommitInfo.Date 一致：
+// rebase 过的旧 commit（提交时间在范围内）不计入，提交时间早于范围的历史不影响结果
+func TestLocalGitSourceAuthorTime(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	commit := func(msg, file string, authored, committed time.Time) {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(msg+"\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", file, err)
+		}
+		if _, err := wt.Add(file); err != nil {
+			t.Fatalf("add %s: %v", file, err)
+		}
+		_, err := wt.Commit(msg, &git.CommitOptions{
+			Author:    &object.Signature{Name: "Alice", Email: "alice@example.com", When: authored},
+			Committer: &object.Signature{Name: "Alice", Email: "alice@example.com", When: committed},
+		})
+		if err != nil {
+			t.Fatalf("commit %s: %v", msg, err)
+		}
+	}
+
+	commit("chore: init", "a.txt", testBase.Add(-72*time.Hour), testBase.Add(-72*time.Hour))
+	commit("feat: rebased", "b.txt", testBase.Add(-48*time.Hour), testBase.Add(time.Hour))
+	commit("feat: new", "c.txt", testBase.Add(2*time.Hour), testBase.Add(2*time.Hour))
+
+	src := NewLocalGitSource([]LocalRepo{{Path: dir}}, nil)
+	activity, err := src.FetchActivities(context.Background(), "alice", testBase, testBase.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+	if len(activity.Commits) != 1 || activity.Commits[0].Message != "feat: new" {
+		t.Fatalf("commits = %+v, want only the commit authored in range", activity.Commits)
+	}
+	if c := activity.Commits[0]; !c.Date.Equal(testBase.Add(2*time.Hour)) || !c.IsDefaultBranch {
+		t.Errorf("commit = %+v, want author date on main", c)
+	}
+}
+
+func TestForUserOffline(t *testing.T) {
+	dir, _ := testRepo(t)
+
+	// 离线模式下即使传入了 GitHub 客户端也不应发出任何请求
+	srv := githubtest.NewServer(nil)
+	defer srv.Close()
+	client := github.NewClient("test-token", github.WithBaseURL(srv.BaseURL()))
+
+	cfg := &config.Config{
+		Users: []config.UserConfig{{Login: "alice", Emails: []string{"alice@example.com"}}},
+	}
+	cfg.Sources.LocalGit = config.LocalGitConfig{
+		Offline: true,
+		Repos:   []config.LocalRepoConfig{{Path: dir, Name: "octo/app"}},
+	}
+
+	activity, err := ForUser(cfg, "alice", client).FetchActivities(context.Background(), "alice", testBase.Add(-time.Hour), testBase.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("FetchActivities: %v", err)
+	}
+	if len(activity.Commits) != 2 {
+		t.Errorf("got %d commits, want 2 local commits", len(activity.Commits))
+	}
+	if reqs := srv.Requests(); len(reqs) > 0 {
+		t.Errorf("offline mode sent GitHub requests: %v", reqs)
+	}
+}
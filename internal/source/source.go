@@ -0,0 +1,62 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+// ActivitySource 是活动数据来源的接口，所有实现都返回统一的 UserActivity 模型
+type ActivitySource interface {
+	FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error)
+}
+
+var _ ActivitySource = (*github.Fetcher)(nil)
+
+// Multi 依次从多个来源拉取活动并合并成一份
+type Multi struct {
+	sources []ActivitySource
+}
+
+// NewMulti 创建一个合并多个来源的 ActivitySource
+func NewMulti(sources ...ActivitySource) *Multi {
+	return &Multi{sources: sources}
+}
+
+// FetchActivities 从所有来源拉取活动；只要有一个来源成功就返回合并结果，有来源失败时结果标记为不完整
+func (m *Multi) FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error) {
+	if len(m.sources) == 0 {
+		return nil, fmt.Errorf("no activity source configured for %s", username)
+	}
+	if len(m.sources) == 1 {
+		return m.sources[0].FetchActivities(ctx, username, since, until)
+	}
+
+	merged := &github.UserActivity{
+		Username: username,
+		Since:    since,
+		Until:    until,
+	}
+
+	var lastErr error
+	succeeded := 0
+	for _, src := range m.sources {
+		activity, err := src.FetchActivities(ctx, username, since, until)
+		if err != nil {
+			println("[Source]", username, "- 来源拉取失败:", err.Error())
+			lastErr = err
+			merged.Truncated = true
+			continue
+		}
+		merged.Merge(activity)
+		succeeded++
+	}
+
+	if succeeded == 0 {
+		return nil, fmt.Errorf("all activity sources failed: %w", lastErr)
+	}
+
+	return merged, nil
+}
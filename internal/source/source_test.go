@@ -0,0 +1,48 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+// stubSource 返回固定的活动或错误
+type stubSource struct {
+	activity *github.UserActivity
+	err      error
+}
+
+func (s stubSource) FetchActivities(ctx context.Context, username string, since, until time.Time) (*github.UserActivity, error) {
+	return s.activity, s.err
+}
+
+func TestMultiTruncated(t *testing.T) {
+	ok := stubSource{activity: &github.UserActivity{Commits: []github.CommitInfo{{SHA: "a1", Repo: "octo/app"}}}}
+	failed := stubSource{err: errors.New("boom")}
+	truncated := stubSource{activity: &github.UserActivity{Truncated: true}}
+
+	tests := []struct {
+		name    string
+		sources []ActivitySource
+		want    bool
+	}{
+		{"all sources succeed", []ActivitySource{ok, ok}, false},
+		{"a source failed", []ActivitySource{ok, failed}, true},
+		{"a source was truncated", []ActivitySource{ok, truncated}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity, err := NewMulti(tt.sources...).FetchActivities(context.Background(), "alice", testBase, testBase.Add(time.Hour))
+			if err != nil {
+				t.Fatalf("FetchActivities: %v", err)
+			}
+			if activity.Truncated != tt.want {
+				t.Errorf("Truncated = %v, want %v", activity.Truncated, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github-reports/internal/github"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Snapshot 记录一次拉取覆盖的时间范围
+type Snapshot struct {
+	Username  string    `json:"username"`
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// SaveActivity 按条目写入活动数据。同一条目（commit SHA、PR / issue 编号、review、release tag）重复写入时覆盖旧值，
+// 因此多次拉取重叠的周期不会产生重复。活动应当是未按组织 / 仓库过滤的完整数据，
+// 否则记录的快照范围会让后续查询误以为该范围内只有这些活动。
+// 拉取不完整（activity.Truncated）时只写入条目，不记录快照。
+func (s *Store) SaveActivity(activity *github.UserActivity) error {
+	user := activity.Username
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, c := range activity.Commits {
+			if err := putItem(tx, bucketCommits, user, c.Date, c.Repo+"@"+c.SHA, c); err != nil {
+				return err
+			}
+		}
+		for _, pr := range activity.PullRequests {
+			id := fmt.Sprintf("%s#%d", pr.Repo, pr.Number)
+			if err := putItem(tx, bucketPRs, user, prTime(pr), id, pr); err != nil {
+				return err
+			}
+		}
+		for _, issue := range activity.Issues {
+			id := fmt.Sprintf("%s#%d", issue.Repo, issue.Number)
+			if err := putItem(tx, bucketIssues, user, issue.CreatedAt, id, issue); err != nil {
+				return err
+			}
+		}
+		for _, review := range activity.Reviews {
+			id := fmt.Sprintf("%s#%d@%s", review.Repo, review.PRNumber, review.CreatedAt.UTC().Format(timeKeyLayout))
+			if err := putItem(tx, bucketReviews, user, review.CreatedAt, id, review); err != nil {
+				return err
+			}
+		}
+		for _, release := range activity.Releases {
+			if err := putItem(tx, bucketReleases, user, release.PublishedAt, release.Repo+"@"+release.Tag, release); err != nil {
+				return err
+			}
+		}
+
+		if activity.Truncated {
+			return nil
+		}
+
+		snapshot := Snapshot{
+			Username:  user,
+			Since:     activity.Since,
+			Until:     activity.Until,
+			FetchedAt: time.Now(),
+		}
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		return tx.Bucket(bucketSnapshots).Put(itemKey(user, activity.Since, activity.Until.UTC().Format(timeKeyLayout)), data)
+	})
+}
+
+// prTime 是 PR 在时间索引中的位置：已合并的按合并时间，否则按创建时间
+func prTime(pr github.PullRequestInfo) time.Time {
+	if pr.MergedAt != nil {
+		return *pr.MergedAt
+	}
+	return pr.CreatedAt
+}
+
+// putItem 写入一个条目，并删除它之前以其他时间写入的旧键
+func putItem(tx *bolt.Tx, bucket []byte, username string, t time.Time, id string, item interface{}) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s %s: %w", bucket, id, err)
+	}
+
+	key := itemKey(username, t, id)
+	indexKey := []byte(string(bucket) + "\x00" + userKey(username) + "\x00" + id)
+	index := tx.Bucket(bucketIndex)
+	if old := index.Get(indexKey); old != nil && !bytes.Equal(old, key) {
+		if err := tx.Bucket(bucket).Delete(old); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Bucket(bucket).Put(key, data); err != nil {
+		return err
+	}
+	return index.Put(indexKey, key)
+}
+
+// Activity 读取用户在 [since, until] 内的所有条目并重建工作项
+func (s *Store) Activity(username string, since, until time.Time) (*github.UserActivity, error) {
+	activity := &github.UserActivity{
+		Username: username,
+		Since:    since,
+		Until:    until,
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		min, max := rangeBounds(username, since, until)
+		return scanRange(tx, min, max, map[string]func([]byte) error{
+			string(bucketCommits): func(v []byte) error {
+				var c github.CommitInfo
+				if err := json.Unmarshal(v, &c); err != nil {
+					return err
+				}
+				activity.Commits = append(activity.Commits, c)
+				return nil
+			},
+			string(bucketPRs): func(v []byte) error {
+				var pr github.PullRequestInfo
+				if err := json.Unmarshal(v, &pr); err != nil {
+					return err
+				}
+				activity.PullRequests = append(activity.PullRequests, pr)
+				return nil
+			},
+			string(bucketIssues): func(v []byte) error {
+				var issue github.IssueInfo
+				if err := json.Unmarshal(v, &issue); err != nil {
+					return err
+				}
+				activity.Issues = append(activity.Issues, issue)
+				return nil
+			},
+			string(bucketReviews): func(v []byte) error {
+				var review github.ReviewInfo
+				if err := json.Unmarshal(v, &review); err != nil {
+					return err
+				}
+				activity.Reviews = append(activity.Reviews, review)
+				return nil
+			},
+			string(bucketReleases): func(v []byte) error {
+				var release github.ReleaseInfo
+				if err := json.Unmarshal(v, &release); err != nil {
+					return err
+				}
+				activity.Releases = append(activity.Releases, release)
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read activity: %w", err)
+	}
+
+	activity.LinkWorkItems()
+	return activity, nil
+}
+
+// scanRange 在每个 bucket 中遍历 [min, max) 区间的键
+func scanRange(tx *bolt.Tx, min, max []byte, decoders map[string]func([]byte) error) error {
+	for name, decode := range decoders {
+		c := tx.Bucket([]byte(name)).Cursor()
+		for k, v := c.Seek(min); k != nil && bytes.Compare(k, max) < 0; k, v = c.Next() {
+			if err := decode(v); err != nil {
+				return fmt.Errorf("failed to decode %s %q: %w", name, k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CoveredActivity 仅当某一次拉取完整覆盖了 [since, until] 时，从历史中读取该范围的活动
+func (s *Store) CoveredActivity(username string, since, until time.Time) (*github.UserActivity, bool, error) {
+	covered, err := s.covered(username, since, until)
+	if err != nil || !covered {
+		return nil, false, err
+	}
+
+	activity, err := s.Activity(username, since, until)
+	if err != nil {
+		return nil, false, err
+	}
+	return activity, true, nil
+}
+
+func (s *Store) covered(username string, since, until time.Time) (bool, error) {
+	var covered bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := []byte(userKey(username) + "\x00")
+		c := tx.Bucket(bucketSnapshots).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var snap Snapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return fmt.Errorf("failed to decode snapshot %q: %w", k, err)
+			}
+			if !snap.Since.After(since) && !snap.Until.Before(until) {
+				covered = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return covered, err
+}
+
+// Snapshots 返回用户的所有拉取记录，按开始时间排序
+func (s *Store) Snapshots(username string) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := []byte(userKey(username) + "\x00")
+		c := tx.Bucket(bucketSnapshots).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var snap Snapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return fmt.Errorf("failed to decode snapshot %q: %w", k, err)
+			}
+			snapshots = append(snapshots, snap)
+		}
+		return nil
+	})
+	return snapshots, err
+}
+
+// Users 返回存储中出现过的所有用户（小写）
+func (s *Store) Users() ([]string, error) {
+	seen := make(map[string]bool)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSnapshots).ForEach(func(k, _ []byte) error {
+			if user, ok := splitItemKey(k); ok {
+				seen[user] = true
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users := make([]string, 0, len(seen))
+	for u := range seen {
+		users = append(users, u)
+	}
+	sort.Strings(users)
+	return users, nil
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github-reports/internal/github"
+)
+
+func TestSaveActivitySnapshot(t *testing.T) {
+	since := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name        string
+		truncated   bool
+		wantCovered bool
+	}{
+		{"complete fetch records the snapshot", false, true},
+		{"truncated fetch keeps items but no snapshot", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := Open(filepath.Join(t.TempDir(), "history.db"))
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer st.Close()
+
+			err = st.SaveActivity(&github.UserActivity{
+				Username:  "octocat",
+				Since:     since,
+				Until:     until,
+				Commits:   []github.CommitInfo{{SHA: "a1", Repo: "octo/app", Date: since.Add(time.Hour)}},
+				Truncated: tt.truncated,
+			})
+			if err != nil {
+				t.Fatalf("SaveActivity: %v", err)
+			}
+
+			_, covered, err := st.CoveredActivity("octocat", since, until)
+			if err != nil {
+				t.Fatalf("CoveredActivity: %v", err)
+			}
+			if covered != tt.wantCovered {
+				t.Errorf("covered = %v, want %v", covered, tt.wantCovered)
+			}
+
+			stored, err := st.Activity("octocat", since, until)
+			if err != nil {
+				t.Fatalf("Activity: %v", err)
+			}
+			if len(stored.Commits) != 1 {
+				t.Errorf("stored %d commits, want 1", len(stored.Commits))
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Checkpoint 是任务的进度，用于中断后继续
+type Checkpoint struct {
+	Done time.Time `json:"done"` // 已完成到的时间点
+	To   time.Time `json:"to"`   // 记录进度时任务的截止时间
+	// Retry 是 Done 之前拉取不完整的周期的起始时间，继续时需要重新拉取
+	Retry []time.Time `json:"retry,omitempty"`
+}
+
+// Checkpoint 返回任务上次记录的进度
+func (s *Store) Checkpoint(job string) (Checkpoint, bool, error) {
+	var cp Checkpoint
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketCheckpoints).Get([]byte(job))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &cp)
+	})
+	if err != nil {
+		return Checkpoint{}, false, fmt.Errorf("failed to read checkpoint %s: %w", job, err)
+	}
+	return cp, found, nil
+}
+
+// SaveCheckpoint 记录任务的进度
+func (s *Store) SaveCheckpoint(job string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint %s: %w", job, err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketCheckpoints).Put([]byte(job), data)
+	})
+}
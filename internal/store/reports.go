@@ -0,0 +1,115 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Report 是一份存档的报告及其生成时的元数据
+type Report struct {
+	ID            uint64    `json:"id"`
+	Username      string    `json:"username"`
+	Requester     string    `json:"requester"` // 发起人：飞书用户对应的登录名、open_id 或 api
+	PeriodLabel   string    `json:"period"`
+	Since         time.Time `json:"since"`
+	Until         time.Time `json:"until"`
+	Org           string    `json:"org,omitempty"`
+	Repo          string    `json:"repo,omitempty"`
+	Style         string    `json:"style,omitempty"`
+	Language      string    `json:"language,omitempty"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Content       string    `json:"content"`
+}
+
+// ReportQuery 是查询报告的条件，零值字段不参与过滤
+type ReportQuery struct {
+	Username string
+	Since    time.Time // 报告周期与 [Since, Until] 有重叠即匹配
+	Until    time.Time
+	Limit    int // 最多返回的条数，从最新的开始
+}
+
+// SaveReport 存档一份报告，并为它分配递增的 ID
+func (s *Store) SaveReport(report *Report) error {
+	if report.CreatedAt.IsZero() {
+		report.CreatedAt = time.Now()
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketReports)
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		report.ID = id
+
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		return b.Put(idKey(id), data)
+	})
+}
+
+// Report 按 ID 读取报告，不存在时返回 nil
+func (s *Store) Report(id uint64) (*Report, error) {
+	var report *Report
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketReports).Get(idKey(id))
+		if data == nil {
+			return nil
+		}
+		report = &Report{}
+		return json.Unmarshal(data, report)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %d: %w", id, err)
+	}
+	return report, nil
+}
+
+// Reports 按条件查询报告，按 ID 从新到旧排列
+func (s *Store) Reports(q ReportQuery) ([]Report, error) {
+	var reports []Report
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketReports).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var r Report
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("failed to decode report %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			if q.Username != "" && !strings.EqualFold(r.Username, q.Username) {
+				continue
+			}
+			if !q.Since.IsZero() && r.Until.Before(q.Since) {
+				continue
+			}
+			if !q.Until.IsZero() && r.Since.After(q.Until) {
+				continue
+			}
+			reports = append(reports, r)
+			if q.Limit > 0 && len(reports) >= q.Limit {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reports: %w", err)
+	}
+	return reports, nil
+}
+
+// idKey 使用大端序，使键的字典序与 ID 顺序一致
+func idKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
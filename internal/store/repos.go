@@ -0,0 +1,39 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github-reports/internal/github"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// RepoInfo 读取缓存的仓库元数据，没有时返回 nil。key 见 github.RepoInfoStore
+func (s *Store) RepoInfo(key string) (*github.RepoInfo, error) {
+	var info *github.RepoInfo
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketRepos).Get([]byte(strings.ToLower(key)))
+		if data == nil {
+			return nil
+		}
+		info = &github.RepoInfo{}
+		return json.Unmarshal(data, info)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository %s: %w", key, err)
+	}
+	return info, nil
+}
+
+// SaveRepoInfo 缓存仓库元数据，覆盖旧值
+func (s *Store) SaveRepoInfo(key string, info *github.RepoInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal repository %s: %w", key, err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketRepos).Put([]byte(strings.ToLower(key)), data)
+	})
+}
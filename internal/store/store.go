@@ -0,0 +1,91 @@
+// Package store 把每次拉取到的活动按条目持久化，并存档生成的报告，
+// 用于环比、趋势分析和报告重发。底层使用嵌入式的 bbolt，无需外部数据库。
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketCommits     = []byte("commits")
+	bucketPRs         = []byte("pull_requests")
+	bucketIssues      = []byte("issues")
+	bucketReviews     = []byte("reviews")
+	bucketReleases    = []byte("releases")
+	bucketIndex       = []byte("item_index") // 条目 ID -> 当前的时间键，条目时间变化（如 PR 被合并）时用于删除旧键
+	bucketSnapshots   = []byte("snapshots")  // 每次拉取覆盖的时间范围
+	bucketReports     = []byte("reports")
+	bucketCheckpoints = []byte("checkpoints")  // 回填等长任务的进度
+	bucketRepos       = []byte("repositories") // 仓库元数据缓存，键为小写的 owner/repo
+)
+
+// timeKeyLayout 是键中的时间格式，定长且按字典序即按时间排序
+const timeKeyLayout = "20060102T150405Z"
+
+// Store 是活动和报告的历史存储
+type Store struct {
+	db *bolt.DB
+}
+
+// Open 打开（不存在时创建）path 处的数据库
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store dir: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketCommits, bucketPRs, bucketIssues, bucketReviews, bucketReleases, bucketIndex, bucketSnapshots, bucketReports, bucketCheckpoints, bucketRepos} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close 关闭数据库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// userKey 是键中的用户名部分，大小写不敏感
+func userKey(username string) string {
+	return strings.ToLower(username)
+}
+
+// itemKey 的格式为 user \x00 时间 \x00 ID，同一用户的条目按时间连续存放，便于范围扫描
+func itemKey(username string, t time.Time, id string) []byte {
+	return []byte(userKey(username) + "\x00" + t.UTC().Format(timeKeyLayout) + "\x00" + id)
+}
+
+// rangeBounds 返回 [since, until] 对应的键区间
+func rangeBounds(username string, since, until time.Time) (min, max []byte) {
+	prefix := userKey(username) + "\x00"
+	return []byte(prefix + since.UTC().Format(timeKeyLayout)),
+		[]byte(prefix + until.UTC().Format(timeKeyLayout) + "\x01")
+}
+
+func splitItemKey(key []byte) (username string, ok bool) {
+	parts := strings.SplitN(string(key), "\x00", 3)
+	if len(parts) != 3 {
+		return "", false
+	}
+	return parts[0], true
+}
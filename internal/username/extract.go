@@ -0,0 +1,165 @@
+// Package username 用确定性规则从聊天消息中识别 GitHub 用户名，
+// 只有规则无法给出唯一结果时才需要交给 LLM。
+package username
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	loginPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9]|-[A-Za-z0-9]){0,38}$`)
+
+	// github.com/<login>，后面不能再跟 /<repo>（那是仓库链接）
+	profileURLPattern = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.)?github\.com/([A-Za-z0-9-]+)(/[A-Za-z0-9._-]+)?/?`)
+	// @handle，@ 前不能是字母数字（排除邮箱），飞书的 @_user_1 占位符以下划线开头因此不会匹配
+	handlePattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@])@([A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)`)
+	// 独立的 ASCII 单词
+	wordPattern = regexp.MustCompile(`[A-Za-z0-9][A-Za-z0-9-]*`)
+)
+
+// reservedPaths 是 github.com 下不是用户主页的一级路径
+var reservedPaths = map[string]bool{
+	"about": true, "apps": true, "collections": true, "customer-stories": true, "enterprise": true,
+	"explore": true, "features": true, "issues": true, "login": true, "marketplace": true,
+	"notifications": true, "orgs": true, "pricing": true, "pulls": true, "search": true,
+	"settings": true, "sponsors": true, "topics": true, "trending": true,
+}
+
+// stopWords 是消息中常见、但不可能是目标用户名的英文单词（小写）
+var stopWords = map[string]bool{
+	"github": true, "gitlab": true, "gitea": true, "git": true, "com": true, "http": true, "https": true, "www": true,
+	"pr": true, "prs": true, "issue": true, "issues": true, "commit": true, "commits": true, "review": true, "reviews": true,
+	"report": true, "weekly": true, "week": true, "month": true, "quarter": true, "sprint": true, "day": true, "days": true,
+	"this": true, "last": true, "today": true, "q1": true, "q2": true, "q3": true, "q4": true,
+	"brief": true, "detailed": true, "english": true, "chinese": true, "zh": true, "en": true,
+	"code": true, "repo": true, "repos": true, "org": true, "user": true, "the": true, "of": true, "for": true,
+	"please": true, "show": true, "me": true, "my": true, "summary": true, "activity": true, "activities": true,
+	"ai": true, "llm": true, "bot": true,
+}
+
+// 出现这些词时消息可能还限定了组织或仓库，交给 LLM 处理。中文按子串匹配，
+// 英文按整词匹配，避免 george、reporter 之类的用户名被误判
+var (
+	ambiguousHints     = []string{"仓库", "组织", "项目", "只看"}
+	ambiguousWordHints = map[string]bool{"repo": true, "repos": true, "org": true, "orgs": true, "only": true}
+)
+
+// Result 是规则提取的结果
+type Result struct {
+	Logins    []string // 按出现顺序去重后的用户名
+	Ambiguous bool     // 规则无法确定，需要交给 LLM
+}
+
+// Extract 依次尝试 GitHub 主页链接、@handle、配置中的别名和消息中唯一的英文单词。
+// aliases 的 key 为别名（不区分大小写），value 为对应的 GitHub 用户名。
+func Extract(content string, aliases map[string]string) Result {
+	lower := strings.ToLower(content)
+	for _, hint := range ambiguousHints {
+		if strings.Contains(lower, hint) {
+			return Result{Ambiguous: true}
+		}
+	}
+	for _, word := range wordPattern.FindAllString(lower, -1) {
+		if ambiguousWordHints[word] {
+			return Result{Ambiguous: true}
+		}
+	}
+
+	var logins []string
+	rest := content
+
+	// 1. GitHub 主页链接
+	for _, m := range profileURLPattern.FindAllStringSubmatch(content, -1) {
+		if m[2] != "" {
+			// 仓库链接，交给 LLM 判断是看用户还是看仓库
+			return Result{Ambiguous: true}
+		}
+		if !reservedPaths[strings.ToLower(m[1])] && ValidLogin(m[1]) {
+			logins = append(logins, m[1])
+		}
+		rest = strings.Replace(rest, m[0], " ", 1)
+	}
+
+	// 2. @handle
+	for _, m := range handlePattern.FindAllStringSubmatch(rest, -1) {
+		if ValidLogin(m[1]) {
+			logins = append(logins, m[1])
+		}
+	}
+	rest = handlePattern.ReplaceAllString(rest, " ")
+
+	// 3. 配置中的别名，长别名优先，避免「小明」吞掉「小明同学」
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	for _, alias := range names {
+		if alias == "" {
+			continue
+		}
+		idx := strings.Index(strings.ToLower(rest), strings.ToLower(alias))
+		if idx < 0 {
+			continue
+		}
+		logins = append(logins, aliases[alias])
+		rest = rest[:idx] + " " + rest[idx+len(alias):]
+	}
+
+	if len(logins) > 0 {
+		return Result{Logins: dedup(logins)}
+	}
+
+	// 4. 消息中唯一一个像用户名的英文单词，例如「帮我看看 minorcell 的动态」
+	var candidates []string
+	for _, word := range wordPattern.FindAllString(rest, -1) {
+		w := strings.ToLower(word)
+		if stopWords[w] || isNumeric(w) || !ValidLogin(word) || looksLikePeriod(w) {
+			continue
+		}
+		candidates = append(candidates, word)
+	}
+	candidates = dedup(candidates)
+	if len(candidates) == 1 {
+		return Result{Logins: candidates}
+	}
+
+	return Result{Ambiguous: true}
+}
+
+// ValidLogin 判断字符串是否符合 GitHub 登录名语法：字母数字和单个连字符，不以连字符开头或结尾，最长 39 个字符
+func ValidLogin(s string) bool {
+	return len(s) <= 39 && loginPattern.MatchString(s)
+}
+
+func dedup(logins []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, l := range logins {
+		key := strings.ToLower(l)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, l)
+	}
+	return result
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// looksLikePeriod 排除 last-week、2026-w41、2026-09 这类周期表达式
+var periodWordPattern = regexp.MustCompile(`^(?:this|last)-\w+|^\d{4}-(?:w?\d{1,2}|q[1-4])$|^\d{4}-\d{2}-\d{2}$`)
+
+func looksLikePeriod(w string) bool {
+	return periodWordPattern.MatchString(w)
+}
@@ -0,0 +1,71 @@
+package username
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractAmbiguousHints(t *testing.T) {
+	tests := []struct {
+		content       string
+		wantLogins    []string
+		wantAmbiguous bool
+	}{
+		{"看看 george 的周报", []string{"george"}, false},
+		{"@reporter-bot 上周", []string{"reporter-bot"}, false},
+		{"@octocat 只看 octo/app", nil, true},
+		{"@octocat in the repo octo/app", nil, true},
+		{"@octocat only merged PRs", nil, true},
+		{"octocat 在 octo 组织的动态", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			got := Extract(tt.content, nil)
+			if got.Ambiguous != tt.wantAmbiguous || !reflect.DeepEqual(got.Logins, tt.wantLogins) {
+				t.Errorf("Extract(%q) = %+v, want logins %v ambiguous %v", tt.content, got, tt.wantLogins, tt.wantAmbiguous)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	aliases := map[string]string{
+		"小明":    "xiaoming",
+		"小明同学":  "xm-classmate",
+		"Alice": "alice-gh",
+	}
+
+	tests := []struct {
+		name          string
+		content       string
+		wantLogins    []string
+		wantAmbiguous bool
+	}{
+		{"profile url", "看看 https://github.com/octocat 的周报", []string{"octocat"}, false},
+		{"profile url without scheme", "github.com/Octocat/ 本月", []string{"Octocat"}, false},
+		{"repo url", "https://github.com/octo/app 最近怎么样", nil, true},
+		{"reserved path", "https://github.com/trending 上的 minorcell", []string{"minorcell"}, false},
+		{"profile and handle deduplicated", "@Octocat https://github.com/octocat", []string{"octocat"}, false},
+		{"email is not a handle", "bob@example.com 的周报", nil, true},
+		{"longest alias first", "小明同学的周报", []string{"xm-classmate"}, false},
+		{"both aliases", "小明同学和小明的对比", []string{"xm-classmate", "xiaoming"}, false},
+		{"alias is case-insensitive", "alice 上周", []string{"alice-gh"}, false},
+		{"stop words", "please show me the weekly report of minorcell", []string{"minorcell"}, false},
+		{"period words", "minorcell last-week", []string{"minorcell"}, false},
+		{"month and date", "2026-09 和 2026-10-01 minorcell", []string{"minorcell"}, false},
+		{"single english word", "帮我看看 minorcell 的动态", []string{"minorcell"}, false},
+		{"two english words", "alpha beta 的动态", nil, true},
+		{"numbers only", "最近 30 天", nil, true},
+		{"no candidate", "你好", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(tt.content, aliases)
+			if got.Ambiguous != tt.wantAmbiguous || !reflect.DeepEqual(got.Logins, tt.wantLogins) {
+				t.Errorf("Extract(%q) = %+v, want logins %v ambiguous %v", tt.content, got, tt.wantLogins, tt.wantAmbiguous)
+			}
+		})
+	}
+}